@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/pivotal/kpack/pkg/logs"
 	"github.com/spf13/cobra"
 
+	"github.com/pivotal/build-service-cli/pkg/build"
 	"github.com/pivotal/build-service-cli/pkg/buildpackage"
 	"github.com/pivotal/build-service-cli/pkg/clusterstack"
 	"github.com/pivotal/build-service-cli/pkg/clusterstore"
@@ -91,6 +94,14 @@ func getImageCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		return logs.NewImageWaiter(clientSet.KpackClient, logs.NewBuildLogsClient(clientSet.K8sClient))
 	}
 
+	newCredentialChecker := func(clientSet k8s.ClientSet) imgcmds.CredentialChecker {
+		return image.CredentialChecker{K8sClient: clientSet.K8sClient, Transport: http.DefaultTransport, Timeout: 10 * time.Second}
+	}
+
+	credentialHandOff := imgcmds.NewSecretCreateHandOff(commands.NewConfirmationProvider(), func() *cobra.Command {
+		return secretcmds.NewCreateCommand(clientSetProvider, newSecretFactory(), newCredentialFetcher)
+	})
+
 	factory := &image.Factory{}
 
 	imageRootCmd := &cobra.Command{
@@ -99,13 +110,19 @@ func getImageCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Aliases: []string{"images", "imgs", "img"},
 	}
 	imageRootCmd.AddCommand(
-		imgcmds.NewCreateCommand(clientSetProvider, factory, newImageWaiter),
-		imgcmds.NewPatchCommand(clientSetProvider, factory, newImageWaiter),
-		imgcmds.NewSaveCommand(clientSetProvider, factory, newImageWaiter),
+		imgcmds.NewCreateCommand(clientSetProvider, factory, newImageWaiter, newCredentialChecker, credentialHandOff),
+		imgcmds.NewPatchCommand(clientSetProvider, factory, newImageWaiter, newCredentialChecker, credentialHandOff),
+		imgcmds.NewSaveCommand(clientSetProvider, factory, newImageWaiter, newCredentialChecker, credentialHandOff),
+		imgcmds.NewCloneCommand(clientSetProvider, factory, newImageWaiter),
 		imgcmds.NewListCommand(clientSetProvider),
 		imgcmds.NewDeleteCommand(clientSetProvider),
-		imgcmds.NewTriggerCommand(clientSetProvider),
+		imgcmds.NewTriggerCommand(clientSetProvider, commands.NewConfirmationProvider(), newImageWaiter),
 		imgcmds.NewStatusCommand(clientSetProvider),
+		imgcmds.NewHistoryCommand(clientSetProvider),
+		imgcmds.NewRollbackCommand(clientSetProvider, &registry.Fetcher{}, registry.RelocatorImpl{}),
+		imgcmds.NewPromoteCommand(clientSetProvider, &registry.Fetcher{}, registry.RelocatorImpl{}),
+		imgcmds.NewProvenanceCommand(clientSetProvider, &registry.Fetcher{}),
+		imgcmds.NewOutdatedCommand(clientSetProvider),
 	)
 	imageRootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		return configureImageFactory(cmd, factory)
@@ -115,6 +132,10 @@ func getImageCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 }
 
 func getBuildCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	newLogTailer := func(clientSet k8s.ClientSet) buildcmds.LogTailer {
+		return build.NewLogsClient(clientSet.K8sClient)
+	}
+
 	buildRootCmd := &cobra.Command{
 		Use:     "build",
 		Short:   "Build Commands",
@@ -122,17 +143,21 @@ func getBuildCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 	}
 	buildRootCmd.AddCommand(
 		buildcmds.NewListCommand(clientSetProvider),
-		buildcmds.NewStatusCommand(clientSetProvider),
-		buildcmds.NewLogsCommand(clientSetProvider),
+		buildcmds.NewStatusCommand(clientSetProvider, newLogTailer),
+		buildcmds.NewLogsCommand(clientSetProvider, newLogTailer),
+		buildcmds.NewCancelCommand(clientSetProvider, commands.NewConfirmationProvider()),
+		buildcmds.NewRetryCommand(clientSetProvider, commands.NewConfirmationProvider()),
+		buildcmds.NewDeleteCommand(clientSetProvider, commands.NewConfirmationProvider()),
+		buildcmds.NewPruneCommand(clientSetProvider, commands.NewConfirmationProvider()),
+		buildcmds.NewBomCommand(clientSetProvider, &registry.Fetcher{}),
+		buildcmds.NewDiffCommand(clientSetProvider),
+		buildcmds.NewStatsCommand(clientSetProvider),
 	)
 	return buildRootCmd
 }
 
 func getSecretCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
-	credentialFetcher := &commands.CredentialFetcher{}
-	secretFactory := &secret.Factory{
-		CredentialFetcher: credentialFetcher,
-	}
+	secretFactory := newSecretFactory()
 
 	secretRootCmd := &cobra.Command{
 		Use:     "secret",
@@ -140,13 +165,27 @@ func getSecretCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Aliases: []string{"secrets"},
 	}
 	secretRootCmd.AddCommand(
-		secretcmds.NewCreateCommand(clientSetProvider, secretFactory),
+		secretcmds.NewCreateCommand(clientSetProvider, secretFactory, newCredentialFetcher),
+		secretcmds.NewCopyCommand(clientSetProvider),
 		secretcmds.NewDeleteCommand(clientSetProvider),
 		secretcmds.NewListCommand(clientSetProvider),
+		secretcmds.NewUpdateCommand(clientSetProvider, secretFactory, newCredentialFetcher),
+		secretcmds.NewVerifyCommand(clientSetProvider),
 	)
 	return secretRootCmd
 }
 
+func newSecretFactory() *secret.Factory {
+	return &secret.Factory{
+		HostKeyScanner:       secret.SshHostKeyScanner{Timeout: 10 * time.Second},
+		ConfirmationProvider: commands.NewConfirmationProvider(),
+	}
+}
+
+func newCredentialFetcher(cmd *cobra.Command) (secret.CredentialFetcher, error) {
+	return commands.NewCredentialFetcher(cmd)
+}
+
 func getClusterBuilderCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 	clusterBuilderRootCmd := &cobra.Command{
 		Use:     "clusterbuilder",
@@ -3,6 +3,8 @@ module github.com/pivotal/build-service-cli
 go 1.14
 
 require (
+	github.com/BurntSushi/toml v0.3.1
+	github.com/docker/cli v0.0.0-20200130152716-5d0cf8839492
 	github.com/evanphx/json-patch v4.5.0+incompatible
 	github.com/ghodss/yaml v1.0.0
 	github.com/google/go-cmp v0.5.1
@@ -0,0 +1,143 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"time"
+)
+
+type CycloneDXDocument struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    CycloneDXMetadata    `json:"metadata"`
+	Components  []CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp,omitempty"`
+	Tools     []CycloneDXTool    `json:"tools"`
+	Component CycloneDXComponent `json:"component"`
+}
+
+type CycloneDXTool struct {
+	Name string `json:"name"`
+}
+
+type CycloneDXComponent struct {
+	Type       string              `json:"type"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	Properties []CycloneDXProperty `json:"properties,omitempty"`
+}
+
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewCycloneDXDocument converts the bill of materials of a built image into a CycloneDX document.
+func NewCycloneDXDocument(image string, created time.Time, md ImageMetadata) CycloneDXDocument {
+	components := []CycloneDXComponent{}
+	for _, entry := range md.BOM {
+		components = append(components, CycloneDXComponent{
+			Type:    "library",
+			Name:    entry.Name,
+			Version: entry.Version,
+			Properties: []CycloneDXProperty{
+				{Name: "buildpack", Value: buildpackRef(entry.Buildpack)},
+			},
+		})
+	}
+
+	return CycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.3",
+		Version:     1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: formatCreated(created),
+			Tools:     []CycloneDXTool{{Name: "kp"}},
+			Component: CycloneDXComponent{
+				Type: "container",
+				Name: image,
+				Properties: []CycloneDXProperty{
+					{Name: "stack", Value: md.StackId},
+					{Name: "runImage", Value: md.RunImage.Image},
+				},
+			},
+		},
+		Components: components,
+	}
+}
+
+type SPDXDocument struct {
+	SPDXVersion       string           `json:"spdxVersion"`
+	DataLicense       string           `json:"dataLicense"`
+	SPDXID            string           `json:"SPDXID"`
+	Name              string           `json:"name"`
+	DocumentNamespace string           `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo `json:"creationInfo"`
+	Packages          []SPDXPackage    `json:"packages"`
+}
+
+type SPDXCreationInfo struct {
+	Created  string   `json:"created,omitempty"`
+	Creators []string `json:"creators"`
+}
+
+type SPDXPackage struct {
+	SPDXID           string `json:"SPDXID"`
+	Name             string `json:"name"`
+	VersionInfo      string `json:"versionInfo,omitempty"`
+	DownloadLocation string `json:"downloadLocation"`
+	LicenseConcluded string `json:"licenseConcluded"`
+	LicenseDeclared  string `json:"licenseDeclared"`
+	CopyrightText    string `json:"copyrightText"`
+	Comment          string `json:"comment,omitempty"`
+}
+
+// NewSPDXDocument converts the bill of materials of a built image into an SPDX document.
+func NewSPDXDocument(image string, created time.Time, md ImageMetadata) SPDXDocument {
+	packages := []SPDXPackage{}
+	for i, entry := range md.BOM {
+		packages = append(packages, SPDXPackage{
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d", i+1),
+			Name:             entry.Name,
+			VersionInfo:      entry.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+			CopyrightText:    "NOASSERTION",
+			Comment:          fmt.Sprintf("provided by buildpack %s", buildpackRef(entry.Buildpack)),
+		})
+	}
+
+	return SPDXDocument{
+		SPDXVersion:       "SPDX-2.2",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              image,
+		DocumentNamespace: "https://kpack.io/spdx/" + image,
+		CreationInfo: SPDXCreationInfo{
+			Created:  formatCreated(created),
+			Creators: []string{"Tool: kp"},
+		},
+		Packages: packages,
+	}
+}
+
+func buildpackRef(bp BuildpackInfo) string {
+	if bp.Version == "" {
+		return bp.Id
+	}
+	return bp.Id + "@" + bp.Version
+}
+
+func formatCreated(created time.Time) string {
+	if created.IsZero() {
+		return ""
+	}
+	return created.UTC().Format(time.RFC3339)
+}
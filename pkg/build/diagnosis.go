@@ -0,0 +1,62 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"regexp"
+)
+
+type failurePattern struct {
+	steps   []string
+	pattern *regexp.Regexp
+	hint    string
+}
+
+var failurePatterns = []failurePattern{
+	{
+		steps:   []string{"detect"},
+		pattern: regexp.MustCompile(`(?i)no buildpack groups passed detection|no buildpacks participating`),
+		hint:    "No buildpack detected the source code. Check that the source code (and sub path) contains an application supported by the builder.",
+	},
+	{
+		steps:   []string{"analyze", "restore", "export"},
+		pattern: regexp.MustCompile(`\b401 Unauthorized\b|(?i:\bunauthorized:|\bdenied:|access denied|access to the resource is denied)`),
+		hint:    "The service account is missing valid credentials for the image registry. Use 'kp secret create' to add registry credentials.",
+	},
+	{
+		steps:   []string{"prepare"},
+		pattern: regexp.MustCompile(`(?i)authentication required|could not read username|permission denied \(publickey\)|repository not found`),
+		hint:    "The git repository could not be accessed. Use 'kp secret create --git-url' to add git credentials.",
+	},
+	{
+		pattern: regexp.MustCompile(`OOMKilled`),
+		hint:    "A build step ran out of memory and was killed.",
+	},
+	{
+		pattern: regexp.MustCompile(`ImagePullBackOff|ErrImagePull`),
+		hint:    "An image used by the build pod could not be pulled. Check that the builder image exists and the service account has image pull secrets for it.",
+	},
+	{
+		pattern: regexp.MustCompile(`FailedMount`),
+		hint:    "A volume could not be mounted in the build pod. Check that all secrets referenced by the service account exist.",
+	},
+}
+
+// GetFailureHints returns hints for known failure patterns found in the output of a failed build step.
+func GetFailureHints(step string, texts ...string) []string {
+	var hints []string
+	for _, p := range failurePatterns {
+		if len(p.steps) > 0 && !contains(p.steps, step) {
+			continue
+		}
+
+		for _, text := range texts {
+			if p.pattern.MatchString(text) {
+				hints = append(hints, p.hint)
+				break
+			}
+		}
+	}
+	return hints
+}
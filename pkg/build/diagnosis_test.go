@@ -0,0 +1,69 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestGetFailureHints(t *testing.T) {
+	spec.Run(t, "TestGetFailureHints", testGetFailureHints)
+}
+
+func testGetFailureHints(t *testing.T, when spec.G, it spec.S) {
+	it("returns a hint when no buildpacks detect the source", func() {
+		hints := build.GetFailureHints("detect", "ERROR: No buildpack groups passed detection.")
+		require.Len(t, hints, 1)
+		require.Contains(t, hints[0], "No buildpack detected the source code")
+	})
+
+	it("only matches step specific patterns for the failed step", func() {
+		require.Len(t, build.GetFailureHints("build", "401 Unauthorized"), 0)
+		require.Len(t, build.GetFailureHints("export", "401 Unauthorized"), 1)
+	})
+
+	it("returns a hint for registry authentication failures", func() {
+		for _, text := range []string{
+			"GET https://some-registry.io/v2/token: 401 Unauthorized",
+			"UNAUTHORIZED: authentication required; [map[Action:pull]]",
+			"DENIED: requested access to the resource is denied",
+			"ERROR: failed to export: access denied",
+		} {
+			hints := build.GetFailureHints("export", text)
+			require.Len(t, hints, 1, text)
+			require.Contains(t, hints[0], "registry credentials")
+		}
+	})
+
+	it("does not return registry hints for unrelated occurrences of 401 or denied", func() {
+		for _, text := range []string{
+			"Adding layer sha256:4401a9c1b4e5f3d2",
+			"Listening on port 4010",
+			"main.go:401: some failure",
+			"Request was denied by the policy",
+		} {
+			require.Len(t, build.GetFailureHints("export", text), 0, text)
+		}
+	})
+
+	it("returns a hint for git authentication failures", func() {
+		hints := build.GetFailureHints("prepare", "fatal: could not read Username for 'https://github.com'")
+		require.Len(t, hints, 1)
+		require.Contains(t, hints[0], "kp secret create --git-url")
+	})
+
+	it("matches pod failures regardless of the step", func() {
+		hints := build.GetFailureHints("", "OOMKilled", "Back-off pulling image: ImagePullBackOff")
+		require.Len(t, hints, 2)
+	})
+
+	it("returns no hints when nothing matches", func() {
+		require.Len(t, build.GetFailureHints("build", "some other failure"), 0)
+	})
+}
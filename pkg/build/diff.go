@@ -0,0 +1,188 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+)
+
+const (
+	BuildpackAdded      = "added"
+	BuildpackRemoved    = "removed"
+	BuildpackUpgraded   = "upgraded"
+	BuildpackDowngraded = "downgraded"
+	BuildpackChanged    = "changed"
+)
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type BuildpackChange struct {
+	Id     string `json:"id"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Change string `json:"change"`
+}
+
+type BuildDiff struct {
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	Changes    []FieldChange     `json:"changes"`
+	Buildpacks []BuildpackChange `json:"buildpacks"`
+}
+
+// Diff compares the inputs and results of two builds of an image.
+func Diff(from, to v1alpha1.Build) BuildDiff {
+	diff := BuildDiff{
+		From:       from.Labels[v1alpha1.BuildNumberLabel],
+		To:         to.Labels[v1alpha1.BuildNumberLabel],
+		Changes:    []FieldChange{},
+		Buildpacks: diffBuildpacks(from.Status.BuildMetadata, to.Status.BuildMetadata),
+	}
+
+	fromFields, toFields := buildFields(from), buildFields(to)
+	for _, field := range mergedKeys(fromFields, toFields) {
+		if fromFields[field] != toFields[field] {
+			diff.Changes = append(diff.Changes, FieldChange{
+				Field: field,
+				From:  fromFields[field],
+				To:    toFields[field],
+			})
+		}
+	}
+
+	return diff
+}
+
+func (d BuildDiff) HasChanges() bool {
+	return len(d.Changes) > 0 || len(d.Buildpacks) > 0
+}
+
+var buildFieldOrder = []string{
+	"Build Reason",
+	"Builder",
+	"Run Image",
+	"Git Url",
+	"Git Revision",
+	"Blob Url",
+	"Registry Image",
+	"Sub Path",
+	"Image",
+}
+
+func buildFields(b v1alpha1.Build) map[string]string {
+	fields := map[string]string{
+		"Build Reason": b.Annotations[v1alpha1.BuildReasonAnnotation],
+		"Builder":      b.Spec.Builder.Image,
+		"Run Image":    b.Status.Stack.RunImage,
+		"Sub Path":     b.Spec.Source.SubPath,
+		"Image":        b.Status.LatestImage,
+	}
+
+	if b.Spec.Source.Git != nil {
+		fields["Git Url"] = b.Spec.Source.Git.URL
+		fields["Git Revision"] = b.Spec.Source.Git.Revision
+	}
+	if b.Spec.Source.Blob != nil {
+		fields["Blob Url"] = b.Spec.Source.Blob.URL
+	}
+	if b.Spec.Source.Registry != nil {
+		fields["Registry Image"] = b.Spec.Source.Registry.Image
+	}
+
+	for _, e := range b.Spec.Env {
+		fields["Env "+e.Name] = e.Value
+	}
+
+	return fields
+}
+
+func mergedKeys(a, b map[string]string) []string {
+	var envKeys []string
+	for _, m := range []map[string]string{a, b} {
+		for k := range m {
+			if strings.HasPrefix(k, "Env ") && !contains(envKeys, k) {
+				envKeys = append(envKeys, k)
+			}
+		}
+	}
+	sort.Strings(envKeys)
+
+	return append(append([]string{}, buildFieldOrder...), envKeys...)
+}
+
+func diffBuildpacks(from, to v1alpha1.BuildpackMetadataList) []BuildpackChange {
+	fromVersions := map[string]string{}
+	for _, bp := range from {
+		fromVersions[bp.Id] = bp.Version
+	}
+
+	toVersions := map[string]string{}
+	for _, bp := range to {
+		toVersions[bp.Id] = bp.Version
+	}
+
+	changes := []BuildpackChange{}
+	for _, bp := range from {
+		toVersion, ok := toVersions[bp.Id]
+		switch {
+		case !ok:
+			changes = append(changes, BuildpackChange{Id: bp.Id, From: bp.Version, Change: BuildpackRemoved})
+		case toVersion != bp.Version:
+			changes = append(changes, BuildpackChange{Id: bp.Id, From: bp.Version, To: toVersion, Change: versionChange(bp.Version, toVersion)})
+		}
+	}
+
+	for _, bp := range to {
+		if _, ok := fromVersions[bp.Id]; !ok {
+			changes = append(changes, BuildpackChange{Id: bp.Id, To: bp.Version, Change: BuildpackAdded})
+		}
+	}
+
+	return changes
+}
+
+func versionChange(from, to string) string {
+	fromParts, fromOk := parseVersion(from)
+	toParts, toOk := parseVersion(to)
+	if !fromOk || !toOk {
+		return BuildpackChanged
+	}
+
+	for i := 0; i < len(fromParts) || i < len(toParts); i++ {
+		var f, t int
+		if i < len(fromParts) {
+			f = fromParts[i]
+		}
+		if i < len(toParts) {
+			t = toParts[i]
+		}
+
+		if f < t {
+			return BuildpackUpgraded
+		} else if f > t {
+			return BuildpackDowngraded
+		}
+	}
+	return BuildpackChanged
+}
+
+func parseVersion(version string) ([]int, bool) {
+	var parts []int
+	for _, s := range strings.Split(strings.TrimPrefix(version, "v"), ".") {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, i)
+	}
+	return parts, true
+}
@@ -0,0 +1,114 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+const InTotoPayloadType = "application/vnd.in-toto+json"
+
+// Envelope is a DSSE envelope holding a signed in-toto statement.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// SignPayload signs a payload with the PEM encoded ECDSA, RSA or Ed25519 private key in keyPath.
+// The key id is the hex encoded sha256 of the DER encoded public key.
+func SignPayload(payloadType string, payload []byte, keyPath string) (Envelope, error) {
+	key, err := readPrivateKey(keyPath)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return Envelope{}, errors.Errorf("unsupported private key type %T", key)
+	}
+
+	pae := preAuthEncoding(payloadType, payload)
+
+	var sig []byte
+	switch signer.(type) {
+	case ed25519.PrivateKey:
+		sig, err = signer.Sign(rand.Reader, pae, crypto.Hash(0))
+	case *ecdsa.PrivateKey, *rsa.PrivateKey:
+		digest := sha256.Sum256(pae)
+		sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	default:
+		return Envelope{}, errors.Errorf("unsupported private key type %T", key)
+	}
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return Envelope{}, err
+	}
+	keyID := sha256.Sum256(publicKey)
+
+	return Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []Signature{
+			{
+				KeyID: hex.EncodeToString(keyID[:]),
+				Sig:   base64.StdEncoding.EncodeToString(sig),
+			},
+		},
+	}, nil
+}
+
+// preAuthEncoding is the DSSE v1 pre-authentication encoding of a payload.
+func preAuthEncoding(payloadType string, payload []byte) []byte {
+	return append([]byte(fmt.Sprintf("DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))), payload...)
+}
+
+func readPrivateKey(keyPath string) (crypto.PrivateKey, error) {
+	data, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.Errorf("no PEM encoded private key found in %q", keyPath)
+	}
+
+	if block.Headers["Proc-Type"] != "" || block.Type == "ENCRYPTED PRIVATE KEY" || block.Type == "ENCRYPTED COSIGN PRIVATE KEY" {
+		return nil, errors.Errorf("encrypted private keys are not supported, use an unencrypted PEM encoded ECDSA, RSA or Ed25519 private key: %q", keyPath)
+	}
+
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, errors.Errorf("unsupported PEM block type %q in %q", block.Type, keyPath)
+	}
+}
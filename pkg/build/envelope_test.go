@@ -0,0 +1,120 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestSignPayload(t *testing.T) {
+	spec.Run(t, "TestSignPayload", testSignPayload)
+}
+
+func testSignPayload(t *testing.T, when spec.G, it spec.S) {
+	var dir string
+
+	it.Before(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "envelope-test")
+		require.NoError(t, err)
+	})
+
+	it.After(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	writeKey := func(blockType string, der []byte) string {
+		keyPath := filepath.Join(dir, "key.pem")
+		require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+		return keyPath
+	}
+
+	paeDigest := func(envelope build.Envelope) []byte {
+		payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+		require.NoError(t, err)
+		require.Equal(t, `{"some":"statement"}`, string(payload))
+
+		digest := sha256.Sum256([]byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(envelope.PayloadType), envelope.PayloadType, len(payload), payload)))
+		return digest[:]
+	}
+
+	signature := func(envelope build.Envelope) []byte {
+		require.Len(t, envelope.Signatures, 1)
+		sig, err := base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
+		require.NoError(t, err)
+		return sig
+	}
+
+	it("signs with an ECDSA key", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		der, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		envelope, err := build.SignPayload(build.InTotoPayloadType, []byte(`{"some":"statement"}`), writeKey("EC PRIVATE KEY", der))
+		require.NoError(t, err)
+
+		var sig struct{ R, S *big.Int }
+		_, err = asn1.Unmarshal(signature(envelope), &sig)
+		require.NoError(t, err)
+		require.True(t, ecdsa.Verify(&key.PublicKey, paeDigest(envelope), sig.R, sig.S))
+
+		publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("%x", sha256.Sum256(publicKey)), envelope.Signatures[0].KeyID)
+	})
+
+	it("signs with an RSA key", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		envelope, err := build.SignPayload(build.InTotoPayloadType, []byte(`{"some":"statement"}`), writeKey("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)))
+		require.NoError(t, err)
+
+		require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, paeDigest(envelope), signature(envelope)))
+	})
+
+	it("errors when the key file does not contain a private key", func() {
+		keyPath := filepath.Join(dir, "key.pem")
+		require.NoError(t, ioutil.WriteFile(keyPath, []byte("not a key"), 0600))
+
+		_, err := build.SignPayload(build.InTotoPayloadType, []byte("{}"), keyPath)
+		require.EqualError(t, err, fmt.Sprintf("no PEM encoded private key found in %q", keyPath))
+	})
+
+	it("errors on encrypted cosign keys", func() {
+		keyPath := writeKey("ENCRYPTED COSIGN PRIVATE KEY", []byte(`{"kdf":{"name":"scrypt"}}`))
+
+		_, err := build.SignPayload(build.InTotoPayloadType, []byte("{}"), keyPath)
+		require.EqualError(t, err, fmt.Sprintf("encrypted private keys are not supported, use an unencrypted PEM encoded ECDSA, RSA or Ed25519 private key: %q", keyPath))
+	})
+
+	it("errors on unsupported PEM blocks", func() {
+		keyPath := writeKey("CERTIFICATE", []byte("some-cert"))
+
+		_, err := build.SignPayload(build.InTotoPayloadType, []byte("{}"), keyPath)
+		require.EqualError(t, err, fmt.Sprintf("unsupported PEM block type \"CERTIFICATE\" in %q", keyPath))
+	})
+}
@@ -0,0 +1,33 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fakes
+
+import (
+	"context"
+	"io"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+type FakeLogTailer struct {
+	Logs   map[string]string
+	Errors map[string]error
+	Calls  []LogTailerCall
+}
+
+type LogTailerCall struct {
+	Build   string
+	Options build.LogOptions
+}
+
+func (f *FakeLogTailer) Tail(_ context.Context, writer io.Writer, bld *v1alpha1.Build, opts build.LogOptions) error {
+	f.Calls = append(f.Calls, LogTailerCall{Build: bld.Name, Options: opts})
+	if err, ok := f.Errors[bld.Name]; ok {
+		return err
+	}
+	_, err := io.WriteString(writer, f.Logs[bld.Name])
+	return err
+}
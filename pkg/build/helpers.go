@@ -4,9 +4,24 @@
 package build
 
 import (
+	"time"
+
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 )
 
+// BuildNeededAnnotation on the latest build of an image makes kpack schedule a new build of the image.
+const BuildNeededAnnotation = "image.kpack.io/additionalBuildNeeded"
+
+// RequestBuild returns a copy of the latest build of an image annotated so that kpack schedules a new build.
+func RequestBuild(latest v1alpha1.Build) *v1alpha1.Build {
+	bld := latest.DeepCopy()
+	if bld.Annotations == nil {
+		bld.Annotations = map[string]string{}
+	}
+	bld.Annotations[BuildNeededAnnotation] = time.Now().String()
+	return bld
+}
+
 func Sort(builds []v1alpha1.Build) func(i int, j int) bool {
 	return func(i, j int) bool {
 		return builds[j].ObjectMeta.CreationTimestamp.After(builds[i].ObjectMeta.CreationTimestamp.Time)
@@ -0,0 +1,176 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	k8sclient "k8s.io/client-go/kubernetes"
+)
+
+type LogOptions struct {
+	Steps      []string
+	Timestamps bool
+	Follow     bool
+	TailLines  int64
+	NoColor    bool
+}
+
+type LogsClient struct {
+	k8sClient k8sclient.Interface
+}
+
+func NewLogsClient(k8sClient k8sclient.Interface) *LogsClient {
+	return &LogsClient{k8sClient: k8sClient}
+}
+
+// Tail writes the logs of the lifecycle steps of a build pod to the writer.
+// When following, it waits for containers to start and returns once the pod has finished.
+func (c *LogsClient) Tail(ctx context.Context, writer io.Writer, bld *v1alpha1.Build, opts LogOptions) error {
+	listOptions := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", v1alpha1.BuildLabel, bld.Name),
+	}
+
+	processed := map[string]interface{}{}
+
+	if !opts.Follow {
+		podList, err := c.k8sClient.CoreV1().Pods(bld.Namespace).List(listOptions)
+		if err != nil {
+			return err
+		}
+
+		if len(podList.Items) == 0 {
+			return errors.Errorf("build pod for build %q not found", bld.Name)
+		}
+
+		for i := range podList.Items {
+			if err := c.streamPod(ctx, writer, &podList.Items[i], opts, processed); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	listOptions.Watch = true
+	watcher, err := c.k8sClient.CoreV1().Pods(bld.Namespace).Watch(listOptions)
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok {
+				continue
+			}
+
+			if err := c.streamPod(ctx, writer, pod, opts, processed); err != nil {
+				return err
+			}
+
+			if PodFinished(pod) {
+				return nil
+			}
+		}
+	}
+}
+
+func (c *LogsClient) streamPod(ctx context.Context, writer io.Writer, pod *corev1.Pod, opts LogOptions, processed map[string]interface{}) error {
+	var statuses []corev1.ContainerStatus
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+	for _, status := range statuses {
+		if status.State.Waiting != nil {
+			continue
+		}
+
+		if len(opts.Steps) > 0 && !contains(opts.Steps, status.Name) {
+			continue
+		}
+
+		if _, ok := processed[status.Name]; ok {
+			continue
+		}
+		processed[status.Name] = nil
+
+		if err := c.streamContainer(ctx, writer, pod, status.Name, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *LogsClient) streamContainer(ctx context.Context, writer io.Writer, pod *corev1.Pod, container string, opts LogOptions) error {
+	podLogOptions := &corev1.PodLogOptions{
+		Container:  container,
+		Follow:     opts.Follow,
+		Timestamps: opts.Timestamps,
+	}
+	if opts.TailLines > 0 {
+		podLogOptions.TailLines = &opts.TailLines
+	}
+
+	logReadCloser, err := c.k8sClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, podLogOptions).Stream()
+	if err != nil {
+		return err
+	}
+	defer logReadCloser.Close()
+
+	header := fmt.Sprintf("===> %s\n", strings.ToUpper(container))
+	if !opts.NoColor {
+		header = cyan(header)
+	}
+
+	_, err = fmt.Fprint(writer, header)
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(logReadCloser)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			line, err := r.ReadBytes('\n')
+			if len(line) > 0 {
+				if _, err := writer.Write(line); err != nil {
+					return err
+				}
+			}
+
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func cyan(s string) string {
+	return fmt.Sprintf("%s%s%s", "\033[0;36m", s, "\033[0m")
+}
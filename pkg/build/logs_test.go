@@ -0,0 +1,168 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sfakes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestLogsClient(t *testing.T) {
+	spec.Run(t, "TestLogsClient", testLogsClient)
+}
+
+func testLogsClient(t *testing.T, when spec.G, it spec.S) {
+	const namespace = "some-namespace"
+
+	bld := &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "some-build",
+			Namespace: namespace,
+		},
+	}
+
+	terminated := func(exitCode int32) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}}
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "some-build-pod",
+			Namespace: namespace,
+			Labels:    map[string]string{v1alpha1.BuildLabel: "some-build"},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "prepare", State: terminated(0)},
+				{Name: "detect", State: terminated(0)},
+				{Name: "build", State: terminated(1)},
+				{Name: "export", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+			},
+		},
+	}
+
+	when("the build has finished", func() {
+		var (
+			server    *httptest.Server
+			k8sClient kubernetes.Interface
+			logCalls  []string
+		)
+
+		it.Before(func() {
+			logCalls = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case fmt.Sprintf("/api/v1/namespaces/%s/pods", namespace):
+					require.Equal(t, v1alpha1.BuildLabel+"=some-build", r.URL.Query().Get("labelSelector"))
+					w.Header().Set("Content-Type", "application/json")
+					require.NoError(t, json.NewEncoder(w).Encode(corev1.PodList{Items: []corev1.Pod{*pod}}))
+				case fmt.Sprintf("/api/v1/namespaces/%s/pods/some-build-pod/log", namespace):
+					require.Equal(t, "", r.URL.Query().Get("follow"))
+					container := r.URL.Query().Get("container")
+					logCalls = append(logCalls, container)
+					_, _ = fmt.Fprintf(w, "%s output\n", container)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+
+			var err error
+			k8sClient, err = kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+			require.NoError(t, err)
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("lists the pod without following and writes the logs of the started steps", func() {
+			out := &bytes.Buffer{}
+			err := build.NewLogsClient(k8sClient).Tail(context.Background(), out, bld, build.LogOptions{NoColor: true})
+			require.NoError(t, err)
+
+			require.Equal(t, []string{"prepare", "detect", "build"}, logCalls)
+			require.Equal(t, `===> PREPARE
+prepare output
+===> DETECT
+detect output
+===> BUILD
+build output
+`, out.String())
+		})
+
+		it("only writes the logs of the requested steps", func() {
+			out := &bytes.Buffer{}
+			err := build.NewLogsClient(k8sClient).Tail(context.Background(), out, bld, build.LogOptions{Steps: []string{"build", "export"}, NoColor: true})
+			require.NoError(t, err)
+
+			require.Equal(t, []string{"build"}, logCalls)
+			require.Equal(t, "===> BUILD\nbuild output\n", out.String())
+		})
+
+		it("colors the step headers unless disabled", func() {
+			out := &bytes.Buffer{}
+			err := build.NewLogsClient(k8sClient).Tail(context.Background(), out, bld, build.LogOptions{Steps: []string{"detect"}})
+			require.NoError(t, err)
+
+			require.Equal(t, "\033[0;36m===> DETECT\n\033[0mdetect output\n", out.String())
+		})
+	})
+
+	it("errors when the build pod does not exist", func() {
+		k8sClient := k8sfakes.NewSimpleClientset()
+
+		err := build.NewLogsClient(k8sClient).Tail(context.Background(), &bytes.Buffer{}, bld, build.LogOptions{})
+		require.EqualError(t, err, `build pod for build "some-build" not found`)
+	})
+
+	when("getting step statuses", func() {
+		it("returns the state of each step in order", func() {
+			steps := build.GetStepStatuses(pod)
+
+			var states []string
+			for _, s := range steps {
+				states = append(states, s.Name+"="+s.State)
+			}
+			require.Equal(t, []string{"prepare=Succeeded", "detect=Succeeded", "build=Failed", "export=Skipped"}, states)
+			require.Equal(t, "1", steps[2].ExitCodeString())
+			require.Equal(t, "--", steps[3].ExitCodeString())
+		})
+
+		it("reports steps that have not started as waiting while the pod runs", func() {
+			running := pod.DeepCopy()
+			running.Status.Phase = corev1.PodRunning
+			running.Status.InitContainerStatuses[2].State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(time.Now())}}
+
+			steps := build.GetStepStatuses(running)
+			require.Equal(t, build.StepRunning, steps[2].State)
+			require.Equal(t, build.StepWaiting, steps[3].State)
+			require.Equal(t, "PodInitializing", steps[3].Reason)
+			require.Equal(t, "--", steps[2].DurationString())
+		})
+
+		it("finds the failed step", func() {
+			step, ok := build.GetFailedStep(pod)
+			require.True(t, ok)
+			require.Equal(t, "build", step.Name)
+		})
+	})
+}
@@ -0,0 +1,132 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pivotal/kpack/pkg/registry/imagehelpers"
+	"github.com/pkg/errors"
+)
+
+const (
+	BuildMetadataLabel     = "io.buildpacks.build.metadata"
+	LifecycleMetadataLabel = "io.buildpacks.lifecycle.metadata"
+	StackIdLabel           = "io.buildpacks.stack.id"
+)
+
+type BuildpackInfo struct {
+	Id       string `json:"id"`
+	Version  string `json:"version"`
+	Homepage string `json:"homepage,omitempty"`
+}
+
+type BOMEntry struct {
+	Name      string                 `json:"name"`
+	Version   string                 `json:"version,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Buildpack BuildpackInfo          `json:"buildpack"`
+}
+
+type Process struct {
+	Type        string   `json:"type"`
+	Command     string   `json:"command"`
+	Args        []string `json:"args,omitempty"`
+	Direct      bool     `json:"direct"`
+	BuildpackId string   `json:"buildpackID,omitempty"`
+}
+
+func (p Process) CommandLine() string {
+	return strings.Join(append([]string{p.Command}, p.Args...), " ")
+}
+
+type RunImageInfo struct {
+	Image    string   `json:"image"`
+	TopLayer string   `json:"topLayer,omitempty"`
+	Mirrors  []string `json:"mirrors,omitempty"`
+}
+
+// ImageMetadata is the metadata written by the buildpacks lifecycle to the labels of a built image.
+type ImageMetadata struct {
+	StackId    string          `json:"stackId"`
+	RunImage   RunImageInfo    `json:"runImage"`
+	Buildpacks []BuildpackInfo `json:"buildpacks"`
+	Processes  []Process       `json:"processes"`
+	BOM        []BOMEntry      `json:"bom"`
+}
+
+type buildMetadata struct {
+	BOM        []BOMEntry      `json:"bom"`
+	Buildpacks []BuildpackInfo `json:"buildpacks"`
+	Processes  []Process       `json:"processes"`
+}
+
+type lifecycleMetadata struct {
+	RunImage struct {
+		TopLayer  string `json:"topLayer"`
+		Reference string `json:"reference"`
+	} `json:"runImage"`
+	Stack struct {
+		RunImage struct {
+			Image   string   `json:"image"`
+			Mirrors []string `json:"mirrors"`
+		} `json:"runImage"`
+	} `json:"stack"`
+}
+
+func ReadImageMetadata(img v1.Image) (ImageMetadata, error) {
+	var buildMd buildMetadata
+	if err := imagehelpers.GetLabel(img, BuildMetadataLabel, &buildMd); err != nil {
+		return ImageMetadata{}, errors.Wrap(err, "image was not built by buildpacks")
+	}
+
+	var lifecycleMd lifecycleMetadata
+	if err := imagehelpers.GetLabel(img, LifecycleMetadataLabel, &lifecycleMd); err != nil {
+		return ImageMetadata{}, errors.Wrap(err, "image was not built by buildpacks")
+	}
+
+	hasStackId, err := imagehelpers.HasLabel(img, StackIdLabel)
+	if err != nil {
+		return ImageMetadata{}, err
+	}
+
+	var stackId string
+	if hasStackId {
+		stackId, err = imagehelpers.GetStringLabel(img, StackIdLabel)
+		if err != nil {
+			return ImageMetadata{}, err
+		}
+	}
+
+	runImage, err := runImageReference(lifecycleMd.Stack.RunImage.Image, lifecycleMd.RunImage.Reference)
+	if err != nil {
+		return ImageMetadata{}, err
+	}
+
+	return ImageMetadata{
+		StackId: stackId,
+		RunImage: RunImageInfo{
+			Image:    runImage,
+			TopLayer: lifecycleMd.RunImage.TopLayer,
+			Mirrors:  lifecycleMd.Stack.RunImage.Mirrors,
+		},
+		Buildpacks: buildMd.Buildpacks,
+		Processes:  buildMd.Processes,
+		BOM:        buildMd.BOM,
+	}, nil
+}
+
+func runImageReference(image, reference string) (string, error) {
+	if image == "" || !strings.HasPrefix(reference, "sha256:") {
+		return reference, nil
+	}
+
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return "", err
+	}
+	return ref.Context().Name() + "@" + reference, nil
+}
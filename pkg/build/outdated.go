@@ -0,0 +1,86 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"sort"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+)
+
+// OutdatedBuildpacks returns the buildpacks of a build for which the builder now provides a different version.
+// When the builder provides several versions of a buildpack the highest version is used.
+// Buildpacks that the builder no longer provides are not reported.
+func OutdatedBuildpacks(built, available v1alpha1.BuildpackMetadataList) []BuildpackChange {
+	latest := map[string]string{}
+	for _, bp := range available {
+		current, ok := latest[bp.Id]
+		if !ok || versionChange(current, bp.Version) == BuildpackUpgraded {
+			latest[bp.Id] = bp.Version
+		}
+	}
+
+	changes := []BuildpackChange{}
+	for _, bp := range built {
+		version, ok := latest[bp.Id]
+		if !ok || version == bp.Version {
+			continue
+		}
+
+		changes = append(changes, BuildpackChange{Id: bp.Id, From: bp.Version, To: version, Change: versionChange(bp.Version, version)})
+	}
+	return changes
+}
+
+// RunImagesBehind returns the number of run images of the repository of the current run image
+// that builds started using after runImage, including the current run image.
+// It returns false when the order of the run images cannot be determined from the builds.
+func RunImagesBehind(builds []v1alpha1.Build, runImage, current string) (int, bool) {
+	if runImage == current {
+		return 0, true
+	}
+
+	repository := repositoryName(current)
+
+	sorted := make([]v1alpha1.Build, len(builds))
+	copy(sorted, builds)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+	})
+
+	var history []string
+	seen := map[string]bool{}
+	for _, bld := range sorted {
+		image := bld.Status.Stack.RunImage
+		if image == "" || seen[image] || repositoryName(image) != repository {
+			continue
+		}
+		seen[image] = true
+		history = append(history, image)
+	}
+
+	if !seen[current] {
+		history = append(history, current)
+	}
+
+	position := map[string]int{}
+	for i, image := range history {
+		position[image] = i
+	}
+
+	from, ok := position[runImage]
+	if !ok || position[current] <= from {
+		return 0, false
+	}
+	return position[current] - from, true
+}
+
+func repositoryName(image string) string {
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return image
+	}
+	return ref.Context().Name()
+}
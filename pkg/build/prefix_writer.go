@@ -0,0 +1,69 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var prefixColors = []string{"32", "33", "34", "35", "36", "31"}
+
+// ColorPrefix colors a prefix with one of a rotating set of colors chosen by index.
+func ColorPrefix(index int, prefix string) string {
+	return fmt.Sprintf("\033[0;%sm%s\033[0m", prefixColors[index%len(prefixColors)], prefix)
+}
+
+// PrefixWriter prefixes every line written to it before passing it to the underlying writer.
+// Writers sharing a lock never interleave partial lines.
+type PrefixWriter struct {
+	writer io.Writer
+	lock   *sync.Mutex
+	prefix string
+	buf    bytes.Buffer
+}
+
+func NewPrefixWriter(writer io.Writer, lock *sync.Mutex, prefix string) *PrefixWriter {
+	return &PrefixWriter{
+		writer: writer,
+		lock:   lock,
+		prefix: prefix,
+	}
+}
+
+func (w *PrefixWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+
+		if err := w.writeLine(w.buf.Next(i + 1)); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush writes any remaining partial line.
+func (w *PrefixWriter) Flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	line := append(w.buf.Bytes(), '\n')
+	w.buf.Reset()
+	return w.writeLine(line)
+}
+
+func (w *PrefixWriter) writeLine(line []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	_, err := fmt.Fprintf(w.writer, "%s %s", w.prefix, line)
+	return err
+}
@@ -0,0 +1,73 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	spec.Run(t, "TestPrefixWriter", testPrefixWriter)
+}
+
+func testPrefixWriter(t *testing.T, when spec.G, it spec.S) {
+	var (
+		out  *bytes.Buffer
+		lock *sync.Mutex
+	)
+
+	it.Before(func() {
+		out = &bytes.Buffer{}
+		lock = &sync.Mutex{}
+	})
+
+	it("prefixes complete lines", func() {
+		w := build.NewPrefixWriter(out, lock, "[img#1]")
+
+		_, err := w.Write([]byte("first line\nsecond "))
+		require.NoError(t, err)
+		require.Equal(t, "[img#1] first line\n", out.String())
+
+		_, err = w.Write([]byte("line\n"))
+		require.NoError(t, err)
+		require.Equal(t, "[img#1] first line\n[img#1] second line\n", out.String())
+	})
+
+	it("writes the remaining partial line on flush", func() {
+		w := build.NewPrefixWriter(out, lock, "[img#1]")
+
+		_, err := w.Write([]byte("no newline"))
+		require.NoError(t, err)
+		require.Equal(t, "", out.String())
+
+		require.NoError(t, w.Flush())
+		require.Equal(t, "[img#1] no newline\n", out.String())
+	})
+
+	it("does not interleave lines from writers sharing a lock", func() {
+		one := build.NewPrefixWriter(out, lock, "[one#1]")
+		two := build.NewPrefixWriter(out, lock, "[two#1]")
+
+		_, err := one.Write([]byte("partial "))
+		require.NoError(t, err)
+		_, err = two.Write([]byte("two\n"))
+		require.NoError(t, err)
+		_, err = one.Write([]byte("one\n"))
+		require.NoError(t, err)
+
+		require.Equal(t, "[two#1] two\n[one#1] partial one\n", out.String())
+	})
+
+	it("colors prefixes by index", func() {
+		require.Equal(t, "\033[0;32m[img#1]\033[0m", build.ColorPrefix(0, "[img#1]"))
+		require.Equal(t, build.ColorPrefix(0, "[img#1]"), build.ColorPrefix(6, "[img#1]"))
+	})
+}
@@ -0,0 +1,262 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+)
+
+const (
+	InTotoStatementType  = "https://in-toto.io/Statement/v0.1"
+	SLSAProvenanceType   = "https://slsa.dev/provenance/v0.1"
+	KpackBuildRecipeType = "https://kpack.io/Build@v1alpha1"
+)
+
+type DigestSet map[string]string
+
+type ProvenanceStatement struct {
+	Type          string              `json:"_type"`
+	Subject       []ProvenanceSubject `json:"subject"`
+	PredicateType string              `json:"predicateType"`
+	Predicate     ProvenancePredicate `json:"predicate"`
+}
+
+type ProvenanceSubject struct {
+	Name   string    `json:"name"`
+	Digest DigestSet `json:"digest"`
+}
+
+type ProvenancePredicate struct {
+	Builder   ProvenanceBuilder    `json:"builder"`
+	Recipe    ProvenanceRecipe     `json:"recipe"`
+	Metadata  ProvenanceMetadata   `json:"metadata"`
+	Materials []ProvenanceMaterial `json:"materials"`
+}
+
+type ProvenanceBuilder struct {
+	ID string `json:"id"`
+}
+
+type ProvenanceRecipe struct {
+	Type              string              `json:"type"`
+	DefinedInMaterial *int                `json:"definedInMaterial,omitempty"`
+	EntryPoint        string              `json:"entryPoint"`
+	Arguments         ProvenanceArguments `json:"arguments"`
+	Environment       map[string]string   `json:"environment,omitempty"`
+}
+
+type ProvenanceArguments struct {
+	BuildReason string          `json:"buildReason,omitempty"`
+	Stack       ProvenanceStack `json:"stack"`
+	Buildpacks  []BuildpackInfo `json:"buildpacks"`
+}
+
+type ProvenanceStack struct {
+	ID         string `json:"id,omitempty"`
+	RunImage   string `json:"runImage,omitempty"`
+	BuildImage string `json:"buildImage,omitempty"`
+}
+
+type ProvenanceMetadata struct {
+	BuildInvocationID string                 `json:"buildInvocationId"`
+	BuildStartedOn    string                 `json:"buildStartedOn,omitempty"`
+	BuildFinishedOn   string                 `json:"buildFinishedOn,omitempty"`
+	Completeness      ProvenanceCompleteness `json:"completeness"`
+	Reproducible      bool                   `json:"reproducible"`
+}
+
+type ProvenanceCompleteness struct {
+	Arguments   bool `json:"arguments"`
+	Environment bool `json:"environment"`
+	Materials   bool `json:"materials"`
+}
+
+type ProvenanceMaterial struct {
+	URI    string    `json:"uri"`
+	Digest DigestSet `json:"digest,omitempty"`
+}
+
+// NewProvenanceStatement describes how the image produced by a build was built as an in-toto statement
+// with a SLSA provenance predicate.
+// Builds do not record the build image of their stack, so it is resolved from the optional cluster stack of the builder.
+// The materials are only marked as complete when the cluster stack still has the run image used by the build.
+func NewProvenanceStatement(bld v1alpha1.Build, imageDigest v1.Hash, md ImageMetadata, stack *v1alpha1.ClusterStack) (ProvenanceStatement, error) {
+	subject, err := name.ParseReference(bld.Status.LatestImage, name.WeakValidation)
+	if err != nil {
+		return ProvenanceStatement{}, err
+	}
+
+	runImage := md.RunImage.Image
+	if runImage == "" {
+		runImage = bld.Status.Stack.RunImage
+	}
+
+	stackId := md.StackId
+	if stackId == "" {
+		stackId = bld.Status.Stack.ID
+	}
+
+	buildImage, err := stackBuildImage(stack, stackId, runImage)
+	if err != nil {
+		return ProvenanceStatement{}, err
+	}
+
+	var materials []ProvenanceMaterial
+	var definedInMaterial *int
+
+	if source, ok := sourceMaterial(bld.Spec.Source); ok {
+		materials = append(materials, source)
+		definedInMaterial = new(int)
+	}
+
+	for _, image := range []string{bld.Spec.Builder.Image, runImage, buildImage} {
+		if image == "" {
+			continue
+		}
+
+		material, err := imageMaterial(image)
+		if err != nil {
+			return ProvenanceStatement{}, err
+		}
+		materials = append(materials, material)
+	}
+
+	env := map[string]string{}
+	for _, e := range bld.Spec.Env {
+		env[e.Name] = e.Value
+	}
+
+	buildpacks := md.Buildpacks
+	if len(buildpacks) == 0 {
+		for _, bp := range bld.Status.BuildMetadata {
+			buildpacks = append(buildpacks, BuildpackInfo{Id: bp.Id, Version: bp.Version})
+		}
+	}
+
+	return ProvenanceStatement{
+		Type: InTotoStatementType,
+		Subject: []ProvenanceSubject{
+			{
+				Name:   subject.Context().Name(),
+				Digest: DigestSet{imageDigest.Algorithm: imageDigest.Hex},
+			},
+		},
+		PredicateType: SLSAProvenanceType,
+		Predicate: ProvenancePredicate{
+			Builder: ProvenanceBuilder{
+				ID: bld.Spec.Builder.Image,
+			},
+			Recipe: ProvenanceRecipe{
+				Type:              KpackBuildRecipeType,
+				DefinedInMaterial: definedInMaterial,
+				EntryPoint:        bld.Labels[v1alpha1.ImageLabel],
+				Arguments: ProvenanceArguments{
+					BuildReason: bld.Annotations[v1alpha1.BuildReasonAnnotation],
+					Stack: ProvenanceStack{
+						ID:         stackId,
+						RunImage:   runImage,
+						BuildImage: buildImage,
+					},
+					Buildpacks: buildpacks,
+				},
+				Environment: env,
+			},
+			Metadata: ProvenanceMetadata{
+				BuildInvocationID: bld.Namespace + "/" + bld.Name,
+				BuildStartedOn:    formatProvenanceTime(bld.CreationTimestamp.Time),
+				BuildFinishedOn:   formatProvenanceTime(buildFinishedOn(bld)),
+				Completeness: ProvenanceCompleteness{
+					Arguments:   true,
+					Environment: true,
+					Materials:   buildImage != "",
+				},
+				Reproducible: false,
+			},
+			Materials: materials,
+		},
+	}, nil
+}
+
+// stackBuildImage returns the build image of a cluster stack when its run image is the run image used by a build.
+// A different run image means the stack was updated after the build, so its build image is not returned.
+func stackBuildImage(stack *v1alpha1.ClusterStack, stackId, runImage string) (string, error) {
+	if stack == nil || runImage == "" || stack.Status.RunImage.LatestImage == "" || stack.Status.BuildImage.LatestImage == "" || stack.Status.Id != stackId {
+		return "", nil
+	}
+
+	used, err := imageMaterial(runImage)
+	if err != nil {
+		return "", err
+	}
+
+	current, err := imageMaterial(stack.Status.RunImage.LatestImage)
+	if err != nil {
+		return "", err
+	}
+
+	if len(used.Digest) == 0 || !reflect.DeepEqual(used.Digest, current.Digest) {
+		return "", nil
+	}
+	return stack.Status.BuildImage.LatestImage, nil
+}
+
+func sourceMaterial(source v1alpha1.SourceConfig) (ProvenanceMaterial, bool) {
+	switch {
+	case source.Git != nil:
+		return ProvenanceMaterial{
+			URI:    "git+" + source.Git.URL,
+			Digest: DigestSet{"sha1": source.Git.Revision},
+		}, true
+	case source.Blob != nil:
+		return ProvenanceMaterial{URI: source.Blob.URL}, true
+	case source.Registry != nil:
+		material, err := imageMaterial(source.Registry.Image)
+		return material, err == nil
+	default:
+		return ProvenanceMaterial{}, false
+	}
+}
+
+func imageMaterial(image string) (ProvenanceMaterial, error) {
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return ProvenanceMaterial{}, err
+	}
+
+	digest, ok := ref.(name.Digest)
+	if !ok {
+		return ProvenanceMaterial{URI: ref.Name()}, nil
+	}
+
+	hash, err := v1.NewHash(digest.DigestStr())
+	if err != nil {
+		return ProvenanceMaterial{}, err
+	}
+
+	return ProvenanceMaterial{
+		URI:    ref.Context().Name(),
+		Digest: DigestSet{hash.Algorithm: hash.Hex},
+	}, nil
+}
+
+func buildFinishedOn(bld v1alpha1.Build) time.Time {
+	cond := bld.Status.GetCondition(corev1alpha1.ConditionSucceeded)
+	if cond == nil || bld.IsRunning() {
+		return time.Time{}
+	}
+	return cond.LastTransitionTime.Inner.Time
+}
+
+func formatProvenanceTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
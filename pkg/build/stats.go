@@ -0,0 +1,226 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	StackReason     = "STACK"
+	BuildpackReason = "BUILDPACK"
+)
+
+type ReasonCount struct {
+	Reason    string `json:"reason"`
+	Total     int    `json:"total"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+	Running   int    `json:"running"`
+}
+
+type FailureCount struct {
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name"`
+	Failures  int    `json:"failures"`
+}
+
+type Stats struct {
+	Total             int            `json:"total"`
+	Succeeded         int            `json:"succeeded"`
+	Failed            int            `json:"failed"`
+	Running           int            `json:"running"`
+	SuccessRate       float64        `json:"successRate"`
+	DurationP50       time.Duration  `json:"-"`
+	DurationP95       time.Duration  `json:"-"`
+	DurationP50Secs   float64        `json:"durationP50Seconds"`
+	DurationP95Secs   float64        `json:"durationP95Seconds"`
+	Reasons           []ReasonCount  `json:"reasons"`
+	FailingImages     []FailureCount `json:"failingImages"`
+	FailingBuilders   []FailureCount `json:"failingBuilders"`
+	StackRebuilds     int            `json:"stackRebuilds"`
+	BuildpackRebuilds int            `json:"buildpackRebuilds"`
+}
+
+// CalculateStats aggregates the outcomes, durations and reasons of builds.
+// Failing builders are taken from the images of the builds and are left empty when the image no longer exists.
+// Only the top failing images and builders are included.
+func CalculateStats(builds []v1alpha1.Build, images []v1alpha1.Image, top int) Stats {
+	stats := Stats{
+		Reasons:         []ReasonCount{},
+		FailingImages:   []FailureCount{},
+		FailingBuilders: []FailureCount{},
+	}
+
+	reasons := map[string]*ReasonCount{}
+	imageFailures := map[string]*FailureCount{}
+	builderFailures := map[string]*FailureCount{}
+	imageBuilders := map[string]corev1.ObjectReference{}
+	var durations []time.Duration
+
+	for _, img := range images {
+		imageBuilders[img.Namespace+"/"+img.Name] = img.Spec.Builder
+	}
+
+	for i := range builds {
+		b := &builds[i]
+		stats.Total++
+
+		cond := b.Status.GetCondition(corev1alpha1.ConditionSucceeded)
+		succeeded, failed := b.IsSuccess(), b.IsFailure()
+		switch {
+		case succeeded:
+			stats.Succeeded++
+		case failed:
+			stats.Failed++
+		default:
+			stats.Running++
+		}
+
+		if (succeeded || failed) && !cond.LastTransitionTime.Inner.IsZero() {
+			durations = append(durations, cond.LastTransitionTime.Inner.Sub(b.CreationTimestamp.Time))
+		}
+
+		for _, reason := range buildReasons(*b) {
+			rc, ok := reasons[reason]
+			if !ok {
+				rc = &ReasonCount{Reason: reason}
+				reasons[reason] = rc
+			}
+
+			rc.Total++
+			switch {
+			case succeeded:
+				rc.Succeeded++
+			case failed:
+				rc.Failed++
+			default:
+				rc.Running++
+			}
+
+			if reason == StackReason {
+				stats.StackRebuilds++
+			} else if reason == BuildpackReason {
+				stats.BuildpackRebuilds++
+			}
+		}
+
+		if failed {
+			addFailure(imageFailures, b.Namespace, "", b.Labels[v1alpha1.ImageLabel])
+
+			builder := imageBuilders[b.Namespace+"/"+b.Labels[v1alpha1.ImageLabel]]
+			builderNamespace := ""
+			if builder.Kind == v1alpha1.BuilderKind {
+				builderNamespace = b.Namespace
+			}
+			addFailure(builderFailures, builderNamespace, builder.Kind, builder.Name)
+		}
+	}
+
+	if finished := stats.Succeeded + stats.Failed; finished > 0 {
+		stats.SuccessRate = math.Round(float64(stats.Succeeded)/float64(finished)*1000) / 10
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.DurationP50 = percentile(durations, 50)
+	stats.DurationP95 = percentile(durations, 95)
+	stats.DurationP50Secs = stats.DurationP50.Seconds()
+	stats.DurationP95Secs = stats.DurationP95.Seconds()
+
+	for _, rc := range reasons {
+		stats.Reasons = append(stats.Reasons, *rc)
+	}
+	sort.Slice(stats.Reasons, SortReasons(stats.Reasons))
+
+	stats.FailingImages = topFailures(imageFailures, top)
+	stats.FailingBuilders = topFailures(builderFailures, top)
+
+	return stats
+}
+
+// FilterSince returns the builds created at or after the provided time.
+func FilterSince(builds []v1alpha1.Build, since time.Time) []v1alpha1.Build {
+	var filtered []v1alpha1.Build
+	for _, b := range builds {
+		if !b.CreationTimestamp.Time.Before(since) {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered
+}
+
+func SortReasons(reasons []ReasonCount) func(i int, j int) bool {
+	return func(i, j int) bool {
+		if reasons[i].Total != reasons[j].Total {
+			return reasons[i].Total > reasons[j].Total
+		}
+		return reasons[i].Reason < reasons[j].Reason
+	}
+}
+
+func SortFailures(failures []FailureCount) func(i int, j int) bool {
+	return func(i, j int) bool {
+		if failures[i].Failures != failures[j].Failures {
+			return failures[i].Failures > failures[j].Failures
+		}
+		if failures[i].Namespace != failures[j].Namespace {
+			return failures[i].Namespace < failures[j].Namespace
+		}
+		if failures[i].Kind != failures[j].Kind {
+			return failures[i].Kind < failures[j].Kind
+		}
+		return failures[i].Name < failures[j].Name
+	}
+}
+
+func buildReasons(b v1alpha1.Build) []string {
+	reasons := b.Annotations[v1alpha1.BuildReasonAnnotation]
+	if reasons == "" {
+		return []string{"UNKNOWN"}
+	}
+	return strings.Split(reasons, ",")
+}
+
+func addFailure(failures map[string]*FailureCount, namespace, kind, name string) {
+	key := kind + "/" + namespace + "/" + name
+	fc, ok := failures[key]
+	if !ok {
+		fc = &FailureCount{Namespace: namespace, Kind: kind, Name: name}
+		failures[key] = fc
+	}
+	fc.Failures++
+}
+
+func topFailures(failures map[string]*FailureCount, top int) []FailureCount {
+	result := []FailureCount{}
+	for _, fc := range failures {
+		result = append(result, *fc)
+	}
+	sort.Slice(result, SortFailures(result))
+
+	if top > 0 && len(result) > top {
+		result = result[:top]
+	}
+	return result
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,83 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+func TestCalculateStats(t *testing.T) {
+	spec.Run(t, "TestCalculateStats", testCalculateStats)
+}
+
+func testCalculateStats(t *testing.T, when spec.G, it spec.S) {
+	const namespace = "some-namespace"
+
+	makeBuild := func(name, image, buildNumber string) v1alpha1.Build {
+		return v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       image,
+					v1alpha1.BuildNumberLabel: buildNumber,
+				},
+			},
+			Spec: v1alpha1.BuildSpec{
+				Builder: v1alpha1.BuildBuilderSpec{Image: "some-repo.com/builder@sha256:" + name},
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{
+						{Type: corev1alpha1.ConditionSucceeded, Status: corev1.ConditionFalse},
+					},
+				},
+			},
+		}
+	}
+
+	makeImage := func(name string, builder corev1.ObjectReference) v1alpha1.Image {
+		return v1alpha1.Image{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1alpha1.ImageSpec{Builder: builder},
+		}
+	}
+
+	builds := []v1alpha1.Build{
+		makeBuild("some-image-2", "some-image", "2"),
+		makeBuild("some-image-1", "some-image", "1"),
+		makeBuild("other-image-1", "other-image", "1"),
+	}
+
+	images := []v1alpha1.Image{
+		makeImage("some-image", corev1.ObjectReference{Kind: v1alpha1.ClusterBuilderKind, Name: "some-builder"}),
+		makeImage("other-image", corev1.ObjectReference{Kind: v1alpha1.BuilderKind, Name: "some-builder"}),
+	}
+
+	it("does not reorder the provided builds", func() {
+		build.CalculateStats(builds, images, 0)
+
+		require.Equal(t, "some-image-2", builds[0].Name)
+		require.Equal(t, "some-image-1", builds[1].Name)
+		require.Equal(t, "other-image-1", builds[2].Name)
+	})
+
+	it("counts builder failures by the builder of the image rather than the builder image digest", func() {
+		stats := build.CalculateStats(builds, images, 0)
+
+		require.Equal(t, []build.FailureCount{
+			{Kind: v1alpha1.ClusterBuilderKind, Name: "some-builder", Failures: 2},
+			{Namespace: namespace, Kind: v1alpha1.BuilderKind, Name: "some-builder", Failures: 1},
+		}, stats.FailingBuilders)
+	})
+}
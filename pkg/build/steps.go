@@ -0,0 +1,123 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var LifecycleSteps = []string{"prepare", "detect", "analyze", "restore", "build", "export"}
+
+const (
+	StepWaiting   = "Waiting"
+	StepRunning   = "Running"
+	StepSucceeded = "Succeeded"
+	StepFailed    = "Failed"
+	StepSkipped   = "Skipped"
+)
+
+type StepStatus struct {
+	Name     string
+	State    string
+	ExitCode *int32
+	Reason   string
+	Started  time.Time
+	Finished time.Time
+}
+
+func (s StepStatus) Duration() time.Duration {
+	if s.Started.IsZero() || s.Finished.IsZero() {
+		return 0
+	}
+	return s.Finished.Sub(s.Started)
+}
+
+func (s StepStatus) ExitCodeString() string {
+	if s.ExitCode == nil {
+		return "--"
+	}
+	return strconv.Itoa(int(*s.ExitCode))
+}
+
+func (s StepStatus) DurationString() string {
+	if s.State != StepSucceeded && s.State != StepFailed {
+		return "--"
+	}
+	return s.Duration().Round(time.Second).String()
+}
+
+// GetStepStatuses returns the status of each lifecycle step of a build pod in execution order.
+func GetStepStatuses(pod *corev1.Pod) []StepStatus {
+	var steps []StepStatus
+	for _, c := range pod.Status.InitContainerStatuses {
+		steps = append(steps, getStepStatus(c, PodFinished(pod)))
+	}
+	return steps
+}
+
+// GetFailedStep returns the first step of a build pod that terminated with a non-zero exit code.
+func GetFailedStep(pod *corev1.Pod) (StepStatus, bool) {
+	for _, s := range GetStepStatuses(pod) {
+		if s.State == StepFailed {
+			return s, true
+		}
+	}
+	return StepStatus{}, false
+}
+
+func PodFinished(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded
+}
+
+func ValidateSteps(steps []string) error {
+	for _, s := range steps {
+		if !contains(LifecycleSteps, s) {
+			return errors.Errorf("invalid step %q, must be one of prepare, detect, analyze, restore, build, or export", s)
+		}
+	}
+	return nil
+}
+
+func getStepStatus(c corev1.ContainerStatus, podFinished bool) StepStatus {
+	status := StepStatus{Name: c.Name}
+
+	switch {
+	case c.State.Terminated != nil:
+		exitCode := c.State.Terminated.ExitCode
+		status.ExitCode = &exitCode
+		status.Reason = c.State.Terminated.Reason
+		status.Started = c.State.Terminated.StartedAt.Time
+		status.Finished = c.State.Terminated.FinishedAt.Time
+		if exitCode == 0 {
+			status.State = StepSucceeded
+		} else {
+			status.State = StepFailed
+		}
+	case c.State.Running != nil:
+		status.State = StepRunning
+		status.Started = c.State.Running.StartedAt.Time
+	case podFinished:
+		status.State = StepSkipped
+	default:
+		status.State = StepWaiting
+		if c.State.Waiting != nil {
+			status.Reason = c.State.Waiting.Reason
+		}
+	}
+
+	return status
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
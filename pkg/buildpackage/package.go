@@ -0,0 +1,473 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package buildpackage
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pivotal/kpack/pkg/registry/imagehelpers"
+	"github.com/pkg/errors"
+
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+const (
+	buildpackLayersLabel = "io.buildpacks.buildpack.layers"
+	packageConfigFile    = "package.toml"
+	buildpackConfigFile  = "buildpack.toml"
+	buildpacksDir        = "/cnb/buildpacks"
+)
+
+// normalizedTime is used for the layer entries and creation time of packaged buildpacks,
+// so that packaging the same source twice results in the same image.
+var normalizedTime = time.Date(1980, time.January, 1, 0, 0, 1, 0, time.UTC)
+
+type buildpackageMetadata struct {
+	Id       string                    `json:"id"`
+	Version  string                    `json:"version,omitempty"`
+	Homepage string                    `json:"homepage,omitempty"`
+	Stacks   []v1alpha1.BuildpackStack `json:"stacks,omitempty"`
+}
+
+type buildpackLayerInfo struct {
+	API         string                    `json:"api"`
+	LayerDiffID string                    `json:"layerDiffID"`
+	Order       v1alpha1.Order            `json:"order,omitempty"`
+	Stacks      []v1alpha1.BuildpackStack `json:"stacks,omitempty"`
+	Homepage    string                    `json:"homepage,omitempty"`
+}
+
+type buildpackLayers map[string]map[string]buildpackLayerInfo
+
+type packageConfig struct {
+	Buildpack    packageDependency   `toml:"buildpack"`
+	Dependencies []packageDependency `toml:"dependencies"`
+}
+
+type packageDependency struct {
+	URI   string `toml:"uri"`
+	Image string `toml:"image"`
+}
+
+type buildpackConfig struct {
+	API       string `toml:"api"`
+	Buildpack struct {
+		Id       string `toml:"id"`
+		Version  string `toml:"version"`
+		Homepage string `toml:"homepage"`
+	} `toml:"buildpack"`
+	Stacks []struct {
+		Id     string   `toml:"id"`
+		Mixins []string `toml:"mixins"`
+	} `toml:"stacks"`
+	Order []struct {
+		Group []struct {
+			Id       string `toml:"id"`
+			Version  string `toml:"version"`
+			Optional bool   `toml:"optional"`
+		} `toml:"group"`
+	} `toml:"order"`
+}
+
+func (c buildpackConfig) stacks() []v1alpha1.BuildpackStack {
+	var stacks []v1alpha1.BuildpackStack
+	for _, s := range c.Stacks {
+		stacks = append(stacks, v1alpha1.BuildpackStack{ID: s.Id, Mixins: s.Mixins})
+	}
+	return stacks
+}
+
+func (c buildpackConfig) order() v1alpha1.Order {
+	var order v1alpha1.Order
+	for _, entry := range c.Order {
+		var group []v1alpha1.BuildpackRef
+		for _, ref := range entry.Group {
+			group = append(group, v1alpha1.BuildpackRef{
+				BuildpackInfo: v1alpha1.BuildpackInfo{Id: ref.Id, Version: ref.Version},
+				Optional:      ref.Optional,
+			})
+		}
+		order = append(order, v1alpha1.OrderEntry{Group: group})
+	}
+	return order
+}
+
+// isBuildpackSource reports whether a buildpackage location is a buildpack directory or a package.toml to package.
+func isBuildpackSource(buildPackage string) bool {
+	fi, err := os.Stat(buildPackage)
+	if err != nil {
+		return false
+	}
+	return fi.IsDir() || filepath.Base(buildPackage) == packageConfigFile
+}
+
+// readPackageConfig reads the package.toml of a buildpack directory or at a path.
+// A directory without a package.toml is packaged as a single buildpack.
+func readPackageConfig(buildPackage string) (packageConfig, string, error) {
+	config := packageConfig{Buildpack: packageDependency{URI: "."}}
+
+	fi, err := os.Stat(buildPackage)
+	if err != nil {
+		return config, "", err
+	}
+
+	configPath := buildPackage
+	if fi.IsDir() {
+		configPath = filepath.Join(buildPackage, packageConfigFile)
+		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+			return config, buildPackage, nil
+		}
+	}
+
+	config = packageConfig{}
+	if _, err := toml.DecodeFile(configPath, &config); err != nil {
+		return config, "", errors.Wrapf(err, "reading %s", configPath)
+	}
+
+	if config.Buildpack.URI == "" {
+		return config, "", errors.Errorf("%s must provide a buildpack uri", configPath)
+	}
+	return config, filepath.Dir(configPath), nil
+}
+
+// packageBuildpack builds a buildpackage image from a buildpack directory and the dependencies in its package.toml.
+func (u *Uploader) packageBuildpack(buildPackage, tempDir string, tlsCfg registry.TLSConfig) (v1.Image, error) {
+	config, baseDir, err := readPackageConfig(buildPackage)
+	if err != nil {
+		return nil, err
+	}
+
+	buildpackDir, err := localPath(baseDir, config.Buildpack.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	var bp buildpackConfig
+	if _, err := toml.DecodeFile(filepath.Join(buildpackDir, buildpackConfigFile), &bp); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", buildpackConfigFile)
+	}
+
+	if err := validateBuildpack(bp); err != nil {
+		return nil, err
+	}
+
+	image, err := mutate.ConfigFile(empty.Image, &v1.ConfigFile{
+		OS:      "linux",
+		Created: v1.Time{Time: normalizedTime},
+		Config:  v1.Config{Labels: map[string]string{}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	layers := buildpackLayers{}
+	var dependencyStacks [][]v1alpha1.BuildpackStack
+	for _, dependency := range config.Dependencies {
+		depImage, err := u.readDependency(baseDir, dependency, tempDir, tlsCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		image, err = addDependency(image, depImage, layers)
+		if err != nil {
+			return nil, err
+		}
+
+		var depMetadata buildpackageMetadata
+		if err := imagehelpers.GetLabel(depImage, buildpackageMetadataLabel, &depMetadata); err != nil {
+			return nil, err
+		}
+		dependencyStacks = append(dependencyStacks, depMetadata.Stacks)
+	}
+
+	layer, err := buildpackLayer(buildpackDir, bp, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	diffId, err := layer.DiffID()
+	if err != nil {
+		return nil, err
+	}
+
+	image, err = mutate.AppendLayers(image, layer)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := buildpackageMetadata{
+		Id:       bp.Buildpack.Id,
+		Version:  bp.Buildpack.Version,
+		Homepage: bp.Buildpack.Homepage,
+		Stacks:   bp.stacks(),
+	}
+
+	if len(bp.Order) > 0 {
+		if err := validateOrder(bp, layers); err != nil {
+			return nil, err
+		}
+		metadata.Stacks = intersectStacks(dependencyStacks)
+	}
+
+	layers.add(bp.Buildpack.Id, bp.Buildpack.Version, buildpackLayerInfo{
+		API:         bp.API,
+		LayerDiffID: diffId.String(),
+		Order:       bp.order(),
+		Stacks:      bp.stacks(),
+		Homepage:    bp.Buildpack.Homepage,
+	})
+
+	return imagehelpers.SetLabels(image, map[string]interface{}{
+		buildpackageMetadataLabel: metadata,
+		buildpackLayersLabel:      layers,
+	})
+}
+
+func validateBuildpack(bp buildpackConfig) error {
+	if bp.Buildpack.Id == "" || bp.Buildpack.Version == "" {
+		return errors.Errorf("%s must provide a buildpack id and version", buildpackConfigFile)
+	}
+
+	if (len(bp.Stacks) > 0) == (len(bp.Order) > 0) {
+		return errors.Errorf("buildpack %s must provide either stacks or order", bp.Buildpack.Id)
+	}
+	return nil
+}
+
+func validateOrder(bp buildpackConfig, layers buildpackLayers) error {
+	for _, entry := range bp.order() {
+		for _, ref := range entry.Group {
+			if _, ok := layers[ref.Id][ref.Version]; !ok {
+				return errors.Errorf("buildpack %s in the order of %s is not provided by the package dependencies", ref.String(), bp.Buildpack.Id)
+			}
+		}
+	}
+	return nil
+}
+
+func (l buildpackLayers) add(id, version string, info buildpackLayerInfo) {
+	if _, ok := l[id]; !ok {
+		l[id] = map[string]buildpackLayerInfo{}
+	}
+	l[id][version] = info
+}
+
+// readDependency reads a package dependency, which may itself be a buildpack directory, a .cnb file or an image.
+func (u *Uploader) readDependency(baseDir string, dependency packageDependency, tempDir string, tlsCfg registry.TLSConfig) (v1.Image, error) {
+	location := dependency.Image
+	if location == "" {
+		location = strings.TrimPrefix(dependency.URI, "docker://")
+		if location == dependency.URI {
+			var err error
+			if location, err = localPath(baseDir, dependency.URI); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	dependencyDir, err := ioutil.TempDir(tempDir, "dependency")
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := u.read(location, dependencyDir, tlsCfg)
+	return image, errors.Wrapf(err, "invalid package dependency %s", location)
+}
+
+// addDependency adds the buildpack layers of a dependency to the image and records them in the layers label.
+func addDependency(image, dependency v1.Image, layers buildpackLayers) (v1.Image, error) {
+	depLayers := buildpackLayers{}
+	if err := imagehelpers.GetLabel(dependency, buildpackLayersLabel, &depLayers); err != nil {
+		return nil, err
+	}
+
+	for _, id := range depLayers.ids() {
+		for _, version := range depLayers.versions(id) {
+			if _, ok := layers[id][version]; ok {
+				continue
+			}
+
+			info := depLayers[id][version]
+			diffId, err := v1.NewHash(info.LayerDiffID)
+			if err != nil {
+				return nil, err
+			}
+
+			layer, err := dependency.LayerByDiffID(diffId)
+			if err != nil {
+				return nil, err
+			}
+
+			if image, err = mutate.AppendLayers(image, layer); err != nil {
+				return nil, err
+			}
+			layers.add(id, version, info)
+		}
+	}
+	return image, nil
+}
+
+// intersectStacks returns the stacks supported by all dependencies, with the mixins any of them require.
+func intersectStacks(dependencyStacks [][]v1alpha1.BuildpackStack) []v1alpha1.BuildpackStack {
+	if len(dependencyStacks) == 0 {
+		return nil
+	}
+
+	var stacks []v1alpha1.BuildpackStack
+	for _, stack := range dependencyStacks[0] {
+		mixins := map[string]bool{}
+		supported := true
+		for _, depStacks := range dependencyStacks {
+			found := false
+			for _, s := range depStacks {
+				if s.ID == stack.ID {
+					found = true
+					for _, m := range s.Mixins {
+						mixins[m] = true
+					}
+				}
+			}
+			supported = supported && found
+		}
+
+		if supported {
+			var stackMixins []string
+			for m := range mixins {
+				stackMixins = append(stackMixins, m)
+			}
+			sort.Strings(stackMixins)
+			stacks = append(stacks, v1alpha1.BuildpackStack{ID: stack.ID, Mixins: stackMixins})
+		}
+	}
+	return stacks
+}
+
+// buildpackLayer writes a buildpack directory to /cnb/buildpacks/<id>/<version> in a layer.
+// Executables and files in bin are made executable and entries are owned by root with a normalized time.
+func buildpackLayer(buildpackDir string, bp buildpackConfig, tempDir string) (v1.Layer, error) {
+	layerFile, err := ioutil.TempFile(tempDir, "buildpack-layer")
+	if err != nil {
+		return nil, err
+	}
+	defer layerFile.Close()
+
+	tw := tar.NewWriter(layerFile)
+
+	bpDir := path.Join(buildpacksDir, strings.ReplaceAll(bp.Buildpack.Id, "/", "_"), bp.Buildpack.Version)
+	for _, dir := range parentDirs(bpDir) {
+		if err := tw.WriteHeader(dirHeader(dir)); err != nil {
+			return nil, err
+		}
+	}
+
+	err = filepath.Walk(buildpackDir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(buildpackDir, file)
+		if err != nil {
+			return err
+		}
+		name := path.Join(bpDir, filepath.ToSlash(relPath))
+
+		switch {
+		case fi.IsDir():
+			return tw.WriteHeader(dirHeader(name))
+		case fi.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(file)
+			if err != nil {
+				return err
+			}
+			return tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: name, Linkname: target, Mode: 0777, ModTime: normalizedTime})
+		case !fi.Mode().IsRegular():
+			return nil
+		}
+
+		var mode int64 = 0644
+		if fi.Mode()&0111 != 0 || strings.HasPrefix(filepath.ToSlash(relPath), "bin/") {
+			mode = 0755
+		}
+
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: fi.Size(), Mode: mode, ModTime: normalizedTime}); err != nil {
+			return err
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return tarball.LayerFromFile(layerFile.Name())
+}
+
+func dirHeader(name string) *tar.Header {
+	return &tar.Header{Typeflag: tar.TypeDir, Name: name, Mode: 0755, ModTime: normalizedTime}
+}
+
+// parentDirs returns the directories above a path, starting at the root.
+func parentDirs(dir string) []string {
+	var dirs []string
+	for d := path.Dir(dir); d != "/"; d = path.Dir(d) {
+		dirs = append([]string{d}, dirs...)
+	}
+	return dirs
+}
+
+// localPath resolves a package.toml uri relative to the directory of the package.toml.
+func localPath(baseDir, uri string) (string, error) {
+	if strings.Contains(uri, "://") && !strings.HasPrefix(uri, "file://") {
+		return "", errors.Errorf("buildpack uri %s must be a local path", uri)
+	}
+
+	p := filepath.FromSlash(strings.TrimPrefix(uri, "file://"))
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(baseDir, p)
+	}
+	return p, nil
+}
+
+func (l buildpackLayers) ids() []string {
+	var ids []string
+	for id := range l {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (l buildpackLayers) versions(id string) []string {
+	var versions []string
+	for version := range l[id] {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
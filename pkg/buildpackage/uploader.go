@@ -62,6 +62,11 @@ func (u Uploader) UploadBuildpackage(writer io.Writer, repository, buildPackage
 }
 
 func (u *Uploader) read(buildPackage, tempDir string, tlsCfg registry.TLSConfig) (v1.Image, error) {
+	if isBuildpackSource(buildPackage) {
+		bp, err := u.packageBuildpack(buildPackage, tempDir, tlsCfg)
+		return bp, errors.Wrapf(err, "invalid local buildpack %s", buildPackage)
+	}
+
 	if isLocalCnb(buildPackage) {
 		cnb, err := readCNB(buildPackage, tempDir)
 		return cnb, errors.Wrapf(err, "invalid local buildpackage %s", buildPackage)
@@ -4,10 +4,15 @@
 package buildpackage
 
 import (
+	"archive/tar"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/pivotal/kpack/pkg/registry/imagehelpers"
 	"github.com/sclevine/spec"
@@ -61,4 +66,150 @@ func testBuildpackageUploader(t *testing.T, when spec.G, it spec.S) {
 			assert.Equal(t, expectedImage, image)
 		})
 	})
+
+	when("a buildpack directory is provided", func() {
+		it("packages the buildpack and uploads it to the registry", func() {
+			image, err := uploader.UploadBuildpackage(ioutil.Discard, "kpackcr.org/somepath", "testdata/sample-bp", registry.TLSConfig{})
+			require.NoError(t, err)
+			assert.Regexp(t, "^kpackcr.org/somepath/sample_buildpackage@sha256:[0-9a-f]{64}$", image)
+
+			packageTomlImage, err := uploader.UploadBuildpackage(ioutil.Discard, "kpackcr.org/somepath", "testdata/sample-bp/package.toml", registry.TLSConfig{})
+			require.NoError(t, err)
+			assert.Equal(t, image, packageTomlImage)
+		})
+
+		it("lays out the buildpack and generates the buildpackage labels", func() {
+			tempDir, err := ioutil.TempDir("", "buildpackage-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(tempDir)
+
+			image, err := uploader.read("testdata/sample-bp", tempDir, registry.TLSConfig{})
+			require.NoError(t, err)
+
+			metadata, err := imagehelpers.GetStringLabel(image, "io.buildpacks.buildpackage.metadata")
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"id":"sample/buildpackage","version":"0.0.1","homepage":"sample.com","stacks":[{"id":"io.buildpacks.stacks.bionic"},{"id":"org.cloudfoundry.stacks.tiny"}]}`, metadata)
+
+			layers, err := image.Layers()
+			require.NoError(t, err)
+			require.Len(t, layers, 1)
+
+			diffId, err := layers[0].DiffID()
+			require.NoError(t, err)
+
+			layersLabel, err := imagehelpers.GetStringLabel(image, "io.buildpacks.buildpack.layers")
+			require.NoError(t, err)
+			assert.JSONEq(t, fmt.Sprintf(`{"sample/buildpackage":{"0.0.1":{"api":"0.2","layerDiffID":"%s","stacks":[{"id":"io.buildpacks.stacks.bionic"},{"id":"org.cloudfoundry.stacks.tiny"}],"homepage":"sample.com"}}}`, diffId), layersLabel)
+
+			assert.Equal(t, map[string]int64{
+				"/cnb":                                0755,
+				"/cnb/buildpacks":                     0755,
+				"/cnb/buildpacks/sample_buildpackage": 0755,
+				"/cnb/buildpacks/sample_buildpackage/0.0.1":                0755,
+				"/cnb/buildpacks/sample_buildpackage/0.0.1/bin":            0755,
+				"/cnb/buildpacks/sample_buildpackage/0.0.1/bin/build":      0755,
+				"/cnb/buildpacks/sample_buildpackage/0.0.1/bin/detect":     0755,
+				"/cnb/buildpacks/sample_buildpackage/0.0.1/buildpack.toml": 0644,
+				"/cnb/buildpacks/sample_buildpackage/0.0.1/package.toml":   0644,
+			}, layerModes(t, layers[0]))
+		})
+
+		it("packages meta-buildpacks with their dependencies", func() {
+			metaDir, err := ioutil.TempDir("", "meta-bp")
+			require.NoError(t, err)
+			defer os.RemoveAll(metaDir)
+
+			sampleBp, err := filepath.Abs("testdata/sample-bp")
+			require.NoError(t, err)
+
+			require.NoError(t, ioutil.WriteFile(filepath.Join(metaDir, "buildpack.toml"), []byte(`api = "0.2"
+
+[buildpack]
+id = "sample/meta"
+version = "0.0.2"
+
+[[order]]
+[[order.group]]
+id = "sample/buildpackage"
+version = "0.0.1"
+`), 0644))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(metaDir, "package.toml"), []byte(fmt.Sprintf(`[buildpack]
+uri = "."
+
+[[dependencies]]
+uri = %q
+`, sampleBp)), 0644))
+
+			tempDir, err := ioutil.TempDir("", "buildpackage-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(tempDir)
+
+			image, err := uploader.read(metaDir, tempDir, registry.TLSConfig{})
+			require.NoError(t, err)
+
+			metadata, err := imagehelpers.GetStringLabel(image, "io.buildpacks.buildpackage.metadata")
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"id":"sample/meta","version":"0.0.2","stacks":[{"id":"io.buildpacks.stacks.bionic"},{"id":"org.cloudfoundry.stacks.tiny"}]}`, metadata)
+
+			layers, err := image.Layers()
+			require.NoError(t, err)
+			require.Len(t, layers, 2)
+
+			var layersLabel map[string]map[string]struct {
+				LayerDiffID string `json:"layerDiffID"`
+			}
+			require.NoError(t, imagehelpers.GetLabel(image, "io.buildpacks.buildpack.layers", &layersLabel))
+
+			for i, bp := range []struct{ id, version string }{{"sample/buildpackage", "0.0.1"}, {"sample/meta", "0.0.2"}} {
+				diffId, err := layers[i].DiffID()
+				require.NoError(t, err)
+				assert.Equal(t, diffId.String(), layersLabel[bp.id][bp.version].LayerDiffID)
+			}
+		})
+
+		it("errors when the order references buildpacks that are not dependencies", func() {
+			metaDir, err := ioutil.TempDir("", "meta-bp")
+			require.NoError(t, err)
+			defer os.RemoveAll(metaDir)
+
+			require.NoError(t, ioutil.WriteFile(filepath.Join(metaDir, "buildpack.toml"), []byte(`api = "0.2"
+
+[buildpack]
+id = "sample/meta"
+version = "0.0.2"
+
+[[order]]
+[[order.group]]
+id = "sample/buildpackage"
+version = "0.0.1"
+`), 0644))
+
+			_, err = uploader.UploadBuildpackage(ioutil.Discard, "kpackcr.org/somepath", metaDir, registry.TLSConfig{})
+			require.EqualError(t, err, fmt.Sprintf("invalid local buildpack %s: buildpack sample/buildpackage@0.0.1 in the order of sample/meta is not provided by the package dependencies", metaDir))
+		})
+
+		it("errors when the directory is not a buildpack", func() {
+			_, err := uploader.UploadBuildpackage(ioutil.Discard, "kpackcr.org/somepath", "testdata", registry.TLSConfig{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid local buildpack testdata: reading buildpack.toml")
+		})
+	})
+}
+
+func layerModes(t *testing.T, layer v1.Layer) map[string]int64 {
+	reader, err := layer.Uncompressed()
+	require.NoError(t, err)
+	defer reader.Close()
+
+	modes := map[string]int64{}
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		modes[header.Name] = header.Mode
+	}
+	return modes
 }
@@ -0,0 +1,90 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+type buildLogMetadata struct {
+	Name            string  `json:"name"`
+	Namespace       string  `json:"namespace"`
+	Image           string  `json:"image"`
+	BuildNumber     string  `json:"buildNumber"`
+	Status          string  `json:"status"`
+	BuildReason     string  `json:"buildReason"`
+	StatusReason    string  `json:"statusReason,omitempty"`
+	StatusMessage   string  `json:"statusMessage,omitempty"`
+	Started         string  `json:"started"`
+	Finished        string  `json:"finished,omitempty"`
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+	PodName         string  `json:"podName,omitempty"`
+	LatestImage     string  `json:"latestImage,omitempty"`
+}
+
+// saveBuildLogs writes the logs of a build to <dir>/<image>/<build-number>.log
+// with the build metadata in a <build-number>.json sidecar and returns the path of the log file.
+func saveBuildLogs(ctx context.Context, logTailer LogTailer, bld v1alpha1.Build, opts build.LogOptions, dir string) (string, error) {
+	logs := &bytes.Buffer{}
+	if err := logTailer.Tail(ctx, logs, &bld, opts); err != nil {
+		return "", err
+	}
+
+	imageDir := filepath.Join(dir, bld.Labels[v1alpha1.ImageLabel])
+	if err := os.MkdirAll(imageDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	buildNumber := bld.Labels[v1alpha1.BuildNumberLabel]
+	logPath := filepath.Join(imageDir, buildNumber+".log")
+	if err := ioutil.WriteFile(logPath, logs.Bytes(), 0644); err != nil {
+		return "", err
+	}
+
+	metadata, err := json.MarshalIndent(makeBuildLogMetadata(bld), "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return logPath, ioutil.WriteFile(filepath.Join(imageDir, buildNumber+".json"), append(metadata, '\n'), 0644)
+}
+
+func makeBuildLogMetadata(bld v1alpha1.Build) buildLogMetadata {
+	cond := bld.Status.GetCondition(corev1alpha1.ConditionSucceeded)
+
+	metadata := buildLogMetadata{
+		Name:        bld.Name,
+		Namespace:   bld.Namespace,
+		Image:       bld.Labels[v1alpha1.ImageLabel],
+		BuildNumber: bld.Labels[v1alpha1.BuildNumberLabel],
+		Status:      getStatus(bld),
+		BuildReason: bld.Annotations[v1alpha1.BuildReasonAnnotation],
+		Started:     bld.CreationTimestamp.UTC().Format(time.RFC3339),
+		PodName:     bld.Status.PodName,
+		LatestImage: bld.Status.LatestImage,
+	}
+
+	if cond != nil {
+		metadata.StatusReason = cond.Reason
+		metadata.StatusMessage = cond.Message
+	}
+
+	if finished := getCompletedAt(bld); !finished.IsZero() {
+		metadata.Finished = finished.UTC().Format(time.RFC3339)
+		metadata.DurationSeconds = finished.Sub(bld.CreationTimestamp.Time).Seconds()
+	}
+
+	return metadata
+}
@@ -0,0 +1,175 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+type ImageFetcher interface {
+	Fetch(src string, tlsCfg registry.TLSConfig) (v1.Image, error)
+}
+
+func NewBomCommand(clientSetProvider k8s.ClientSetProvider, fetcher ImageFetcher) *cobra.Command {
+	var (
+		namespace   string
+		buildNumber string
+		output      string
+		tlsCfg      registry.TLSConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bom <image-name>",
+		Short: "Display the bill of materials of an image build",
+		Long: `Prints the bill of materials of the image built by a specific build of an image in the provided namespace.
+
+The bill of materials, launch processes and run image are read from the labels of the built image in the registry.
+The output can be displayed as a table, as json, or as a CycloneDX or SPDX document.
+
+The build defaults to the latest build number.
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build bom my-image\nkp build bom my-image -b 2 -n my-namespace\nkp build bom my-image --output cyclonedx",
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "json" && output != "cyclonedx" && output != "spdx" {
+				return errors.Errorf("invalid output format %q, must be one of table, json, cyclonedx, or spdx", output)
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
+				LabelSelector: v1alpha1.ImageLabel + "=" + args[0],
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(buildList.Items) == 0 {
+				return errors.New("no builds found")
+			}
+
+			sort.Slice(buildList.Items, build.Sort(buildList.Items))
+			bld, err := findBuild(buildList, buildNumber, args[0], cs.Namespace)
+			if err != nil {
+				return err
+			}
+
+			if bld.Status.LatestImage == "" {
+				return errors.Errorf("%s did not produce an image", describeBuild(bld))
+			}
+
+			img, err := fetcher.Fetch(bld.Status.LatestImage, tlsCfg)
+			if err != nil {
+				return err
+			}
+
+			md, err := build.ReadImageMetadata(img)
+			if err != nil {
+				return err
+			}
+
+			switch output {
+			case "json":
+				return writeJSON(cmd.OutOrStdout(), struct {
+					Image string `json:"image"`
+					build.ImageMetadata
+				}{bld.Status.LatestImage, md})
+			case "cyclonedx":
+				return writeJSON(cmd.OutOrStdout(), build.NewCycloneDXDocument(bld.Status.LatestImage, getCompletedAt(bld), md))
+			case "spdx":
+				return writeJSON(cmd.OutOrStdout(), build.NewSPDXDocument(bld.Status.LatestImage, getCompletedAt(bld), md))
+			default:
+				return displayBom(cmd, bld.Status.LatestImage, md)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().StringVar(&output, "output", "table", "output format. supported formats are: table, json, cyclonedx, spdx")
+	commands.SetTLSFlags(cmd, &tlsCfg)
+
+	return cmd
+}
+
+func getCompletedAt(bld v1alpha1.Build) time.Time {
+	if bld.IsRunning() {
+		return time.Time{}
+	}
+	return bld.Status.GetCondition(corev1alpha1.ConditionSucceeded).LastTransitionTime.Inner.Time
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func displayBom(cmd *cobra.Command, image string, md build.ImageMetadata) error {
+	statusWriter := commands.NewStatusWriter(cmd.OutOrStdout())
+
+	err := statusWriter.AddBlock(
+		"",
+		"Image", image,
+		"Stack", md.StackId,
+		"Run Image", md.RunImage.Image,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = statusWriter.Write()
+	if err != nil {
+		return err
+	}
+
+	processWriter, err := commands.NewTableWriter(cmd.OutOrStdout(), "Process Type", "Command", "Buildpack")
+	if err != nil {
+		return err
+	}
+
+	for _, p := range md.Processes {
+		err := processWriter.AddRow(p.Type, p.CommandLine(), p.BuildpackId)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = processWriter.Write()
+	if err != nil {
+		return err
+	}
+
+	bomWriter, err := commands.NewTableWriter(cmd.OutOrStdout(), "Buildpack Id", "Buildpack Version", "Name", "Version")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range md.BOM {
+		err := bomWriter.AddRow(entry.Buildpack.Id, entry.Buildpack.Version, entry.Name, entry.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return bomWriter.Write()
+}
@@ -0,0 +1,226 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/pivotal/kpack/pkg/registry/imagehelpers"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	buildpkg "github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/image/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildBomCommand(t *testing.T) {
+	spec.Run(t, "TestBuildBomCommand", testBuildBomCommand)
+}
+
+func testBuildBomCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+	)
+
+	var fetcher *fakes.Fetcher
+
+	it.Before(func() {
+		builtImage, err := random.Image(0, 0)
+		require.NoError(t, err)
+
+		builtImage, err = imagehelpers.SetStringLabels(builtImage, map[string]string{
+			buildpkg.StackIdLabel: "some-stack-id",
+			buildpkg.BuildMetadataLabel: `{
+	  "bom": [{"name": "openjdk", "version": "11.0.8", "metadata": {"uri": "https://example.com/jdk.tgz"}, "buildpack": {"id": "bp-id-1", "version": "bp-version-1"}}],
+	  "buildpacks": [{"id": "bp-id-1", "version": "bp-version-1"}],
+	  "processes": [{"type": "web", "command": "java", "args": ["-jar", "app.jar"], "direct": false, "buildpackID": "bp-id-1"}]
+	}`,
+			buildpkg.LifecycleMetadataLabel: `{
+	  "runImage": {"topLayer": "sha256:top", "reference": "sha256:abc"},
+	  "stack": {"runImage": {"image": "some-repo.com/run-image:latest"}}
+	}`,
+		})
+		require.NoError(t, err)
+
+		unlabeledImage, err := random.Image(0, 0)
+		require.NoError(t, err)
+
+		fetcher = &fakes.Fetcher{}
+		fetcher.AddImage("repo.com/image-1:tag", builtImage)
+		fetcher.AddImage("repo.com/image-2:tag", unlabeledImage)
+	})
+
+	cmdFunc := func(clientSet *kpackfakes.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return build.NewBomCommand(clientSetProvider, fetcher)
+	}
+
+	it("displays the bill of materials as a table", func() {
+		testhelpers.CommandTest{
+			Objects: testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:    []string{image, "-b", "1"},
+			ExpectedOutput: `Image:        repo.com/image-1:tag
+Stack:        some-stack-id
+Run Image:    some-repo.com/run-image@sha256:abc
+
+PROCESS TYPE    COMMAND              BUILDPACK
+web             java -jar app.jar    bp-id-1
+
+BUILDPACK ID    BUILDPACK VERSION    NAME       VERSION
+bp-id-1         bp-version-1         openjdk    11.0.8
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("displays the bill of materials as json", func() {
+		testhelpers.CommandTest{
+			Objects: testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:    []string{image, "-b", "1", "--output", "json"},
+			ExpectedOutput: `{
+  "image": "repo.com/image-1:tag",
+  "stackId": "some-stack-id",
+  "runImage": {
+    "image": "some-repo.com/run-image@sha256:abc",
+    "topLayer": "sha256:top"
+  },
+  "buildpacks": [
+    {
+      "id": "bp-id-1",
+      "version": "bp-version-1"
+    }
+  ],
+  "processes": [
+    {
+      "type": "web",
+      "command": "java",
+      "args": [
+        "-jar",
+        "app.jar"
+      ],
+      "direct": false,
+      "buildpackID": "bp-id-1"
+    }
+  ],
+  "bom": [
+    {
+      "name": "openjdk",
+      "version": "11.0.8",
+      "metadata": {
+        "uri": "https://example.com/jdk.tgz"
+      },
+      "buildpack": {
+        "id": "bp-id-1",
+        "version": "bp-version-1"
+      }
+    }
+  ]
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("displays the bill of materials as a CycloneDX document", func() {
+		testhelpers.CommandTest{
+			Objects: testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:    []string{image, "-b", "1", "--output", "cyclonedx"},
+			ExpectedOutput: `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.3",
+  "version": 1,
+  "metadata": {
+    "tools": [
+      {
+        "name": "kp"
+      }
+    ],
+    "component": {
+      "type": "container",
+      "name": "repo.com/image-1:tag",
+      "properties": [
+        {
+          "name": "stack",
+          "value": "some-stack-id"
+        },
+        {
+          "name": "runImage",
+          "value": "some-repo.com/run-image@sha256:abc"
+        }
+      ]
+    }
+  },
+  "components": [
+    {
+      "type": "library",
+      "name": "openjdk",
+      "version": "11.0.8",
+      "properties": [
+        {
+          "name": "buildpack",
+          "value": "bp-id-1@bp-version-1"
+        }
+      ]
+    }
+  ]
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("displays the bill of materials as an SPDX document", func() {
+		testhelpers.CommandTest{
+			Objects: testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:    []string{image, "-b", "1", "--output", "spdx"},
+			ExpectedOutput: `{
+  "spdxVersion": "SPDX-2.2",
+  "dataLicense": "CC0-1.0",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "repo.com/image-1:tag",
+  "documentNamespace": "https://kpack.io/spdx/repo.com/image-1:tag",
+  "creationInfo": {
+    "creators": [
+      "Tool: kp"
+    ]
+  },
+  "packages": [
+    {
+      "SPDXID": "SPDXRef-Package-1",
+      "name": "openjdk",
+      "versionInfo": "11.0.8",
+      "downloadLocation": "NOASSERTION",
+      "licenseConcluded": "NOASSERTION",
+      "licenseDeclared": "NOASSERTION",
+      "copyrightText": "NOASSERTION",
+      "comment": "provided by buildpack bp-id-1@bp-version-1"
+    }
+  ]
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when the image was not built by buildpacks", func() {
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image, "-b", "2"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: image was not built by buildpacks: could not find label io.buildpacks.build.metadata\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors with an invalid output format", func() {
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image, "--output", "yaml"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: invalid output format \"yaml\", must be one of table, json, cyclonedx, or spdx\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
@@ -0,0 +1,153 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+func NewCancelCommand(clientSetProvider k8s.ClientSetProvider, confirmationProvider ConfirmationProvider) *cobra.Command {
+	var (
+		namespace   string
+		buildNumber string
+		selector    string
+		force       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cancel [<image-name>]",
+		Short: "Cancel a running image build",
+		Long: `Cancel a running build of an image in the provided namespace.
+
+The build is marked as failed with the reason "BuildCancelled" and its build pod is then deleted to stop it.
+kpack does not recreate the pod of a failed build.
+When a selector is provided, all running builds matching the selector are cancelled.
+
+The build defaults to the latest build number.
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build cancel my-image\nkp build cancel my-image -b 2 -n my-namespace\nkp build cancel -l team=my-team",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateBuildSelection(args, buildNumber, selector); err != nil {
+				return err
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			imageBuilds, err := listImageBuilds(cs, args, selector)
+			if err != nil {
+				return err
+			}
+
+			var builds []v1alpha1.Build
+			if len(args) > 0 {
+				bld, err := findBuild(&v1alpha1.BuildList{Items: imageBuilds[args[0]]}, buildNumber, args[0], cs.Namespace)
+				if err != nil {
+					return err
+				}
+
+				if !bld.IsRunning() {
+					return errors.Errorf("%s is not running", describeBuild(bld))
+				}
+				builds = append(builds, bld)
+			} else {
+				for _, img := range sortedImageNames(imageBuilds) {
+					for _, bld := range imageBuilds[img] {
+						if bld.IsRunning() {
+							builds = append(builds, bld)
+						}
+					}
+				}
+			}
+
+			if len(builds) == 0 {
+				return ch.Printlnf("No running builds found")
+			}
+
+			confirmed, err := confirmBuilds(ch, confirmationProvider, force, "cancellation", len(builds))
+			if err != nil {
+				return err
+			}
+
+			if !confirmed {
+				return ch.Printlnf("Skipping build cancellation")
+			}
+
+			for _, bld := range builds {
+				if !ch.IsDryRun() {
+					if err := cancelBuild(cs, bld); err != nil {
+						return err
+					}
+				}
+
+				if err := ch.PrintResult("Cancelled %s", describeBuild(bld)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "label selector of the builds to cancel")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "cancel without confirmation")
+	cmd.Flags().Bool(commands.DryRunFlag, false, "only print the builds that would be cancelled, without cancelling them")
+
+	return cmd
+}
+
+// cancelledReason is the reason of the failed condition of cancelled builds.
+const cancelledReason = "BuildCancelled"
+
+// cancelBuild marks a running build as failed and then deletes its pod.
+// The build must be marked first, as kpack recreates the pod of an unfinished build.
+func cancelBuild(cs k8s.ClientSet, bld v1alpha1.Build) error {
+	if bld.Status.PodName == "" {
+		return errors.Errorf("%s has no build pod yet, try again once it has started", describeBuild(bld))
+	}
+
+	cancelled := bld.DeepCopy()
+	conditions := corev1alpha1.Conditions{{
+		Type:               corev1alpha1.ConditionSucceeded,
+		Status:             corev1.ConditionFalse,
+		Reason:             cancelledReason,
+		Message:            "The build was cancelled",
+		LastTransitionTime: corev1alpha1.VolatileTime{Inner: metav1.Now()},
+	}}
+	for _, c := range cancelled.Status.Conditions {
+		if c.Type != corev1alpha1.ConditionSucceeded {
+			conditions = append(conditions, c)
+		}
+	}
+	cancelled.Status.Conditions = conditions
+
+	_, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).UpdateStatus(cancelled)
+	if err != nil {
+		return err
+	}
+
+	err = cs.K8sClient.CoreV1().Pods(cs.Namespace).Delete(bld.Status.PodName, &metav1.DeleteOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
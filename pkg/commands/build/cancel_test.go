@@ -0,0 +1,171 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfakes "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildCancelCommand(t *testing.T) {
+	spec.Run(t, "TestBuildCancelCommand", testBuildCancelCommand)
+}
+
+func testBuildCancelCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+	)
+
+	var (
+		confirmationProvider *FakeConfirmationProvider
+		k8sClient            *k8sfakes.Clientset
+		kpackClient          *kpackfakes.Clientset
+		out                  *bytes.Buffer
+	)
+
+	buildPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-three",
+			Namespace: defaultNamespace,
+		},
+	}
+
+	it.Before(func() {
+		confirmationProvider = &FakeConfirmationProvider{confirm: true}
+		k8sClient = k8sfakes.NewSimpleClientset(buildPod)
+		kpackClient = kpackfakes.NewSimpleClientset(testhelpers.MakeTestBuilds(image, defaultNamespace)...)
+		out = &bytes.Buffer{}
+	})
+
+	execute := func(args ...string) error {
+		clientSetProvider := testhelpers.GetFakeK8sAndKpackProvider(k8sClient, kpackClient, defaultNamespace)
+		cmd := build.NewCancelCommand(clientSetProvider, confirmationProvider)
+		cmd.SetArgs(args)
+		cmd.SetOut(out)
+		cmd.SetErr(out)
+		return cmd.Execute()
+	}
+
+	cancelledBuild := func(name string) *v1alpha1.Build {
+		bld, err := kpackClient.KpackV1alpha1().Builds(defaultNamespace).Get(name, metav1.GetOptions{})
+		require.NoError(t, err)
+		return bld
+	}
+
+	it("marks the latest build as cancelled before deleting its pod", func() {
+		require.NoError(t, execute(image))
+		require.Equal(t, "Cancelled build \"3\" of image \"test-image\"\n", out.String())
+		require.True(t, confirmationProvider.requested)
+
+		kpackActions, err := testhelpers.ActionRecorderList{kpackClient}.ActionsByVerb()
+		require.NoError(t, err)
+		require.Len(t, kpackActions.Updates, 1)
+		require.Equal(t, "status", kpackActions.Updates[0].GetSubresource())
+
+		bld := cancelledBuild("build-three")
+		require.False(t, bld.IsRunning())
+		require.True(t, bld.Finished())
+		condition := bld.Status.GetCondition(corev1alpha1.ConditionSucceeded)
+		require.Equal(t, corev1.ConditionFalse, condition.Status)
+		require.Equal(t, "BuildCancelled", condition.Reason)
+		require.Equal(t, "pod-three", bld.Status.PodName)
+
+		k8sActions, err := testhelpers.ActionRecorderList{k8sClient}.ActionsByVerb()
+		require.NoError(t, err)
+		require.Len(t, k8sActions.Deletes, 1)
+		require.Equal(t, "pod-three", k8sActions.Deletes[0].GetName())
+	})
+
+	it("keeps the build cancelled when its pod was already deleted", func() {
+		k8sClient = k8sfakes.NewSimpleClientset()
+
+		require.NoError(t, execute(image, "--force"))
+		require.True(t, cancelledBuild("build-three").Finished())
+	})
+
+	it("cancels running builds matching a selector", func() {
+		require.NoError(t, execute("-l", v1alpha1.ImageLabel+"="+image, "--force"))
+		require.Equal(t, "Cancelled build \"3\" of image \"test-image\"\n", out.String())
+		require.False(t, confirmationProvider.requested)
+		require.True(t, cancelledBuild("build-three").Finished())
+	})
+
+	it("errors when the build pod has not been created yet", func() {
+		builds := testhelpers.MakeTestBuilds(image, defaultNamespace)
+		builds[1].(*v1alpha1.Build).Status.PodName = ""
+		kpackClient = kpackfakes.NewSimpleClientset(builds...)
+
+		require.EqualError(t, execute(image, "--force"), "build \"3\" of image \"test-image\" has no build pod yet, try again once it has started")
+		require.True(t, cancelledBuild("build-three").IsRunning())
+	})
+
+	it("errors when the build is not running", func() {
+		require.EqualError(t, execute(image, "-b", "1"), "build \"1\" of image \"test-image\" is not running")
+	})
+
+	it("does not cancel when confirmation is declined", func() {
+		confirmationProvider.confirm = false
+
+		require.NoError(t, execute(image))
+		require.Equal(t, "Skipping build cancellation\n", out.String())
+
+		kpackActions, err := testhelpers.ActionRecorderList{kpackClient}.ActionsByVerb()
+		require.NoError(t, err)
+		require.Len(t, kpackActions.Updates, 0)
+	})
+
+	it("does not cancel with dry run", func() {
+		require.NoError(t, execute(image, "--dry-run"))
+		require.Equal(t, "Cancelled build \"3\" of image \"test-image\" (dry run)\n", out.String())
+		require.False(t, confirmationProvider.requested)
+
+		kpackActions, err := testhelpers.ActionRecorderList{kpackClient}.ActionsByVerb()
+		require.NoError(t, err)
+		require.Len(t, kpackActions.Updates, 0)
+		require.True(t, cancelledBuild("build-three").IsRunning())
+
+		k8sActions, err := testhelpers.ActionRecorderList{k8sClient}.ActionsByVerb()
+		require.NoError(t, err)
+		require.Len(t, k8sActions.Deletes, 0)
+	})
+
+	it("errors when an image name and selector are both provided", func() {
+		testhelpers.CommandTest{
+			KpackObjects:   []runtime.Object{},
+			Args:           []string{image, "-l", "some=label"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: an image name and selector cannot be used together\n",
+		}.TestK8sAndKpack(t, func(k8sClientSet *k8sfakes.Clientset, kpackClientSet *kpackfakes.Clientset) *cobra.Command {
+			return build.NewCancelCommand(testhelpers.GetFakeK8sAndKpackProvider(k8sClientSet, kpackClientSet, defaultNamespace), confirmationProvider)
+		})
+	})
+}
+
+type FakeConfirmationProvider struct {
+	// return values for confirm request
+	confirm bool
+	err     error
+	// tracks if confirmation was requested
+	requested bool
+}
+
+func (f *FakeConfirmationProvider) Confirm(_ string, _ ...string) (bool, error) {
+	f.requested = true
+	return f.confirm, f.err
+}
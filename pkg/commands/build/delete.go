@@ -0,0 +1,107 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+func NewDeleteCommand(clientSetProvider k8s.ClientSetProvider, confirmationProvider ConfirmationProvider) *cobra.Command {
+	var (
+		namespace   string
+		buildNumber string
+		selector    string
+		force       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "delete [<image-name>]",
+		Short: "Delete an image build",
+		Long: `Delete a build of an image in the provided namespace.
+
+When a selector is provided, all builds matching the selector are deleted.
+
+The build defaults to the latest build number.
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build delete my-image -b 2\nkp build delete my-image -b 2 -n my-namespace\nkp build delete -l team=my-team",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateBuildSelection(args, buildNumber, selector); err != nil {
+				return err
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			imageBuilds, err := listImageBuilds(cs, args, selector)
+			if err != nil {
+				return err
+			}
+
+			var builds []v1alpha1.Build
+			if len(args) > 0 {
+				bld, err := findBuild(&v1alpha1.BuildList{Items: imageBuilds[args[0]]}, buildNumber, args[0], cs.Namespace)
+				if err != nil {
+					return err
+				}
+				builds = append(builds, bld)
+			} else {
+				for _, img := range sortedImageNames(imageBuilds) {
+					builds = append(builds, imageBuilds[img]...)
+				}
+			}
+
+			if len(builds) == 0 {
+				return ch.Printlnf("No builds found")
+			}
+
+			return deleteBuilds(cs, ch, confirmationProvider, force, builds)
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "label selector of the builds to delete")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "delete without confirmation")
+	cmd.Flags().Bool(commands.DryRunFlag, false, "only print the builds that would be deleted, without deleting them")
+
+	return cmd
+}
+
+func deleteBuilds(cs k8s.ClientSet, ch *commands.CommandHelper, confirmationProvider ConfirmationProvider, force bool, builds []v1alpha1.Build) error {
+	confirmed, err := confirmBuilds(ch, confirmationProvider, force, "deletion", len(builds))
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		return ch.Printlnf("Skipping build deletion")
+	}
+
+	for _, bld := range builds {
+		if !ch.IsDryRun() {
+			err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).Delete(bld.Name, &metav1.DeleteOptions{})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := ch.PrintResult("Deleted %s", describeBuild(bld)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	clientgotesting "k8s.io/client-go/testing"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildDeleteCommand(t *testing.T) {
+	spec.Run(t, "TestBuildDeleteCommand", testBuildDeleteCommand)
+}
+
+func testBuildDeleteCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+	)
+
+	var confirmationProvider *FakeConfirmationProvider
+
+	it.Before(func() {
+		confirmationProvider = &FakeConfirmationProvider{confirm: true}
+	})
+
+	cmdFunc := func(clientSet *kpackfakes.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return build.NewDeleteCommand(clientSetProvider, confirmationProvider)
+	}
+
+	it("deletes the build", func() {
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image, "-b", "2"},
+			ExpectedOutput: "Deleted build \"2\" of image \"test-image\"\n",
+			ExpectDeletes: []clientgotesting.DeleteActionImpl{
+				{
+					ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace},
+					Name:       "build-two",
+				},
+			},
+		}.TestKpack(t, cmdFunc)
+		require.True(t, confirmationProvider.requested)
+	})
+
+	it("deletes all builds matching a selector", func() {
+		testhelpers.CommandTest{
+			Objects: testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:    []string{"-l", v1alpha1.ImageLabel + "=" + image, "-f"},
+			ExpectedOutput: `Deleted build "1" of image "test-image"
+Deleted build "2" of image "test-image"
+Deleted build "3" of image "test-image"
+`,
+			ExpectDeletes: []clientgotesting.DeleteActionImpl{
+				{ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace}, Name: "build-one"},
+				{ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace}, Name: "build-two"},
+				{ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace}, Name: "build-three"},
+			},
+		}.TestKpack(t, cmdFunc)
+		require.False(t, confirmationProvider.requested)
+	})
+
+	it("does not delete with dry run", func() {
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image, "-b", "2", "--dry-run"},
+			ExpectedOutput: "Deleted build \"2\" of image \"test-image\" (dry run)\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("does not delete when confirmation is declined", func() {
+		confirmationProvider.confirm = false
+
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image, "-b", "2"},
+			ExpectedOutput: "Skipping build deletion\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when neither an image name nor a selector is provided", func() {
+		testhelpers.CommandTest{
+			Args:           []string{},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: an image name or selector is required\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
@@ -0,0 +1,148 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+func NewDiffCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	var (
+		namespace    string
+		buildNumbers []string
+		output       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <image-name>",
+		Short: "Display the differences between two image builds",
+		Long: `Prints the differences between two builds of an image in the provided namespace.
+
+The buildpacks, run image, builder, source, environment variables, build reason and built image of the builds are compared.
+
+The builds default to the two latest build numbers.
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build diff my-image\nkp build diff my-image -b 14 -b 15 -n my-namespace",
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(buildNumbers) != 0 && len(buildNumbers) != 2 {
+				return errors.New("exactly two build numbers must be provided")
+			}
+
+			if output != "table" && output != "json" {
+				return errors.Errorf("invalid output format %q, must be one of table or json", output)
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
+				LabelSelector: v1alpha1.ImageLabel + "=" + args[0],
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(buildList.Items) < 2 {
+				return errors.New("at least two builds are required to compare")
+			}
+
+			sort.Slice(buildList.Items, build.Sort(buildList.Items))
+
+			from, to := buildList.Items[len(buildList.Items)-2], buildList.Items[len(buildList.Items)-1]
+			if len(buildNumbers) == 2 {
+				from, err = findBuild(buildList, buildNumbers[0], args[0], cs.Namespace)
+				if err != nil {
+					return err
+				}
+
+				to, err = findBuild(buildList, buildNumbers[1], args[0], cs.Namespace)
+				if err != nil {
+					return err
+				}
+			}
+
+			diff := build.Diff(from, to)
+
+			if output == "json" {
+				return writeJSON(cmd.OutOrStdout(), diff)
+			}
+			return displayDiff(cmd, diff)
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringArrayVarP(&buildNumbers, "build", "b", []string{}, "build number to compare (can be set twice)")
+	cmd.Flags().StringVar(&output, "output", "table", "output format. supported formats are: table, json")
+
+	return cmd
+}
+
+func displayDiff(cmd *cobra.Command, diff build.BuildDiff) error {
+	if !diff.HasChanges() {
+		_, err := fmt.Fprintf(cmd.OutOrStdout(), "No differences between build %s and build %s\n", diff.From, diff.To)
+		return err
+	}
+
+	fromHeader, toHeader := "Build "+diff.From, "Build "+diff.To
+
+	if len(diff.Changes) > 0 {
+		tableWriter, err := commands.NewTableWriter(cmd.OutOrStdout(), "Field", fromHeader, toHeader)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range diff.Changes {
+			err := tableWriter.AddRow(c.Field, valueOrDashes(c.From), valueOrDashes(c.To))
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tableWriter.Write()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(diff.Buildpacks) > 0 {
+		tableWriter, err := commands.NewTableWriter(cmd.OutOrStdout(), "Buildpack Id", fromHeader, toHeader, "Change")
+		if err != nil {
+			return err
+		}
+
+		for _, c := range diff.Buildpacks {
+			err := tableWriter.AddRow(c.Id, valueOrDashes(c.From), valueOrDashes(c.To), c.Change)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tableWriter.Write()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func valueOrDashes(s string) string {
+	if s == "" {
+		return "--"
+	}
+	return s
+}
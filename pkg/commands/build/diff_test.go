@@ -0,0 +1,145 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildDiffCommand(t *testing.T) {
+	spec.Run(t, "TestBuildDiffCommand", testBuildDiffCommand)
+}
+
+func testBuildDiffCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+	)
+
+	makeBuild := func(number, revision, runImage, latestImage string, env []corev1.EnvVar, buildpacks v1alpha1.BuildpackMetadataList) *v1alpha1.Build {
+		return &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "build-" + number,
+				Namespace: defaultNamespace,
+				Labels: map[string]string{
+					v1alpha1.ImageLabel:       image,
+					v1alpha1.BuildNumberLabel: number,
+				},
+				Annotations: map[string]string{
+					v1alpha1.BuildReasonAnnotation: "COMMIT",
+				},
+			},
+			Spec: v1alpha1.BuildSpec{
+				Builder: v1alpha1.BuildBuilderSpec{Image: "some-repo.com/my-builder@sha256:builder"},
+				Source: v1alpha1.SourceConfig{
+					Git: &v1alpha1.Git{URL: "https://github.com/some/repo", Revision: revision},
+				},
+				Env: env,
+			},
+			Status: v1alpha1.BuildStatus{
+				BuildMetadata: buildpacks,
+				Stack:         v1alpha1.BuildStack{RunImage: runImage},
+				LatestImage:   latestImage,
+			},
+		}
+	}
+
+	buildOne := makeBuild("1", "abc", "some-repo.com/run@sha256:run-1", "repo.com/image@sha256:image-1",
+		[]corev1.EnvVar{{Name: "BP_JAVA_VERSION", Value: "8"}, {Name: "REMOVED", Value: "value"}},
+		v1alpha1.BuildpackMetadataList{
+			{Id: "bp-removed", Version: "1.0.0"},
+			{Id: "bp-upgraded", Version: "1.2.0"},
+			{Id: "bp-same", Version: "2.0.0"},
+		})
+	buildTwo := makeBuild("2", "def", "some-repo.com/run@sha256:run-2", "repo.com/image@sha256:image-2",
+		[]corev1.EnvVar{{Name: "BP_JAVA_VERSION", Value: "11"}},
+		v1alpha1.BuildpackMetadataList{
+			{Id: "bp-upgraded", Version: "1.10.0"},
+			{Id: "bp-same", Version: "2.0.0"},
+			{Id: "bp-added", Version: "0.1.0"},
+		})
+	buildThree := makeBuild("3", "def", "some-repo.com/run@sha256:run-2", "repo.com/image@sha256:image-2",
+		[]corev1.EnvVar{{Name: "BP_JAVA_VERSION", Value: "11"}},
+		v1alpha1.BuildpackMetadataList{
+			{Id: "bp-upgraded", Version: "1.10.0"},
+			{Id: "bp-same", Version: "2.0.0"},
+			{Id: "bp-added", Version: "0.1.0"},
+		})
+
+	cmdFunc := func(clientSet *kpackfakes.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return build.NewDiffCommand(clientSetProvider)
+	}
+
+	it("displays the differences between two builds", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{buildOne, buildTwo, buildThree},
+			Args:    []string{image, "-b", "1", "-b", "2"},
+			ExpectedOutput: `FIELD                  BUILD 1                           BUILD 2
+Run Image              some-repo.com/run@sha256:run-1    some-repo.com/run@sha256:run-2
+Git Revision           abc                               def
+Image                  repo.com/image@sha256:image-1     repo.com/image@sha256:image-2
+Env BP_JAVA_VERSION    8                                 11
+Env REMOVED            value                             --
+
+BUILDPACK ID    BUILD 1    BUILD 2    CHANGE
+bp-removed      1.0.0      --         removed
+bp-upgraded     1.2.0      1.10.0     upgraded
+bp-added        --         0.1.0      added
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("defaults to the two latest builds", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{buildOne, buildTwo, buildThree},
+			Args:           []string{image},
+			ExpectedOutput: "No differences between build 2 and build 3\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("displays the differences as json", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{buildTwo, buildThree},
+			Args:    []string{image, "--output", "json"},
+			ExpectedOutput: `{
+  "from": "2",
+  "to": "3",
+  "changes": [],
+  "buildpacks": []
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when only one build number is provided", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{buildOne, buildTwo},
+			Args:           []string{image, "-b", "1"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: exactly two build numbers must be provided\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when there are fewer than two builds", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{buildOne},
+			Args:           []string{image},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: at least two builds are required to compare\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
@@ -0,0 +1,17 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"context"
+	"io"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+)
+
+type LogTailer interface {
+	Tail(ctx context.Context, writer io.Writer, bld *v1alpha1.Build, opts build.LogOptions) error
+}
@@ -4,11 +4,11 @@
 package build
 
 import (
-	"context"
+	"fmt"
 	"sort"
+	"time"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
-	"github.com/pivotal/kpack/pkg/logs"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,28 +18,97 @@ import (
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 )
 
-func NewLogsCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+func NewLogsCommand(clientSetProvider k8s.ClientSetProvider, newLogTailer func(k8s.ClientSet) LogTailer) *cobra.Command {
 	var (
 		namespace   string
 		buildNumber string
+		steps       []string
+		timestamps  bool
+		noFollow    bool
+		saveDir     string
+		allBuilds   bool
+		since       time.Duration
+		selector    string
 	)
 
 	cmd := &cobra.Command{
-		Use:   "logs <image-name>",
+		Use:   "logs [<image-name>...]",
 		Short: "Tails logs for an image build",
 		Long: `Tails logs from the containers of a specific build of an image in the provided namespace.
 
 The build defaults to the latest build number.
-The namespace defaults to the kubernetes current-context namespace.`,
-		Example:      "kp build logs my-image\nkp build logs my-image -b 2 -n my-namespace",
-		Args:         commands.ExactArgsWithUsage(1),
+The namespace defaults to the kubernetes current-context namespace.
+
+Logs may be limited to specific lifecycle steps by using the "--step" flag.
+Supported steps are prepare, detect, analyze, restore, build, and export.
+
+Use "--no-follow" to print the logs that are currently available without waiting for the build to finish.
+
+Use "--save" to write the logs to <dir>/<image>/<build-number>.log instead of printing them.
+A <build-number>.json file with the status, reason and timing of the build is written next to each log file.
+
+Use "--all-builds" to get the logs of every finished build of the image, or of every image in the namespace when no image is provided.
+Builds may be limited to those that finished recently by using the "--since" flag.
+
+When multiple images or a selector are provided, the active builds of all matching images are tailed concurrently.
+Each log line is prefixed with [<image>#<build-number>] and builds that start while tailing are picked up.
+The command exits once all tracked builds have finished and fails if any of them did not succeed.`,
+		Example: `kp build logs my-image
+kp build logs my-image -b 2 -n my-namespace
+kp build logs my-image --step detect --step build
+kp build logs my-image -b 2 --timestamps --no-follow
+kp build logs my-image -b 2 --save ./logs
+kp build logs --all-builds --since 24h --save ./logs
+kp build logs my-image my-other-image
+kp build logs -l team=payments`,
+		Args:         cobra.ArbitraryArgs,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			multipleImages := selector != "" || len(args) > 1
+
+			switch {
+			case multipleImages && len(args) > 0 && selector != "":
+				return errors.New("image names and a selector cannot be used together")
+			case multipleImages && (buildNumber != "" || saveDir != "" || allBuilds || noFollow):
+				return errors.New("--build, --save, --all-builds and --no-follow cannot be used with multiple images or a selector")
+			case !multipleImages && !allBuilds:
+				if err := commands.ExactArgsWithUsage(1)(cmd, args); err != nil {
+					return err
+				}
+			}
+
+			if allBuilds && buildNumber != "" {
+				return errors.New("a build number cannot be used with --all-builds")
+			}
+
+			if since > 0 && !allBuilds {
+				return errors.New("--since can only be used with --all-builds")
+			}
+
+			if err := build.ValidateSteps(steps); err != nil {
+				return err
+			}
+
 			cs, err := clientSetProvider.GetClientSet(namespace)
 			if err != nil {
 				return err
 			}
 
+			opts := build.LogOptions{
+				Steps:      steps,
+				Timestamps: timestamps,
+				Follow:     !noFollow,
+				NoColor:    saveDir != "",
+			}
+
+			if multipleImages {
+				return tailImageBuilds(cmd, newLogTailer(cs), cs, args, selector, opts)
+			}
+
+			if allBuilds {
+				return archiveBuildLogs(cmd, newLogTailer(cs), cs, args, since, opts, saveDir)
+			}
+
 			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
 				LabelSelector: v1alpha1.ImageLabel + "=" + args[0],
 			})
@@ -55,12 +124,97 @@ The namespace defaults to the kubernetes current-context namespace.`,
 				if err != nil {
 					return err
 				}
-				return logs.NewBuildLogsClient(cs.K8sClient).Tail(context.Background(), cmd.OutOrStdout(), args[0], bld.Labels[v1alpha1.BuildNumberLabel], cs.Namespace)
+
+				if saveDir == "" {
+					return newLogTailer(cs).Tail(cmd.Context(), cmd.OutOrStdout(), &bld, opts)
+				}
+
+				path, err := saveBuildLogs(cmd.Context(), newLogTailer(cs), bld, opts, saveDir)
+				if err != nil {
+					return err
+				}
+
+				_, err = fmt.Fprintf(cmd.OutOrStdout(), "Saved logs for %s to %s\n", describeBuild(bld), path)
+				return err
 			}
 		},
 	}
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
 	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().StringArrayVar(&steps, "step", []string{}, "lifecycle step to show logs for")
+	cmd.Flags().BoolVar(&timestamps, "timestamps", false, "prefix each log line with its timestamp")
+	cmd.Flags().BoolVar(&noFollow, "no-follow", false, "print available logs and exit without waiting for the build to finish")
+	cmd.Flags().StringVar(&saveDir, "save", "", "directory to save the logs to")
+	cmd.Flags().BoolVar(&allBuilds, "all-builds", false, "get the logs of all finished builds")
+	cmd.Flags().DurationVar(&since, "since", 0, "only include builds that finished within this duration (e.g. 24h)")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "label selector of the builds to tail")
 
 	return cmd
 }
+
+func archiveBuildLogs(cmd *cobra.Command, logTailer LogTailer, cs k8s.ClientSet, args []string, since time.Duration, opts build.LogOptions, saveDir string) error {
+	var labelSelector string
+	if len(args) > 0 {
+		labelSelector = v1alpha1.ImageLabel + "=" + args[0]
+	}
+
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return err
+	}
+
+	var builds []v1alpha1.Build
+	for _, bld := range buildList.Items {
+		if _, ok := bld.Labels[v1alpha1.ImageLabel]; !ok || bld.IsRunning() {
+			continue
+		}
+
+		if since > 0 && getCompletedAt(bld).Before(time.Now().Add(-since)) {
+			continue
+		}
+
+		builds = append(builds, bld)
+	}
+
+	if len(builds) == 0 {
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), "No finished builds found")
+		return err
+	}
+
+	sort.Slice(builds, build.Sort(builds))
+	opts.Follow = false
+
+	for _, bld := range builds {
+		if saveDir == "" {
+			_, err := fmt.Fprintf(cmd.OutOrStdout(), "Logs for %s:\n", describeBuild(bld))
+			if err != nil {
+				return err
+			}
+
+			err = logTailer.Tail(cmd.Context(), cmd.OutOrStdout(), &bld, opts)
+			if err != nil {
+				if _, err := fmt.Fprintf(cmd.ErrOrStderr(), "Skipping %s: %s\n", describeBuild(bld), err); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		path, err := saveBuildLogs(cmd.Context(), logTailer, bld, opts, saveDir)
+		if err != nil {
+			if _, err := fmt.Fprintf(cmd.ErrOrStderr(), "Skipping %s: %s\n", describeBuild(bld), err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "Saved logs for %s to %s\n", describeBuild(bld), path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -4,13 +4,28 @@
 package build_test
 
 import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
+	buildpkg "github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/build/fakes"
 	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
 )
 
@@ -24,13 +39,101 @@ func testBuildLogsCommand(t *testing.T, when spec.G, it spec.S) {
 		defaultNamespace = "some-default-namespace"
 	)
 
+	var (
+		logTailer *fakes.FakeLogTailer
+		saveDir   string
+	)
+
+	it.Before(func() {
+		logTailer = &fakes.FakeLogTailer{
+			Logs: map[string]string{
+				"build-one":   "some build one logs\n",
+				"build-three": "some build three logs\n",
+			},
+			Errors: map[string]error{
+				"build-two": errors.New("build pod for build \"build-two\" not found"),
+			},
+		}
+
+		var err error
+		saveDir, err = ioutil.TempDir("", "build-logs")
+		require.NoError(t, err)
+	})
+
+	it.After(func() {
+		require.NoError(t, os.RemoveAll(saveDir))
+	})
+
 	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
 		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-		return build.NewLogsCommand(clientSetProvider)
+		return build.NewLogsCommand(clientSetProvider, func(k8s.ClientSet) build.LogTailer {
+			return logTailer
+		})
 	}
 
 	when("getting build logs", func() {
 		when("in the default namespace", func() {
+			when("the build exists", func() {
+				when("the build flag is provided", func() {
+					it("tails the logs of the build", func() {
+						testhelpers.CommandTest{
+							Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+							Args:           []string{image, "-b", "1"},
+							ExpectedOutput: "some build one logs\n",
+						}.TestKpack(t, cmdFunc)
+
+						require.Len(t, logTailer.Calls, 1)
+						require.Equal(t, fakes.LogTailerCall{
+							Build:   "build-one",
+							Options: buildpkg.LogOptions{Steps: []string{}, Follow: true},
+						}, logTailer.Calls[0])
+					})
+				})
+
+				when("the build flag is not provided", func() {
+					it("tails the logs of the most recent build", func() {
+						testhelpers.CommandTest{
+							Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+							Args:           []string{image},
+							ExpectedOutput: "some build three logs\n",
+						}.TestKpack(t, cmdFunc)
+
+						require.Len(t, logTailer.Calls, 1)
+						require.Equal(t, "build-three", logTailer.Calls[0].Build)
+					})
+				})
+
+				when("steps, timestamps and no-follow are provided", func() {
+					it("passes the log options to the log tailer", func() {
+						testhelpers.CommandTest{
+							Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+							Args:           []string{image, "--step", "detect", "--step", "build", "--timestamps", "--no-follow"},
+							ExpectedOutput: "some build three logs\n",
+						}.TestKpack(t, cmdFunc)
+
+						require.Len(t, logTailer.Calls, 1)
+						require.Equal(t, buildpkg.LogOptions{
+							Steps:      []string{"detect", "build"},
+							Timestamps: true,
+							Follow:     false,
+						}, logTailer.Calls[0].Options)
+					})
+				})
+
+				when("an invalid step is provided", func() {
+					it("returns an error", func() {
+						testhelpers.CommandTest{
+							Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+							Args:           []string{image, "--step", "completion"},
+							ExpectErr:      true,
+							ExpectedOutput: "Error: invalid step \"completion\", must be one of prepare, detect, analyze, restore, build, or export\n",
+						}.TestKpack(t, cmdFunc)
+
+						require.Len(t, logTailer.Calls, 0)
+					})
+				})
+			})
+
 			when("the build does not exist", func() {
 				when("the build flag is provided", func() {
 					it("prints an appropriate message", func() {
@@ -55,6 +158,175 @@ func testBuildLogsCommand(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("saving logs", func() {
+			it("writes the logs and metadata of the build to the directory", func() {
+				logPath := filepath.Join(saveDir, image, "1.log")
+
+				testhelpers.CommandTest{
+					Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+					Args:           []string{image, "-b", "1", "--save", saveDir},
+					ExpectedOutput: "Saved logs for build \"1\" of image \"test-image\" to " + logPath + "\n",
+				}.TestKpack(t, cmdFunc)
+
+				require.Len(t, logTailer.Calls, 1)
+				require.Equal(t, buildpkg.LogOptions{Steps: []string{}, Follow: true, NoColor: true}, logTailer.Calls[0].Options)
+
+				logs, err := ioutil.ReadFile(logPath)
+				require.NoError(t, err)
+				require.Equal(t, "some build one logs\n", string(logs))
+
+				metadata, err := ioutil.ReadFile(filepath.Join(saveDir, image, "1.json"))
+				require.NoError(t, err)
+				require.Equal(t, `{
+  "name": "build-one",
+  "namespace": "some-default-namespace",
+  "image": "test-image",
+  "buildNumber": "1",
+  "status": "SUCCESS",
+  "buildReason": "CONFIG",
+  "started": "0001-01-01T00:00:00Z",
+  "podName": "pod-one",
+  "latestImage": "repo.com/image-1:tag"
+}
+`, string(metadata))
+			})
+
+			it("saves the logs of all finished builds and skips builds without logs", func() {
+				testhelpers.CommandTest{
+					Objects:             testhelpers.MakeTestBuilds(image, defaultNamespace),
+					Args:                []string{"--all-builds", "--save", saveDir},
+					ExpectedOutput:      "Saved logs for build \"1\" of image \"test-image\" to " + filepath.Join(saveDir, image, "1.log") + "\n",
+					ExpectedErrorOutput: "Skipping build \"2\" of image \"test-image\": build pod for build \"build-two\" not found\n",
+				}.TestKpack(t, cmdFunc)
+
+				require.Len(t, logTailer.Calls, 2)
+				require.Equal(t, buildpkg.LogOptions{Steps: []string{}, Follow: false, NoColor: true}, logTailer.Calls[0].Options)
+
+				_, err := os.Stat(filepath.Join(saveDir, image, "2.log"))
+				require.True(t, os.IsNotExist(err))
+			})
+		})
+
+		when("getting the logs of all builds", func() {
+			it("prints the logs of all finished builds of the image", func() {
+				testhelpers.CommandTest{
+					Objects: testhelpers.MakeTestBuilds(image, defaultNamespace),
+					Args:    []string{image, "--all-builds"},
+					ExpectedOutput: `Logs for build "1" of image "test-image":
+some build one logs
+Logs for build "2" of image "test-image":
+`,
+					ExpectedErrorOutput: "Skipping build \"2\" of image \"test-image\": build pod for build \"build-two\" not found\n",
+				}.TestKpack(t, cmdFunc)
+			})
+
+			it("only includes builds that finished within the since duration", func() {
+				testhelpers.CommandTest{
+					Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+					Args:           []string{"--all-builds", "--since", "24h"},
+					ExpectedOutput: "No finished builds found\n",
+				}.TestKpack(t, cmdFunc)
+
+				require.Len(t, logTailer.Calls, 0)
+			})
+
+			it("errors when since is used without all builds", func() {
+				testhelpers.CommandTest{
+					Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+					Args:           []string{image, "--since", "24h"},
+					ExpectErr:      true,
+					ExpectedOutput: "Error: --since can only be used with --all-builds\n",
+				}.TestKpack(t, cmdFunc)
+			})
+		})
+
+		when("tailing multiple images", func() {
+			var completingTailer *completingLogTailer
+
+			multiCmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+				completingTailer.clientSet = clientSet
+				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+				return build.NewLogsCommand(clientSetProvider, func(k8s.ClientSet) build.LogTailer {
+					return completingTailer
+				})
+			}
+
+			it.Before(func() {
+				completingTailer = &completingLogTailer{
+					logs: map[string]string{
+						"payments-1": "payments one logs\n",
+						"payments-2": "payments two logs\n",
+						"orders-3":   "orders three logs\n",
+					},
+					results: map[string]corev1.ConditionStatus{
+						"payments-1": corev1.ConditionTrue,
+						"payments-2": corev1.ConditionTrue,
+						"orders-3":   corev1.ConditionFalse,
+					},
+					next: map[string]*v1alpha1.Build{
+						"payments-1": makeLogsTestBuild("payments-2", "payments", "2", defaultNamespace, corev1.ConditionUnknown),
+					},
+				}
+			})
+
+			it("tails the active builds matching the selector and picks up new builds", func() {
+				testhelpers.CommandTest{
+					Objects: []runtime.Object{
+						makeLogsTestBuild("payments-1", "payments", "1", defaultNamespace, corev1.ConditionUnknown),
+						makeLogsTestBuild("orders-3", "orders", "3", defaultNamespace, corev1.ConditionUnknown),
+					},
+					Args: []string{"-l", "team=payments"},
+					ExpectedOutput: buildpkg.ColorPrefix(0, "[payments#1]") + " payments one logs\n" +
+						buildpkg.ColorPrefix(0, "[payments#2]") + " payments two logs\n" + `
+IMAGE       BUILD    STATUS
+payments    1        SUCCESS
+payments    2        SUCCESS
+
+`,
+				}.TestKpack(t, multiCmdFunc)
+			})
+
+			it("only tails images with an active build and fails when a build does not succeed", func() {
+				testhelpers.CommandTest{
+					Objects: append(testhelpers.MakeTestBuilds(image, defaultNamespace)[:1],
+						makeLogsTestBuild("orders-3", "orders", "3", defaultNamespace, corev1.ConditionUnknown),
+					),
+					Args:      []string{image, "orders"},
+					ExpectErr: true,
+					ExpectedOutput: buildpkg.ColorPrefix(0, "[orders#3]") + " orders three logs\n" + `
+IMAGE     BUILD    STATUS
+orders    3        FAILURE
+
+Error: 1 of 1 builds did not succeed
+`,
+				}.TestKpack(t, multiCmdFunc)
+			})
+
+			it("prints a message when there are no active builds", func() {
+				testhelpers.CommandTest{
+					Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace)[:1],
+					Args:           []string{image, "orders"},
+					ExpectedOutput: "No active builds found\n",
+				}.TestKpack(t, multiCmdFunc)
+			})
+
+			it("errors when a build number is provided", func() {
+				testhelpers.CommandTest{
+					Args:           []string{image, "orders", "-b", "1"},
+					ExpectErr:      true,
+					ExpectedOutput: "Error: --build, --save, --all-builds and --no-follow cannot be used with multiple images or a selector\n",
+				}.TestKpack(t, multiCmdFunc)
+			})
+
+			it("errors when image names and a selector are provided", func() {
+				testhelpers.CommandTest{
+					Args:           []string{image, "-l", "team=payments"},
+					ExpectErr:      true,
+					ExpectedOutput: "Error: image names and a selector cannot be used together\n",
+				}.TestKpack(t, multiCmdFunc)
+			})
+		})
+
 		when("in a given namespace", func() {
 			const namespace = "some-namespace"
 			when("the build does not exist", func() {
@@ -82,3 +354,51 @@ func testBuildLogsCommand(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 }
+
+// completingLogTailer completes each build it tails and optionally starts the next build of the image.
+type completingLogTailer struct {
+	clientSet *fake.Clientset
+	logs      map[string]string
+	results   map[string]corev1.ConditionStatus
+	next      map[string]*v1alpha1.Build
+}
+
+func (l *completingLogTailer) Tail(_ context.Context, writer io.Writer, bld *v1alpha1.Build, _ buildpkg.LogOptions) error {
+	if _, err := io.WriteString(writer, l.logs[bld.Name]); err != nil {
+		return err
+	}
+
+	gvr := v1alpha1.SchemeGroupVersion.WithResource("builds")
+	if next, ok := l.next[bld.Name]; ok {
+		if err := l.clientSet.Tracker().Create(gvr, next, next.Namespace); err != nil {
+			return err
+		}
+	}
+
+	completed := bld.DeepCopy()
+	completed.Status.Conditions = corev1alpha1.Conditions{
+		{Type: corev1alpha1.ConditionSucceeded, Status: l.results[bld.Name]},
+	}
+	return l.clientSet.Tracker().Update(gvr, completed, completed.Namespace)
+}
+
+func makeLogsTestBuild(name, image, number, namespace string, status corev1.ConditionStatus) *v1alpha1.Build {
+	return &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				v1alpha1.ImageLabel:       image,
+				v1alpha1.BuildNumberLabel: number,
+				"team":                    image,
+			},
+		},
+		Status: v1alpha1.BuildStatus{
+			Status: corev1alpha1.Status{
+				Conditions: corev1alpha1.Conditions{
+					{Type: corev1alpha1.ConditionSucceeded, Status: status},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+// logMultiplexer tails builds concurrently, prefixing every line with the image and build number.
+type logMultiplexer struct {
+	ctx       context.Context
+	logTailer LogTailer
+	opts      build.LogOptions
+	out       io.Writer
+	errOut    io.Writer
+
+	lock   sync.Mutex
+	wg     sync.WaitGroup
+	colors map[string]int
+	builds map[string]v1alpha1.Build
+}
+
+// tailImageBuilds tails the active builds of the images or of the builds matching the selector concurrently.
+// Builds that start while tailing are picked up and it returns once every tracked build has finished.
+func tailImageBuilds(cmd *cobra.Command, logTailer LogTailer, cs k8s.ClientSet, images []string, selector string, opts build.LogOptions) error {
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	matches := func(bld v1alpha1.Build) bool {
+		img, ok := bld.Labels[v1alpha1.ImageLabel]
+		return ok && (len(images) == 0 || contains(images, img))
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: selector}
+	if selector == "" {
+		listOptions.LabelSelector = v1alpha1.ImageLabel
+	}
+
+	// watch before listing so builds that start in between are not missed
+	watchOptions := listOptions
+	watchOptions.Watch = true
+	watcher, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).Watch(watchOptions)
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(listOptions)
+	if err != nil {
+		return err
+	}
+
+	m := &logMultiplexer{
+		ctx:       ctx,
+		logTailer: logTailer,
+		opts:      opts,
+		out:       cmd.OutOrStdout(),
+		errOut:    cmd.ErrOrStderr(),
+		colors:    map[string]int{},
+		builds:    map[string]v1alpha1.Build{},
+	}
+
+	latest := map[string]v1alpha1.Build{}
+	for _, bld := range buildList.Items {
+		if !matches(bld) {
+			continue
+		}
+
+		img := bld.Labels[v1alpha1.ImageLabel]
+		if current, ok := latest[img]; !ok || buildNumber(bld) > buildNumber(current) {
+			latest[img] = bld
+		}
+	}
+
+	for _, img := range sortedKeys(latest) {
+		m.update(latest[img])
+	}
+
+	if len(m.builds) == 0 {
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), "No active builds found")
+		return err
+	}
+
+	for !m.finished() {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return errors.New("build watch closed unexpectedly")
+			}
+
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+
+			bld, ok := event.Object.(*v1alpha1.Build)
+			if !ok || !matches(*bld) {
+				continue
+			}
+
+			m.update(*bld)
+		}
+	}
+
+	m.wg.Wait()
+	return m.summarize()
+}
+
+// update records the latest state of a build and starts tailing it when it is a new active build.
+func (m *logMultiplexer) update(bld v1alpha1.Build) {
+	if _, ok := m.builds[bld.Name]; ok {
+		m.builds[bld.Name] = bld
+		return
+	}
+
+	if !isActive(bld) {
+		return
+	}
+	m.builds[bld.Name] = bld
+
+	img := bld.Labels[v1alpha1.ImageLabel]
+	if _, ok := m.colors[img]; !ok {
+		m.colors[img] = len(m.colors)
+	}
+
+	prefix := build.ColorPrefix(m.colors[img], buildPrefix(bld))
+	writer := build.NewPrefixWriter(m.out, &m.lock, prefix)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		err := m.logTailer.Tail(m.ctx, writer, &bld, m.opts)
+		if flushErr := writer.Flush(); err == nil {
+			err = flushErr
+		}
+
+		if err != nil {
+			m.lock.Lock()
+			defer m.lock.Unlock()
+			_, _ = fmt.Fprintf(m.errOut, "%s %s\n", prefix, err)
+		}
+	}()
+}
+
+func (m *logMultiplexer) finished() bool {
+	for _, bld := range m.builds {
+		if isActive(bld) {
+			return false
+		}
+	}
+	return true
+}
+
+// summarize writes the final status of every tracked build and returns an error if any build failed.
+func (m *logMultiplexer) summarize() error {
+	var builds []v1alpha1.Build
+	for _, bld := range m.builds {
+		builds = append(builds, bld)
+	}
+
+	sort.Slice(builds, func(i, j int) bool {
+		if builds[i].Labels[v1alpha1.ImageLabel] != builds[j].Labels[v1alpha1.ImageLabel] {
+			return builds[i].Labels[v1alpha1.ImageLabel] < builds[j].Labels[v1alpha1.ImageLabel]
+		}
+		return buildNumber(builds[i]) < buildNumber(builds[j])
+	})
+
+	if _, err := fmt.Fprintln(m.out); err != nil {
+		return err
+	}
+
+	writer, err := commands.NewTableWriter(m.out, "Image", "Build", "Status")
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, bld := range builds {
+		status := getStatus(bld)
+		if status != "SUCCESS" {
+			failed++
+		}
+
+		err := writer.AddRow(bld.Labels[v1alpha1.ImageLabel], bld.Labels[v1alpha1.BuildNumberLabel], status)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Write(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d of %d builds did not succeed", failed, len(builds))
+	}
+	return nil
+}
+
+func isActive(bld v1alpha1.Build) bool {
+	status := getStatus(bld)
+	return status != "SUCCESS" && status != "FAILURE"
+}
+
+func buildPrefix(bld v1alpha1.Build) string {
+	return fmt.Sprintf("[%s#%s]", bld.Labels[v1alpha1.ImageLabel], bld.Labels[v1alpha1.BuildNumberLabel])
+}
+
+func buildNumber(bld v1alpha1.Build) int {
+	n, _ := strconv.Atoi(bld.Labels[v1alpha1.BuildNumberLabel])
+	return n
+}
+
+func sortedKeys(builds map[string]v1alpha1.Build) []string {
+	var keys []string
+	for k := range builds {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
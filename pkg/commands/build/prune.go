@@ -0,0 +1,87 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+func NewPruneCommand(clientSetProvider k8s.ClientSetProvider, confirmationProvider ConfirmationProvider) *cobra.Command {
+	var (
+		namespace string
+		selector  string
+		keep      int
+		force     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune [<image-name>]",
+		Short: "Delete old image builds",
+		Long: `Delete all but the most recent builds of an image in the provided namespace.
+
+Running builds are never pruned.
+When a selector is provided, the builds of each image matching the selector are pruned.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build prune my-image --keep 5\nkp build prune -l team=my-team --keep 5 -n my-namespace",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateBuildSelection(args, "", selector); err != nil {
+				return err
+			}
+
+			if keep < 1 {
+				return errors.New("keep must be at least 1")
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			imageBuilds, err := listImageBuilds(cs, args, selector)
+			if err != nil {
+				return err
+			}
+
+			var builds []v1alpha1.Build
+			for _, img := range sortedImageNames(imageBuilds) {
+				imgBuilds := imageBuilds[img]
+				if len(imgBuilds) <= keep {
+					continue
+				}
+
+				for _, bld := range imgBuilds[:len(imgBuilds)-keep] {
+					if !bld.IsRunning() {
+						builds = append(builds, bld)
+					}
+				}
+			}
+
+			if len(builds) == 0 {
+				return ch.Printlnf("No builds to prune")
+			}
+
+			return deleteBuilds(cs, ch, confirmationProvider, force, builds)
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "label selector of the images to prune")
+	cmd.Flags().IntVar(&keep, "keep", 10, "number of most recent builds to keep for each image")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "prune without confirmation")
+	cmd.Flags().Bool(commands.DryRunFlag, false, "only print the builds that would be deleted, without deleting them")
+
+	return cmd
+}
@@ -0,0 +1,91 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	clientgotesting "k8s.io/client-go/testing"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildPruneCommand(t *testing.T) {
+	spec.Run(t, "TestBuildPruneCommand", testBuildPruneCommand)
+}
+
+func testBuildPruneCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+	)
+
+	var confirmationProvider *FakeConfirmationProvider
+
+	it.Before(func() {
+		confirmationProvider = &FakeConfirmationProvider{confirm: true}
+	})
+
+	cmdFunc := func(clientSet *kpackfakes.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return build.NewPruneCommand(clientSetProvider, confirmationProvider)
+	}
+
+	it("deletes all but the most recent builds", func() {
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image, "--keep", "2"},
+			ExpectedOutput: "Deleted build \"1\" of image \"test-image\"\n",
+			ExpectDeletes: []clientgotesting.DeleteActionImpl{
+				{ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace}, Name: "build-one"},
+			},
+		}.TestKpack(t, cmdFunc)
+		require.True(t, confirmationProvider.requested)
+	})
+
+	it("prunes the builds of each image matching a selector", func() {
+		testhelpers.CommandTest{
+			Objects: testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:    []string{"-l", "image.kpack.io/image", "--keep", "1", "--force"},
+			ExpectedOutput: `Deleted build "1" of image "test-image"
+Deleted build "2" of image "test-image"
+`,
+			ExpectDeletes: []clientgotesting.DeleteActionImpl{
+				{ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace}, Name: "build-one"},
+				{ActionImpl: clientgotesting.ActionImpl{Namespace: defaultNamespace}, Name: "build-two"},
+			},
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("does not prune when there are no more builds than kept", func() {
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image},
+			ExpectedOutput: "No builds to prune\n",
+		}.TestKpack(t, cmdFunc)
+		require.False(t, confirmationProvider.requested)
+	})
+
+	it("does not prune with dry run", func() {
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image, "--keep", "2", "--dry-run"},
+			ExpectedOutput: "Deleted build \"1\" of image \"test-image\" (dry run)\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when keep is less than one", func() {
+		testhelpers.CommandTest{
+			Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+			Args:           []string{image, "--keep", "0"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: keep must be at least 1\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
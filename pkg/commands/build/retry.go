@@ -0,0 +1,118 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+func NewRetryCommand(clientSetProvider k8s.ClientSetProvider, confirmationProvider ConfirmationProvider) *cobra.Command {
+	var (
+		namespace string
+		selector  string
+		force     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "retry [<image-name>]",
+		Short: "Rebuild the latest build of an image",
+		Long: `Rebuild the latest build of an image in the provided namespace.
+
+The image is triggered so that kpack schedules a new build with the next build number.
+The new build uses the current source, builder and configuration of the image, which may differ from the inputs of the retried build.
+The latest build of the image must have finished.
+When a selector is provided, the latest build of each image matching the selector is rebuilt.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build retry my-image\nkp build retry my-image -n my-namespace\nkp build retry -l team=my-team",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateBuildSelection(args, "", selector); err != nil {
+				return err
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			imageBuilds, err := listImageBuilds(cs, args, selector)
+			if err != nil {
+				return err
+			}
+
+			var retries []v1alpha1.Build
+			if len(args) > 0 {
+				builds := imageBuilds[args[0]]
+				bld := builds[len(builds)-1]
+				if bld.IsRunning() {
+					return errors.Errorf("%s is still running", describeBuild(bld))
+				}
+				retries = append(retries, bld)
+			} else {
+				for _, img := range sortedImageNames(imageBuilds) {
+					builds := imageBuilds[img]
+					latest := builds[len(builds)-1]
+					if latest.IsRunning() {
+						if err := ch.Printlnf("Skipping %s, build is still running", describeBuild(latest)); err != nil {
+							return err
+						}
+						continue
+					}
+					retries = append(retries, latest)
+				}
+			}
+
+			if len(retries) == 0 {
+				return ch.Printlnf("No builds to retry")
+			}
+
+			confirmed, err := confirmBuilds(ch, confirmationProvider, force, "retry", len(retries))
+			if err != nil {
+				return err
+			}
+
+			if !confirmed {
+				return ch.Printlnf("Skipping build retry")
+			}
+
+			for _, bld := range retries {
+				requested := build.RequestBuild(bld)
+				if !ch.IsDryRun() {
+					requested, err = cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).Update(requested)
+					if err != nil {
+						return err
+					}
+				}
+
+				if err := ch.PrintObj(requested); err != nil {
+					return err
+				}
+
+				if err := ch.PrintResult("Retrying %s, triggered image %q", describeBuild(bld), bld.Labels[v1alpha1.ImageLabel]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "label selector of the images to rebuild")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "rebuild without confirmation")
+	commands.SetDryRunOutputFlags(cmd)
+
+	return cmd
+}
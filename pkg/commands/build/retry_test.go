@@ -0,0 +1,118 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	buildpkg "github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildRetryCommand(t *testing.T) {
+	spec.Run(t, "TestBuildRetryCommand", testBuildRetryCommand)
+}
+
+func testBuildRetryCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		image            = "test-image"
+		defaultNamespace = "some-default-namespace"
+	)
+
+	var (
+		confirmationProvider *FakeConfirmationProvider
+		kpackClient          *kpackfakes.Clientset
+		out                  *bytes.Buffer
+	)
+
+	finishedBuilds := func() []runtime.Object {
+		builds := testhelpers.MakeTestBuilds(image, defaultNamespace)
+		builds[1].(*v1alpha1.Build).Status.Conditions = corev1alpha1.Conditions{
+			{Type: corev1alpha1.ConditionSucceeded, Status: corev1.ConditionFalse},
+		}
+		return builds
+	}
+
+	it.Before(func() {
+		confirmationProvider = &FakeConfirmationProvider{confirm: true}
+		kpackClient = kpackfakes.NewSimpleClientset(finishedBuilds()...)
+		out = &bytes.Buffer{}
+	})
+
+	execute := func(args ...string) error {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(kpackClient, defaultNamespace)
+		cmd := build.NewRetryCommand(clientSetProvider, confirmationProvider)
+		cmd.SetArgs(args)
+		cmd.SetOut(out)
+		cmd.SetErr(out)
+		return cmd.Execute()
+	}
+
+	buildUpdates := func() []*v1alpha1.Build {
+		actions, err := testhelpers.ActionRecorderList{kpackClient}.ActionsByVerb()
+		require.NoError(t, err)
+		require.Len(t, actions.Creates, 0)
+
+		var builds []*v1alpha1.Build
+		for _, u := range actions.Updates {
+			builds = append(builds, u.GetObject().(*v1alpha1.Build))
+		}
+		return builds
+	}
+
+	it("triggers the image of the latest build instead of creating a build", func() {
+		require.NoError(t, execute(image))
+		require.Equal(t, "Retrying build \"3\" of image \"test-image\", triggered image \"test-image\"\n", out.String())
+		require.True(t, confirmationProvider.requested)
+
+		updates := buildUpdates()
+		require.Len(t, updates, 1)
+		require.Equal(t, "build-three", updates[0].Name)
+		require.NotEmpty(t, updates[0].Annotations[buildpkg.BuildNeededAnnotation])
+	})
+
+	it("does not accept a build number", func() {
+		require.EqualError(t, execute(image, "-b", "2"), "unknown shorthand flag: 'b' in -b")
+		require.Len(t, buildUpdates(), 0)
+	})
+
+	it("errors when the build is still running", func() {
+		kpackClient = kpackfakes.NewSimpleClientset(testhelpers.MakeTestBuilds(image, defaultNamespace)...)
+
+		require.EqualError(t, execute(image), "build \"3\" of image \"test-image\" is still running")
+	})
+
+	it("skips images with a running latest build when using a selector", func() {
+		kpackClient = kpackfakes.NewSimpleClientset(testhelpers.MakeTestBuilds(image, defaultNamespace)...)
+
+		require.NoError(t, execute("-l", v1alpha1.ImageLabel+"="+image))
+		require.Equal(t, "Skipping build \"3\" of image \"test-image\", build is still running\nNo builds to retry\n", out.String())
+		require.False(t, confirmationProvider.requested)
+	})
+
+	it("does not trigger the image with dry run", func() {
+		require.NoError(t, execute(image, "--dry-run"))
+		require.Equal(t, "Retrying build \"3\" of image \"test-image\", triggered image \"test-image\" (dry run)\n", out.String())
+		require.False(t, confirmationProvider.requested)
+		require.Len(t, buildUpdates(), 0)
+	})
+
+	it("does not trigger the image when confirmation is declined", func() {
+		confirmationProvider.confirm = false
+
+		require.NoError(t, execute(image))
+		require.Equal(t, "Skipping build retry\n", out.String())
+		require.Len(t, buildUpdates(), 0)
+	})
+}
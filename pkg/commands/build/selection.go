@@ -0,0 +1,88 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+type ConfirmationProvider interface {
+	Confirm(message string, okayResponses ...string) (bool, error)
+}
+
+func validateBuildSelection(args []string, buildNumber, selector string) error {
+	switch {
+	case len(args) == 0 && selector == "":
+		return errors.New("an image name or selector is required")
+	case len(args) > 0 && selector != "":
+		return errors.New("an image name and selector cannot be used together")
+	case selector != "" && buildNumber != "":
+		return errors.New("a build number cannot be used with a selector")
+	}
+	return nil
+}
+
+// listImageBuilds returns the builds of the image in args or of the images matching the selector,
+// grouped by image name and sorted by build number.
+func listImageBuilds(cs k8s.ClientSet, args []string, selector string) (map[string][]v1alpha1.Build, error) {
+	labelSelector := selector
+	if len(args) > 0 {
+		labelSelector = v1alpha1.ImageLabel + "=" + args[0]
+	}
+
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	imageBuilds := map[string][]v1alpha1.Build{}
+	for _, b := range buildList.Items {
+		img, ok := b.Labels[v1alpha1.ImageLabel]
+		if !ok {
+			continue
+		}
+		imageBuilds[img] = append(imageBuilds[img], b)
+	}
+
+	if len(args) > 0 && len(imageBuilds) == 0 {
+		return nil, errors.New("no builds found")
+	}
+
+	for _, builds := range imageBuilds {
+		sort.Slice(builds, build.Sort(builds))
+	}
+	return imageBuilds, nil
+}
+
+func sortedImageNames(imageBuilds map[string][]v1alpha1.Build) []string {
+	var names []string
+	for name := range imageBuilds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func describeBuild(bld v1alpha1.Build) string {
+	return fmt.Sprintf("build %q of image %q", bld.Labels[v1alpha1.BuildNumberLabel], bld.Labels[v1alpha1.ImageLabel])
+}
+
+func confirmBuilds(ch *commands.CommandHelper, confirmationProvider ConfirmationProvider, force bool, action string, count int) (bool, error) {
+	if force || ch.IsDryRun() {
+		return true, nil
+	}
+
+	return confirmationProvider.Confirm(fmt.Sprintf("Please confirm %s of %d build(s) by typing 'y': ", action, count))
+}
@@ -0,0 +1,236 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+func NewStatsCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	var (
+		namespace     string
+		allNamespaces bool
+		since         time.Duration
+		top           int
+		output        string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Display build statistics",
+		Long: `Prints statistics about the builds in the provided namespace or in all namespaces.
+
+The statistics include the number of builds by reason and outcome, the success rate, the p50 and p95 build durations,
+the images and builders with the most failed builds, and the number of rebuilds caused by stack and buildpack updates.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp build stats\nkp build stats -A --since 720h\nkp build stats -n my-namespace --output csv",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "json" && output != "csv" {
+				return errors.Errorf("invalid output format %q, must be one of table, json, or csv", output)
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			listNamespace := cs.Namespace
+			if allNamespaces {
+				listNamespace = metav1.NamespaceAll
+			}
+
+			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(listNamespace).List(metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+
+			builds := buildList.Items
+			if since > 0 {
+				builds = build.FilterSince(builds, time.Now().Add(-since))
+			}
+
+			imageList, err := cs.KpackClient.KpackV1alpha1().Images(listNamespace).List(metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+
+			stats := build.CalculateStats(builds, imageList.Items, top)
+
+			switch output {
+			case "json":
+				return writeJSON(cmd.OutOrStdout(), stats)
+			case "csv":
+				return writeStatsCSV(cmd, stats)
+			default:
+				return displayStats(cmd, stats, allNamespaces)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "include builds in all namespaces")
+	cmd.Flags().DurationVar(&since, "since", 0, "only include builds created within this duration (e.g. 720h)")
+	cmd.Flags().IntVar(&top, "top", 5, "number of most failing images and builders to display")
+	cmd.Flags().StringVar(&output, "output", "table", "output format. supported formats are: table, json, csv")
+
+	return cmd
+}
+
+func displayStats(cmd *cobra.Command, stats build.Stats, allNamespaces bool) error {
+	statusWriter := commands.NewStatusWriter(cmd.OutOrStdout())
+
+	err := statusWriter.AddBlock(
+		"",
+		"Builds", strconv.Itoa(stats.Total),
+		"Succeeded", strconv.Itoa(stats.Succeeded),
+		"Failed", strconv.Itoa(stats.Failed),
+		"Building", strconv.Itoa(stats.Running),
+		"Success Rate", fmt.Sprintf("%.1f%%", stats.SuccessRate),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = statusWriter.AddBlock(
+		"",
+		"Duration p50", stats.DurationP50.Round(time.Second).String(),
+		"Duration p95", stats.DurationP95.Round(time.Second).String(),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = statusWriter.AddBlock(
+		"",
+		"Stack Rebuilds", strconv.Itoa(stats.StackRebuilds),
+		"Buildpack Rebuilds", strconv.Itoa(stats.BuildpackRebuilds),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = statusWriter.Write()
+	if err != nil {
+		return err
+	}
+
+	reasonWriter, err := commands.NewTableWriter(cmd.OutOrStdout(), "Reason", "Total", "Succeeded", "Failed", "Building")
+	if err != nil {
+		return err
+	}
+
+	for _, r := range stats.Reasons {
+		err := reasonWriter.AddRow(r.Reason, strconv.Itoa(r.Total), strconv.Itoa(r.Succeeded), strconv.Itoa(r.Failed), strconv.Itoa(r.Running))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = reasonWriter.Write()
+	if err != nil {
+		return err
+	}
+
+	imageHeaders := []string{"Failing Image", "Failures"}
+	if allNamespaces {
+		imageHeaders = []string{"Failing Image", "Namespace", "Failures"}
+	}
+
+	imageWriter, err := commands.NewTableWriter(cmd.OutOrStdout(), imageHeaders...)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range stats.FailingImages {
+		row := []string{f.Name, strconv.Itoa(f.Failures)}
+		if allNamespaces {
+			row = []string{f.Name, f.Namespace, strconv.Itoa(f.Failures)}
+		}
+
+		err := imageWriter.AddRow(row...)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = imageWriter.Write()
+	if err != nil {
+		return err
+	}
+
+	builderHeaders := []string{"Failing Builder", "Kind", "Failures"}
+	if allNamespaces {
+		builderHeaders = []string{"Failing Builder", "Kind", "Namespace", "Failures"}
+	}
+
+	builderWriter, err := commands.NewTableWriter(cmd.OutOrStdout(), builderHeaders...)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range stats.FailingBuilders {
+		row := []string{valueOrDashes(f.Name), valueOrDashes(f.Kind), strconv.Itoa(f.Failures)}
+		if allNamespaces {
+			row = []string{valueOrDashes(f.Name), valueOrDashes(f.Kind), valueOrDashes(f.Namespace), strconv.Itoa(f.Failures)}
+		}
+
+		err := builderWriter.AddRow(row...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return builderWriter.Write()
+}
+
+func writeStatsCSV(cmd *cobra.Command, stats build.Stats) error {
+	records := [][]string{
+		{"metric", "key", "value"},
+		{"builds", "total", strconv.Itoa(stats.Total)},
+		{"builds", "succeeded", strconv.Itoa(stats.Succeeded)},
+		{"builds", "failed", strconv.Itoa(stats.Failed)},
+		{"builds", "building", strconv.Itoa(stats.Running)},
+		{"success_rate", "", strconv.FormatFloat(stats.SuccessRate, 'f', 1, 64)},
+		{"duration_seconds", "p50", strconv.FormatFloat(stats.DurationP50Secs, 'f', 0, 64)},
+		{"duration_seconds", "p95", strconv.FormatFloat(stats.DurationP95Secs, 'f', 0, 64)},
+		{"rebuilds", build.StackReason, strconv.Itoa(stats.StackRebuilds)},
+		{"rebuilds", build.BuildpackReason, strconv.Itoa(stats.BuildpackRebuilds)},
+	}
+
+	for _, r := range stats.Reasons {
+		records = append(records,
+			[]string{"reason_total", r.Reason, strconv.Itoa(r.Total)},
+			[]string{"reason_succeeded", r.Reason, strconv.Itoa(r.Succeeded)},
+			[]string{"reason_failed", r.Reason, strconv.Itoa(r.Failed)},
+			[]string{"reason_building", r.Reason, strconv.Itoa(r.Running)},
+		)
+	}
+
+	for _, f := range stats.FailingImages {
+		records = append(records, []string{"image_failures", f.Namespace + "/" + f.Name, strconv.Itoa(f.Failures)})
+	}
+
+	for _, f := range stats.FailingBuilders {
+		key := f.Kind + "/" + f.Name
+		if f.Namespace != "" {
+			key = f.Kind + "/" + f.Namespace + "/" + f.Name
+		}
+		records = append(records, []string{"builder_failures", key, strconv.Itoa(f.Failures)})
+	}
+
+	return csv.NewWriter(cmd.OutOrStdout()).WriteAll(records)
+}
@@ -0,0 +1,239 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package build_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestBuildStatsCommand(t *testing.T) {
+	spec.Run(t, "TestBuildStatsCommand", testBuildStatsCommand)
+}
+
+func testBuildStatsCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		otherNamespace   = "some-other-namespace"
+	)
+
+	now := time.Now()
+
+	makeBuild := func(name, namespace, image, reason string, status corev1.ConditionStatus, created time.Time, duration time.Duration) *v1alpha1.Build {
+		return &v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.Time{Time: created},
+				Labels: map[string]string{
+					v1alpha1.ImageLabel: image,
+				},
+				Annotations: map[string]string{
+					v1alpha1.BuildReasonAnnotation: reason,
+				},
+			},
+			Spec: v1alpha1.BuildSpec{
+				Builder: v1alpha1.BuildBuilderSpec{Image: "some-repo.com/builder-for-" + image},
+			},
+			Status: v1alpha1.BuildStatus{
+				Status: corev1alpha1.Status{
+					Conditions: corev1alpha1.Conditions{
+						{
+							Type:               corev1alpha1.ConditionSucceeded,
+							Status:             status,
+							LastTransitionTime: corev1alpha1.VolatileTime{Inner: metav1.Time{Time: created.Add(duration)}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	builds := []runtime.Object{
+		makeBuild("app-1", defaultNamespace, "app", "CONFIG", corev1.ConditionTrue, now.Add(-4*time.Hour), 2*time.Minute),
+		makeBuild("app-2", defaultNamespace, "app", "STACK", corev1.ConditionFalse, now.Add(-3*time.Hour), time.Minute),
+		makeBuild("other-1", defaultNamespace, "other", "COMMIT,BUILDPACK", corev1.ConditionTrue, now.Add(-2*time.Hour), 4*time.Minute),
+		makeBuild("other-2", defaultNamespace, "other", "TRIGGER", corev1.ConditionUnknown, now.Add(-time.Hour), 0),
+		makeBuild("old", defaultNamespace, "other", "CONFIG", corev1.ConditionFalse, now.Add(-48*time.Hour), 10*time.Minute),
+		makeBuild("elsewhere", otherNamespace, "app", "STACK", corev1.ConditionFalse, now.Add(-time.Hour), 3*time.Minute),
+	}
+
+	makeImage := func(name, namespace, builderKind, builderName string) *v1alpha1.Image {
+		return &v1alpha1.Image{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: v1alpha1.ImageSpec{
+				Builder: corev1.ObjectReference{Kind: builderKind, Name: builderName},
+			},
+		}
+	}
+
+	images := []runtime.Object{
+		makeImage("app", defaultNamespace, v1alpha1.ClusterBuilderKind, "default"),
+		makeImage("other", defaultNamespace, v1alpha1.BuilderKind, "some-builder"),
+		makeImage("app", otherNamespace, v1alpha1.ClusterBuilderKind, "default"),
+	}
+
+	cmdFunc := func(clientSet *kpackfakes.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return build.NewStatsCommand(clientSetProvider)
+	}
+
+	it("displays build statistics for the namespace within the since window", func() {
+		testhelpers.CommandTest{
+			Objects: append(images, builds...),
+			Args:    []string{"--since", "24h"},
+			ExpectedOutput: `Builds:          4
+Succeeded:       2
+Failed:          1
+Building:        1
+Success Rate:    66.7%
+
+Duration p50:    2m0s
+Duration p95:    4m0s
+
+Stack Rebuilds:        1
+Buildpack Rebuilds:    1
+
+REASON       TOTAL    SUCCEEDED    FAILED    BUILDING
+BUILDPACK    1        1            0         0
+COMMIT       1        1            0         0
+CONFIG       1        1            0         0
+STACK        1        0            1         0
+TRIGGER      1        0            0         1
+
+FAILING IMAGE    FAILURES
+app              1
+
+FAILING BUILDER    KIND              FAILURES
+default            ClusterBuilder    1
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("displays build statistics for all namespaces as csv", func() {
+		testhelpers.CommandTest{
+			Objects: append(images, builds...),
+			Args:    []string{"-A", "--output", "csv", "--top", "1"},
+			ExpectedOutput: `metric,key,value
+builds,total,6
+builds,succeeded,2
+builds,failed,3
+builds,building,1
+success_rate,,40.0
+duration_seconds,p50,180
+duration_seconds,p95,600
+rebuilds,STACK,2
+rebuilds,BUILDPACK,1
+reason_total,CONFIG,2
+reason_succeeded,CONFIG,1
+reason_failed,CONFIG,1
+reason_building,CONFIG,0
+reason_total,STACK,2
+reason_succeeded,STACK,0
+reason_failed,STACK,2
+reason_building,STACK,0
+reason_total,BUILDPACK,1
+reason_succeeded,BUILDPACK,1
+reason_failed,BUILDPACK,0
+reason_building,BUILDPACK,0
+reason_total,COMMIT,1
+reason_succeeded,COMMIT,1
+reason_failed,COMMIT,0
+reason_building,COMMIT,0
+reason_total,TRIGGER,1
+reason_succeeded,TRIGGER,0
+reason_failed,TRIGGER,0
+reason_building,TRIGGER,1
+image_failures,some-default-namespace/app,1
+builder_failures,ClusterBuilder/default,2
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("displays builders of builds whose image no longer exists as unknown", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{images[1], builds[1], builds[4]},
+			Args:    []string{"-A"},
+			ExpectedOutput: `Builds:          2
+Succeeded:       0
+Failed:          2
+Building:        0
+Success Rate:    0.0%
+
+Duration p50:    1m0s
+Duration p95:    10m0s
+
+Stack Rebuilds:        1
+Buildpack Rebuilds:    0
+
+REASON    TOTAL    SUCCEEDED    FAILED    BUILDING
+CONFIG    1        0            1         0
+STACK     1        0            1         0
+
+FAILING IMAGE    NAMESPACE                 FAILURES
+app              some-default-namespace    1
+other            some-default-namespace    1
+
+FAILING BUILDER    KIND       NAMESPACE                 FAILURES
+--                 --         --                        1
+some-builder       Builder    some-default-namespace    1
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("displays build statistics as json", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{builds[0]},
+			Args:    []string{"--output", "json"},
+			ExpectedOutput: `{
+  "total": 1,
+  "succeeded": 1,
+  "failed": 0,
+  "running": 0,
+  "successRate": 100,
+  "durationP50Seconds": 120,
+  "durationP95Seconds": 120,
+  "reasons": [
+    {
+      "reason": "CONFIG",
+      "total": 1,
+      "succeeded": 1,
+      "failed": 0,
+      "running": 0
+    }
+  ],
+  "failingImages": [],
+  "failingBuilders": [],
+  "stackRebuilds": 0,
+  "buildpackRebuilds": 0
+}
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors with an invalid output format", func() {
+		testhelpers.CommandTest{
+			Args:           []string{"--output", "yaml"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: invalid output format \"yaml\", must be one of table, json, or csv\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
@@ -4,6 +4,8 @@
 package build
 
 import (
+	"bytes"
+	"fmt"
 	"sort"
 	"strconv"
 
@@ -11,17 +13,21 @@ import (
 	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 
 	"github.com/pivotal/build-service-cli/pkg/build"
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 )
 
-func NewStatusCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+func NewStatusCommand(clientSetProvider k8s.ClientSetProvider, newLogTailer func(k8s.ClientSet) LogTailer) *cobra.Command {
 	var (
 		namespace   string
 		buildNumber string
+		logLines    int64
 	)
 
 	cmd := &cobra.Command{
@@ -29,6 +35,9 @@ func NewStatusCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Short: "Display status for an image build",
 		Long: `Prints detailed information about the status of a specific build of an image in the provided namespace.
 
+The status of each lifecycle step is read from the build pod and is only available while the build pod exists.
+For failed builds, the failed step, its last log lines, related warning events, and hints for known failures are displayed.
+
 The build defaults to the latest build number.
 The namespace defaults to the kubernetes current-context namespace.`,
 		Example:      "kp build status my-image\nkp build status my-image -b 2 -n my-namespace",
@@ -55,12 +64,25 @@ The namespace defaults to the kubernetes current-context namespace.`,
 				if err != nil {
 					return err
 				}
-				return displayBuildStatus(cmd, bld)
+				pod, err := getBuildPod(cs, bld)
+				if err != nil {
+					return err
+				}
+				err = displayBuildStatus(cmd, bld, pod)
+				if err != nil {
+					return err
+				}
+
+				if getStatus(bld) != "FAILURE" {
+					return nil
+				}
+				return displayFailureDiagnosis(cmd, cs, newLogTailer(cs), bld, pod, logLines)
 			}
 		},
 	}
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
 	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().Int64Var(&logLines, "log-lines", 20, "number of log lines of the failed step to display, 0 to not display logs")
 
 	return cmd
 }
@@ -90,7 +112,19 @@ func findBuild(buildList *v1alpha1.BuildList, buildNumberString string, img, nam
 	return v1alpha1.Build{}, errors.Errorf("build \"%d\" not found", buildNumber)
 }
 
-func displayBuildStatus(cmd *cobra.Command, bld v1alpha1.Build) error {
+func getBuildPod(cs k8s.ClientSet, bld v1alpha1.Build) (*corev1.Pod, error) {
+	if bld.Status.PodName == "" {
+		return nil, nil
+	}
+
+	pod, err := cs.K8sClient.CoreV1().Pods(cs.Namespace).Get(bld.Status.PodName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return pod, err
+}
+
+func displayBuildStatus(cmd *cobra.Command, bld v1alpha1.Build, pod *corev1.Pod) error {
 	statusWriter := commands.NewStatusWriter(cmd.OutOrStdout())
 
 	statusItems := []string{
@@ -163,6 +197,13 @@ func displayBuildStatus(cmd *cobra.Command, bld v1alpha1.Build) error {
 		return err
 	}
 
+	if pod != nil {
+		err = displayStepsTable(cmd, pod)
+		if err != nil {
+			return err
+		}
+	}
+
 	tableWriter, err := commands.NewTableWriter(cmd.OutOrStdout(), "Buildpack Id", "Buildpack Version")
 	if err != nil {
 		return err
@@ -177,3 +218,157 @@ func displayBuildStatus(cmd *cobra.Command, bld v1alpha1.Build) error {
 
 	return tableWriter.Write()
 }
+
+func displayStepsTable(cmd *cobra.Command, pod *corev1.Pod) error {
+	tableWriter, err := commands.NewTableWriter(cmd.OutOrStdout(), "Step", "Status", "Exit Code", "Duration")
+	if err != nil {
+		return err
+	}
+
+	for _, step := range build.GetStepStatuses(pod) {
+		err := tableWriter.AddRow(step.Name, step.State, step.ExitCodeString(), step.DurationString())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tableWriter.Write()
+}
+
+func displayFailureDiagnosis(cmd *cobra.Command, cs k8s.ClientSet, logTailer LogTailer, bld v1alpha1.Build, pod *corev1.Pod, logLines int64) error {
+	var (
+		step   build.StepStatus
+		failed bool
+		logs   bytes.Buffer
+		texts  []string
+	)
+
+	if pod != nil {
+		step, failed = build.GetFailedStep(pod)
+	}
+
+	if failed {
+		texts = append(texts, step.Reason)
+	}
+
+	var logsErr error
+	if failed && logLines > 0 {
+		logsErr = logTailer.Tail(cmd.Context(), &logs, &bld, build.LogOptions{
+			Steps:     []string{step.Name},
+			TailLines: logLines,
+			NoColor:   true,
+		})
+		texts = append(texts, logs.String())
+	}
+
+	events, err := getWarningEvents(cs, bld)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		texts = append(texts, e.Reason, e.Message)
+	}
+	texts = append(texts, bld.Status.GetCondition(corev1alpha1.ConditionSucceeded).Message)
+
+	hints := build.GetFailureHints(step.Name, texts...)
+
+	out := cmd.OutOrStdout()
+
+	if failed {
+		statusWriter := commands.NewStatusWriter(out)
+		err = statusWriter.AddBlock(
+			"",
+			"Failed Step", step.Name,
+			"Exit Code", step.ExitCodeString(),
+			"Exit Reason", step.Reason,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = statusWriter.Write()
+		if err != nil {
+			return err
+		}
+	}
+
+	if failed && logLines > 0 && logsErr != nil {
+		_, err = fmt.Fprintf(out, "Logs of %s are not available: %s\n\n", step.Name, logsErr)
+		if err != nil {
+			return err
+		}
+	} else if failed && logLines > 0 {
+		_, err = fmt.Fprintf(out, "Last %d log lines of %s:\n%s\n", logLines, step.Name, logs.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(events) > 0 {
+		tableWriter, err := commands.NewTableWriter(out, "Event Reason", "Object", "Message")
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			err := tableWriter.AddRow(e.Reason, e.InvolvedObject.Kind+"/"+e.InvolvedObject.Name, e.Message)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tableWriter.Write()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(hints) > 0 {
+		_, err = fmt.Fprintln(out, "Hints:")
+		if err != nil {
+			return err
+		}
+
+		for _, h := range hints {
+			_, err = fmt.Fprintf(out, "  - %s\n", h)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = fmt.Fprintln(out)
+	}
+	return err
+}
+
+func getWarningEvents(cs k8s.ClientSet, bld v1alpha1.Build) ([]corev1.Event, error) {
+	names := []string{bld.Name}
+	if bld.Status.PodName != "" {
+		names = append(names, bld.Status.PodName)
+	}
+
+	var events []corev1.Event
+	for _, name := range names {
+		eventList, err := cs.K8sClient.CoreV1().Events(cs.Namespace).List(metav1.ListOptions{
+			FieldSelector: fields.AndSelectors(
+				fields.OneTermEqualSelector("involvedObject.name", name),
+				fields.OneTermEqualSelector("type", corev1.EventTypeWarning),
+			).String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range eventList.Items {
+			if e.Type == corev1.EventTypeWarning && e.InvolvedObject.Name == name {
+				events = append(events, e)
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(&events[j].LastTimestamp)
+	})
+	return events, nil
+}
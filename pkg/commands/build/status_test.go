@@ -4,19 +4,26 @@
 package build_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
 	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
-	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8sfakes "k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
 
+	buildpkg "github.com/pivotal/build-service-cli/pkg/build"
+	buildfakes "github.com/pivotal/build-service-cli/pkg/build/fakes"
 	"github.com/pivotal/build-service-cli/pkg/commands/build"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
 )
 
@@ -68,9 +75,22 @@ bp-id-2         bp-version-2
 `
 	)
 
-	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
-		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-		return build.NewStatusCommand(clientSetProvider)
+	var (
+		logTailer *buildfakes.FakeLogTailer
+		k8sClient *k8sfakes.Clientset
+	)
+
+	it.Before(func() {
+		logTailer = &buildfakes.FakeLogTailer{Logs: map[string]string{}}
+	})
+
+	cmdFunc := func(k8sClientSet *k8sfakes.Clientset, kpackClientSet *kpackfakes.Clientset) *cobra.Command {
+		k8sClient = k8sClientSet
+		clientSetProvider := testhelpers.GetFakeK8sAndKpackProvider(k8sClientSet, kpackClientSet, defaultNamespace)
+		newLogTailer := func(k8s.ClientSet) build.LogTailer {
+			return logTailer
+		}
+		return build.NewStatusCommand(clientSetProvider, newLogTailer)
 	}
 
 	when("getting build status", func() {
@@ -79,20 +99,20 @@ bp-id-2         bp-version-2
 				when("the build flag is provided", func() {
 					it("shows the build status", func() {
 						testhelpers.CommandTest{
-							Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+							KpackObjects:   testhelpers.MakeTestBuilds(image, defaultNamespace),
 							Args:           []string{image, "-b", "1"},
 							ExpectedOutput: expectedOutputForBuildNumber,
-						}.TestKpack(t, cmdFunc)
+						}.TestK8sAndKpack(t, cmdFunc)
 					})
 				})
 
 				when("the build flag is not provided", func() {
 					it("shows the build status of the most recent build", func() {
 						testhelpers.CommandTest{
-							Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+							KpackObjects:   testhelpers.MakeTestBuilds(image, defaultNamespace),
 							Args:           []string{image},
 							ExpectedOutput: expectedOutputForMostRecent,
-						}.TestKpack(t, cmdFunc)
+						}.TestK8sAndKpack(t, cmdFunc)
 					})
 				})
 			})
@@ -101,11 +121,11 @@ bp-id-2         bp-version-2
 				when("the build flag is provided", func() {
 					it("prints an appropriate message", func() {
 						testhelpers.CommandTest{
-							Objects:        testhelpers.MakeTestBuilds(image, defaultNamespace),
+							KpackObjects:   testhelpers.MakeTestBuilds(image, defaultNamespace),
 							Args:           []string{image, "-b", "123"},
 							ExpectErr:      true,
 							ExpectedOutput: "Error: build \"123\" not found\n",
-						}.TestKpack(t, cmdFunc)
+						}.TestK8sAndKpack(t, cmdFunc)
 					})
 				})
 
@@ -115,7 +135,7 @@ bp-id-2         bp-version-2
 							Args:           []string{image},
 							ExpectErr:      true,
 							ExpectedOutput: "Error: no builds found\n",
-						}.TestKpack(t, cmdFunc)
+						}.TestK8sAndKpack(t, cmdFunc)
 					})
 				})
 			})
@@ -128,20 +148,20 @@ bp-id-2         bp-version-2
 				when("the build flag is provided", func() {
 					it("gets the build status", func() {
 						testhelpers.CommandTest{
-							Objects:        testhelpers.MakeTestBuilds(image, namespace),
+							KpackObjects:   testhelpers.MakeTestBuilds(image, namespace),
 							Args:           []string{image, "-b", "1", "-n", namespace},
 							ExpectedOutput: expectedOutputForBuildNumber,
-						}.TestKpack(t, cmdFunc)
+						}.TestK8sAndKpack(t, cmdFunc)
 					})
 				})
 
 				when("the build flag is not provided", func() {
 					it("shows the build status of the most recent build", func() {
 						testhelpers.CommandTest{
-							Objects:        testhelpers.MakeTestBuilds(image, namespace),
+							KpackObjects:   testhelpers.MakeTestBuilds(image, namespace),
 							Args:           []string{image, "-n", namespace},
 							ExpectedOutput: expectedOutputForMostRecent,
-						}.TestKpack(t, cmdFunc)
+						}.TestK8sAndKpack(t, cmdFunc)
 					})
 				})
 			})
@@ -150,11 +170,11 @@ bp-id-2         bp-version-2
 				when("the build flag is provided", func() {
 					it("prints an appropriate message", func() {
 						testhelpers.CommandTest{
-							Objects:        testhelpers.MakeTestBuilds(image, namespace),
+							KpackObjects:   testhelpers.MakeTestBuilds(image, namespace),
 							Args:           []string{image, "-b", "123", "-n", namespace},
 							ExpectErr:      true,
 							ExpectedOutput: "Error: build \"123\" not found\n",
-						}.TestKpack(t, cmdFunc)
+						}.TestK8sAndKpack(t, cmdFunc)
 					})
 				})
 
@@ -164,7 +184,7 @@ bp-id-2         bp-version-2
 							Args:           []string{image, "-n", namespace},
 							ExpectErr:      true,
 							ExpectedOutput: "Error: no builds found\n",
-						}.TestKpack(t, cmdFunc)
+						}.TestK8sAndKpack(t, cmdFunc)
 					})
 				})
 			})
@@ -240,11 +260,301 @@ bp-id-2         bp-version-2
 					},
 				}
 				testhelpers.CommandTest{
-					Objects:        []runtime.Object{bld},
+					KpackObjects:   []runtime.Object{bld},
 					Args:           []string{image},
 					ExpectedOutput: expectedOutput,
-				}.TestKpack(t, cmdFunc)
+				}.TestK8sAndKpack(t, cmdFunc)
+			})
+		})
+
+		when("the build pod exists", func() {
+			it("displays the status of each lifecycle step", func() {
+				const expectedOutput = `Image:           repo.com/image-1:tag
+Status:          SUCCESS
+Build Reason:    CONFIG
+
+Started:     0001-01-01 00:00:00
+Finished:    0001-01-01 00:00:00
+
+Pod Name:    pod-one
+
+Builder:      some-repo.com/my-builder
+Run Image:    some-repo.com/run-image
+
+Source:    Local Source
+
+STEP       STATUS       EXIT CODE    DURATION
+prepare    Succeeded    0            2s
+detect     Succeeded    0            1m5s
+build      Failed       51           30s
+export     Skipped      --           --
+
+BUILDPACK ID    BUILDPACK VERSION
+bp-id-1         bp-version-1
+bp-id-2         bp-version-2
+
+`
+				started := time.Date(2020, 8, 1, 12, 0, 0, 0, time.UTC)
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod-one",
+						Namespace: defaultNamespace,
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodFailed,
+						InitContainerStatuses: []corev1.ContainerStatus{
+							terminatedContainer("prepare", 0, started, started.Add(2*time.Second)),
+							terminatedContainer("detect", 0, started.Add(2*time.Second), started.Add(67*time.Second)),
+							terminatedContainer("build", 51, started.Add(67*time.Second), started.Add(97*time.Second)),
+							{
+								Name:  "export",
+								State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}},
+							},
+						},
+					},
+				}
+
+				testhelpers.CommandTest{
+					K8sObjects:     []runtime.Object{pod},
+					KpackObjects:   testhelpers.MakeTestBuilds(image, defaultNamespace),
+					Args:           []string{image, "-b", "1"},
+					ExpectedOutput: expectedOutput,
+				}.TestK8sAndKpack(t, cmdFunc)
+				require.Len(t, logTailer.Calls, 0)
+			})
+		})
+
+		when("the build failed", func() {
+			it("displays a diagnosis of the failure", func() {
+				const expectedOutput = `Image:           repo.com/image-2:tag
+Status:          FAILURE
+Build Reason:    COMMIT,BUILDPACK
+
+Started:     0001-01-01 01:00:00
+Finished:    0001-01-01 00:00:00
+
+Pod Name:    pod-two
+
+Builder:      --
+Run Image:    --
+
+Source:    Local Source
+
+STEP       STATUS       EXIT CODE    DURATION
+prepare    Succeeded    0            2s
+export     Failed       1            5s
+
+BUILDPACK ID    BUILDPACK VERSION
+
+Failed Step:    export
+Exit Code:      1
+Exit Reason:    Error
+
+Last 5 log lines of export:
+ERROR: failed to export: UNAUTHORIZED: authentication required
+
+EVENT REASON    OBJECT         MESSAGE
+FailedMount     Pod/pod-two    secret "missing" not found
+
+Hints:
+  - The service account is missing valid credentials for the image registry. Use 'kp secret create' to add registry credentials.
+  - A volume could not be mounted in the build pod. Check that all secrets referenced by the service account exist.
+
+`
+				started := time.Date(2020, 8, 1, 12, 0, 0, 0, time.UTC)
+				exportStatus := terminatedContainer("export", 1, started.Add(2*time.Second), started.Add(7*time.Second))
+				exportStatus.State.Terminated.Reason = "Error"
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod-two",
+						Namespace: defaultNamespace,
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodFailed,
+						InitContainerStatuses: []corev1.ContainerStatus{
+							terminatedContainer("prepare", 0, started, started.Add(2*time.Second)),
+							exportStatus,
+						},
+					},
+				}
+				podEvent := &corev1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "pod-event", Namespace: defaultNamespace},
+					InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "pod-two"},
+					Type:           corev1.EventTypeWarning,
+					Reason:         "FailedMount",
+					Message:        `secret "missing" not found`,
+				}
+				normalEvent := &corev1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "normal-event", Namespace: defaultNamespace},
+					InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "pod-two"},
+					Type:           corev1.EventTypeNormal,
+					Reason:         "Pulled",
+					Message:        "image pulled",
+				}
+				otherEvent := &corev1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "other-event", Namespace: defaultNamespace},
+					InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "other-pod"},
+					Type:           corev1.EventTypeWarning,
+					Reason:         "BackOff",
+					Message:        "back-off restarting",
+				}
+				logTailer.Logs["build-two"] = "ERROR: failed to export: UNAUTHORIZED: authentication required\n"
+
+				testhelpers.CommandTest{
+					K8sObjects:     []runtime.Object{pod, podEvent, normalEvent, otherEvent},
+					KpackObjects:   testhelpers.MakeTestBuilds(image, defaultNamespace),
+					Args:           []string{image, "-b", "2", "--log-lines", "5"},
+					ExpectedOutput: expectedOutput,
+				}.TestK8sAndKpack(t, cmdFunc)
+
+				require.Len(t, logTailer.Calls, 1)
+				require.Equal(t, buildfakes.LogTailerCall{
+					Build: "build-two",
+					Options: buildpkg.LogOptions{
+						Steps:     []string{"export"},
+						TailLines: 5,
+						NoColor:   true,
+					},
+				}, logTailer.Calls[0])
+
+				var fieldSelectors []string
+				for _, action := range k8sClient.Actions() {
+					if list, ok := action.(clientgotesting.ListAction); ok && action.GetResource().Resource == "events" {
+						fieldSelectors = append(fieldSelectors, list.GetListRestrictions().Fields.String())
+					}
+				}
+				require.Equal(t, []string{
+					"involvedObject.name=build-two,type=Warning",
+					"involvedObject.name=pod-two,type=Warning",
+				}, fieldSelectors)
+			})
+
+			it("displays the diagnosis without logs when the logs are not available", func() {
+				const expectedOutput = `Image:           repo.com/image-2:tag
+Status:          FAILURE
+Build Reason:    COMMIT,BUILDPACK
+
+Started:     0001-01-01 01:00:00
+Finished:    0001-01-01 00:00:00
+
+Pod Name:    pod-two
+
+Builder:      --
+Run Image:    --
+
+Source:    Local Source
+
+STEP      STATUS    EXIT CODE    DURATION
+export    Failed    1            5s
+
+BUILDPACK ID    BUILDPACK VERSION
+
+Failed Step:    export
+Exit Code:      1
+Exit Reason:    Error
+
+Logs of export are not available: pods "pod-two" is forbidden
+
+EVENT REASON    OBJECT         MESSAGE
+FailedMount     Pod/pod-two    secret "missing" not found
+
+Hints:
+  - A volume could not be mounted in the build pod. Check that all secrets referenced by the service account exist.
+
+`
+				started := time.Date(2020, 8, 1, 12, 0, 0, 0, time.UTC)
+				exportStatus := terminatedContainer("export", 1, started.Add(2*time.Second), started.Add(7*time.Second))
+				exportStatus.State.Terminated.Reason = "Error"
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod-two",
+						Namespace: defaultNamespace,
+					},
+					Status: corev1.PodStatus{
+						Phase:                 corev1.PodFailed,
+						InitContainerStatuses: []corev1.ContainerStatus{exportStatus},
+					},
+				}
+				podEvent := &corev1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "pod-event", Namespace: defaultNamespace},
+					InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "pod-two"},
+					Type:           corev1.EventTypeWarning,
+					Reason:         "FailedMount",
+					Message:        `secret "missing" not found`,
+				}
+				logTailer.Errors = map[string]error{"build-two": errors.New(`pods "pod-two" is forbidden`)}
+
+				testhelpers.CommandTest{
+					K8sObjects:     []runtime.Object{pod, podEvent},
+					KpackObjects:   testhelpers.MakeTestBuilds(image, defaultNamespace),
+					Args:           []string{image, "-b", "2"},
+					ExpectedOutput: expectedOutput,
+				}.TestK8sAndKpack(t, cmdFunc)
+
+				require.Len(t, logTailer.Calls, 1)
+			})
+
+			it("does not display logs when no log lines are requested", func() {
+				const expectedOutput = `Image:           repo.com/image-2:tag
+Status:          FAILURE
+Build Reason:    COMMIT,BUILDPACK
+
+Started:     0001-01-01 01:00:00
+Finished:    0001-01-01 00:00:00
+
+Pod Name:    pod-two
+
+Builder:      --
+Run Image:    --
+
+Source:    Local Source
+
+STEP      STATUS    EXIT CODE    DURATION
+export    Failed    1            5s
+
+BUILDPACK ID    BUILDPACK VERSION
+
+Failed Step:    export
+Exit Code:      1
+Exit Reason:    Error
+
+`
+				started := time.Date(2020, 8, 1, 12, 0, 0, 0, time.UTC)
+				exportStatus := terminatedContainer("export", 1, started.Add(2*time.Second), started.Add(7*time.Second))
+				exportStatus.State.Terminated.Reason = "Error"
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod-two",
+						Namespace: defaultNamespace,
+					},
+					Status: corev1.PodStatus{
+						Phase:                 corev1.PodFailed,
+						InitContainerStatuses: []corev1.ContainerStatus{exportStatus},
+					},
+				}
+
+				testhelpers.CommandTest{
+					K8sObjects:     []runtime.Object{pod},
+					KpackObjects:   testhelpers.MakeTestBuilds(image, defaultNamespace),
+					Args:           []string{image, "-b", "2", "--log-lines", "0"},
+					ExpectedOutput: expectedOutput,
+				}.TestK8sAndKpack(t, cmdFunc)
+
+				require.Len(t, logTailer.Calls, 0)
 			})
 		})
 	})
 }
+
+func terminatedContainer(name string, exitCode int32, started, finished time.Time) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name: name,
+		State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{
+				ExitCode:   exitCode,
+				StartedAt:  metav1.Time{Time: started},
+				FinishedAt: metav1.Time{Time: finished},
+			},
+		},
+	}
+}
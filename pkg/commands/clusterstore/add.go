@@ -28,11 +28,17 @@ func NewAddCommand(clientSetProvider k8s.ClientSetProvider, factory *clusterstor
 Buildpackages will be uploaded to the canonical repository.
 Therefore, you must have credentials to access the registry on your machine.
 
+Buildpackages may be images, local .cnb files, or local buildpack directories.
+Buildpack directories, or the path to their package.toml, are packaged into a buildpackage before upload.
+The dependencies in the package.toml are packaged with meta-buildpacks.
+
 The canonical repository is read from the "canonical.repository" key in the "kp-config" ConfigMap within "kpack" namespace.
 `,
 		Example: `kp clusterstore add my-store -b my-registry.com/my-buildpackage
 kp clusterstore add my-store -b my-registry.com/my-buildpackage -b my-registry.com/my-other-buildpackage -b my-registry.com/my-third-buildpackage
-kp clusterstore add my-store -b ../path/to/my-local-buildpackage.cnb`,
+kp clusterstore add my-store -b ../path/to/my-local-buildpackage.cnb
+kp clusterstore add my-store -b ../path/to/my-buildpack/
+kp clusterstore add my-store -b ../path/to/my-buildpack/package.toml`,
 		Args:         commands.ExactArgsWithUsage(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
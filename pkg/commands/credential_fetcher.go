@@ -5,32 +5,128 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
-	"syscall"
+	"strings"
 
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
+const (
+	PasswordStdinFlag = "password-stdin"
+	PasswordFileFlag  = "password-file"
+)
+
 type CredentialFetcher struct {
+	in            io.Reader
+	out           io.Writer
+	passwordStdin bool
+	passwordFile  string
+}
+
+// NewCredentialFetcher returns a CredentialFetcher that reads passwords from the source selected by the
+// password flags of the command and prompts through the writers of the command.
+func NewCredentialFetcher(cmd *cobra.Command) (*CredentialFetcher, error) {
+	passwordStdin, err := GetBoolFlag(PasswordStdinFlag, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordFile, err := GetStringFlag(PasswordFileFlag, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if passwordStdin && passwordFile != "" {
+		return nil, errors.Errorf("only one of --%s or --%s can be used", PasswordStdinFlag, PasswordFileFlag)
+	}
+
+	output, err := GetStringFlag(OutputFlag, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	out := cmd.OutOrStdout()
+	if output != "" {
+		out = cmd.ErrOrStderr()
+	}
+
+	return &CredentialFetcher{
+		in:            cmd.InOrStdin(),
+		out:           out,
+		passwordStdin: passwordStdin,
+		passwordFile:  passwordFile,
+	}, nil
+}
+
+func SetPasswordFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(PasswordStdinFlag, false, "read the password from stdin")
+	cmd.Flags().String(PasswordFileFlag, "", "path to a file containing the password")
 }
 
 func (c CredentialFetcher) FetchPassword(envVar, prompt string) (string, error) {
+	if c.passwordFile != "" {
+		buf, err := ioutil.ReadFile(c.passwordFile)
+		if err != nil {
+			return "", err
+		}
+		return nonEmptyPassword(buf, c.passwordFile)
+	}
+
+	if c.passwordStdin {
+		buf, err := ioutil.ReadAll(c.reader())
+		if err != nil {
+			return "", err
+		}
+		return nonEmptyPassword(buf, "stdin")
+	}
+
 	password, ok := os.LookupEnv(envVar)
 	if ok {
 		return password, nil
 	}
 
-	_, err := fmt.Fprint(os.Stdout, prompt)
+	stdin, ok := c.reader().(*os.File)
+	if !ok || !terminal.IsTerminal(int(stdin.Fd())) {
+		return "", errors.Errorf("cannot prompt for password, stdin is not a terminal. use --%s, --%s, or the %s env var", PasswordStdinFlag, PasswordFileFlag, envVar)
+	}
+
+	_, err := fmt.Fprint(c.writer(), prompt)
 	if err != nil {
 		return "", err
 	}
 
-	pwBytes, err := terminal.ReadPassword(int(syscall.Stdin))
+	pwBytes, err := terminal.ReadPassword(int(stdin.Fd()))
 	if err != nil {
 		return "", err
 	}
 
-	_, _ = fmt.Fprintln(os.Stdout, "")
+	_, _ = fmt.Fprintln(c.writer(), "")
 
 	return string(pwBytes), nil
 }
+
+func (c CredentialFetcher) reader() io.Reader {
+	if c.in == nil {
+		return os.Stdin
+	}
+	return c.in
+}
+
+func (c CredentialFetcher) writer() io.Writer {
+	if c.out == nil {
+		return os.Stdout
+	}
+	return c.out
+}
+
+func nonEmptyPassword(buf []byte, source string) (string, error) {
+	password := strings.TrimRight(string(buf), "\r\n")
+	if password == "" {
+		return "", errors.Errorf("no password found in %s", source)
+	}
+	return password, nil
+}
@@ -4,10 +4,14 @@
 package commands_test
 
 import (
+	"bytes"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 
 	"github.com/pivotal/build-service-cli/pkg/commands"
@@ -35,4 +39,72 @@ func testCredentialFetcher(t *testing.T, when spec.G, it spec.S) {
 			require.Equal(t, "some-password-value", password)
 		})
 	})
+
+	when("the password flags are used", func() {
+		fetch := func(stdin string, args ...string) (string, error) {
+			var password string
+			cmd := &cobra.Command{
+				RunE: func(cmd *cobra.Command, _ []string) error {
+					fetcher, err := commands.NewCredentialFetcher(cmd)
+					if err != nil {
+						return err
+					}
+
+					password, err = fetcher.FetchPassword("SOME_UNSET_TEST_ENV_VAR", "password: ")
+					return err
+				},
+				SilenceErrors: true,
+				SilenceUsage:  true,
+			}
+			commands.SetPasswordFlags(cmd)
+			cmd.SetArgs(args)
+			cmd.SetIn(bytes.NewBufferString(stdin))
+			cmd.SetOut(&bytes.Buffer{})
+
+			err := cmd.Execute()
+			return password, err
+		}
+
+		it("reads the password from stdin", func() {
+			password, err := fetch("some-password\n", "--password-stdin")
+			require.NoError(t, err)
+			require.Equal(t, "some-password", password)
+		})
+
+		it("reads the password from a file", func() {
+			dir, err := ioutil.TempDir("", "credential-fetcher-test")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			passwordFile := filepath.Join(dir, "password")
+			require.NoError(t, ioutil.WriteFile(passwordFile, []byte("some-password\r\n"), 0600))
+
+			password, err := fetch("", "--password-file", passwordFile)
+			require.NoError(t, err)
+			require.Equal(t, "some-password", password)
+		})
+
+		it("errors when stdin is empty", func() {
+			_, err := fetch("", "--password-stdin")
+			require.EqualError(t, err, "no password found in stdin")
+		})
+
+		it("errors when both flags are used", func() {
+			_, err := fetch("some-password", "--password-stdin", "--password-file", "some-file")
+			require.EqualError(t, err, "only one of --password-stdin or --password-file can be used")
+		})
+	})
+
+	when("no password is provided and stdin is not a terminal", func() {
+		it("returns an error instead of prompting", func() {
+			cmd := &cobra.Command{}
+			cmd.SetIn(bytes.NewBufferString("some-password"))
+
+			fetcher, err := commands.NewCredentialFetcher(cmd)
+			require.NoError(t, err)
+
+			_, err = fetcher.FetchPassword("SOME_UNSET_TEST_ENV_VAR", "password: ")
+			require.EqualError(t, err, "cannot prompt for password, stdin is not a terminal. use --password-stdin, --password-file, or the SOME_UNSET_TEST_ENV_VAR env var")
+		})
+	})
 }
@@ -0,0 +1,225 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/image"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+func NewCloneCommand(clientSetProvider k8s.ClientSetProvider, factory *image.Factory, newImageWaiter func(k8s.ClientSet) ImageWaiter) *cobra.Command {
+	var (
+		tag         string
+		namespace   string
+		toNamespace string
+		subPath     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "clone <source-name> <name> --tag <tag>",
+		Short: "Create an image configuration from an existing image",
+		Long: `Create an image configuration by copying the configuration of an existing image in the provided namespace.
+The new image uses the provided tag and is created in the namespace provided with "--to-namespace", or in the namespace of the existing image.
+
+The builder, service account, source, cache size, environment variables and labels of the existing image are copied.
+The same flags as "kp image create" may be used to override the source, builder, environment variables or cache size.
+
+When cloning to another namespace, a warning is printed for each namespaced builder, service account and secret
+referenced by the image that does not exist in the target namespace.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example: `kp image clone my-image my-new-image --tag my-registry.com/my-new-repo
+kp image clone my-image my-new-image --tag my-registry.com/my-new-repo --git https://my-repo.com/my-new-app.git
+kp image clone my-image my-image --tag my-registry.com/my-repo-staging -n my-namespace --to-namespace my-staging-namespace`,
+		Args:         commands.ExactArgsWithUsage(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			src, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			targetNamespace := cs.Namespace
+			if toNamespace != "" {
+				targetNamespace = toNamespace
+			}
+
+			factory.Printer = ch
+			factory.SubPath = nil
+			if cmd.Flags().Changed("sub-path") {
+				factory.SubPath = &subPath
+			}
+
+			img, err := factory.MakeClone(src, args[1], targetNamespace, tag)
+			if err != nil {
+				return err
+			}
+
+			if targetNamespace != cs.Namespace {
+				if err := warnMissingReferences(ch, cs, src, img); err != nil {
+					return err
+				}
+			}
+
+			k8s.SetLastAppliedCfg(img)
+
+			if !ch.IsDryRun() {
+				img, err = cs.KpackClient.KpackV1alpha1().Images(img.Namespace).Create(img)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := ch.PrintObj(img); err != nil {
+				return err
+			}
+
+			if err := ch.PrintResult("Image %q created", img.Name); err != nil {
+				return err
+			}
+
+			if ch.ShouldWait() {
+				_, err := newImageWaiter(cs).Wait(cmd.Context(), cmd.OutOrStdout(), img)
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&tag, "tag", "t", "", "registry location where the image will be created")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace of the existing image")
+	cmd.Flags().StringVar(&toNamespace, "to-namespace", "", "kubernetes namespace of the new image (default to the namespace of the existing image)")
+	cmd.Flags().StringVar(&factory.GitRepo, "git", "", "git repository url")
+	cmd.Flags().StringVar(&factory.GitRevision, "git-revision", "", "git revision (default \"master\")")
+	cmd.Flags().StringVar(&factory.Blob, "blob", "", "source code blob url")
+	cmd.Flags().StringVar(&factory.LocalPath, "local-path", "", "path to local source code")
+	cmd.Flags().StringVar(&subPath, "sub-path", "", "build code at the sub path located within the source code directory")
+	cmd.Flags().StringVarP(&factory.Builder, "builder", "b", "", "builder name")
+	cmd.Flags().StringVarP(&factory.ClusterBuilder, "cluster-builder", "c", "", "cluster builder name")
+	cmd.Flags().StringArrayVar(&factory.Env, "env", []string{}, "build time environment variables")
+	cmd.Flags().StringVar(&factory.CacheSize, "cache-size", "", "cache size as a kubernetes quantity")
+	cmd.Flags().BoolP("wait", "w", false, "wait for image create to be reconciled and tail resulting build logs")
+	commands.SetDryRunOutputFlags(cmd)
+	commands.SetTLSFlags(cmd, &factory.TLSConfig)
+	_ = cmd.MarkFlagRequired("tag")
+	return cmd
+}
+
+// warnMissingReferences prints a warning for each namespaced resource referenced by the cloned image
+// that does not exist in its namespace.
+func warnMissingReferences(ch *commands.CommandHelper, cs k8s.ClientSet, src, img *v1alpha1.Image) error {
+	if img.Spec.Builder.Kind == v1alpha1.BuilderKind {
+		_, err := cs.KpackClient.KpackV1alpha1().Builders(img.Namespace).Get(img.Spec.Builder.Name, metav1.GetOptions{})
+		if err := warnIfNotFound(ch, err, "Builder", img.Spec.Builder.Name, img.Namespace); err != nil {
+			return err
+		}
+	}
+
+	serviceAccount := img.Spec.ServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+
+	_, err := cs.K8sClient.CoreV1().ServiceAccounts(img.Namespace).Get(serviceAccount, metav1.GetOptions{})
+	if err := warnIfNotFound(ch, err, "Service Account", serviceAccount, img.Namespace); err != nil {
+		return err
+	}
+
+	secrets, err := referencedSecrets(cs, src)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range secrets {
+		_, err := cs.K8sClient.CoreV1().Secrets(img.Namespace).Get(secret, metav1.GetOptions{})
+		if err := warnIfNotFound(ch, err, "Secret", secret, img.Namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func warnIfNotFound(ch *commands.CommandHelper, err error, kind, name, namespace string) error {
+	if k8serrors.IsNotFound(err) {
+		return ch.Printlnf("Warning: %s %q does not exist in namespace %q", kind, name, namespace)
+	}
+	return err
+}
+
+// referencedSecrets returns the names of the secrets of the service account, source and bindings of an image,
+// excluding service account tokens.
+func referencedSecrets(cs k8s.ClientSet, img *v1alpha1.Image) ([]string, error) {
+	var secrets []string
+	add := func(name string) {
+		if name != "" && !contains(secrets, name) {
+			secrets = append(secrets, name)
+		}
+	}
+
+	serviceAccount := img.Spec.ServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+
+	sa, err := cs.K8sClient.CoreV1().ServiceAccounts(img.Namespace).Get(serviceAccount, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, err
+	} else if err == nil {
+		for _, ref := range sa.Secrets {
+			secret, err := cs.K8sClient.CoreV1().Secrets(img.Namespace).Get(ref.Name, metav1.GetOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return nil, err
+			}
+
+			// service account tokens are generated in the target namespace
+			if err == nil && secret.Type != corev1.SecretTypeServiceAccountToken {
+				add(ref.Name)
+			}
+		}
+		for _, ref := range sa.ImagePullSecrets {
+			add(ref.Name)
+		}
+	}
+
+	if img.Spec.Source.Registry != nil {
+		for _, ref := range img.Spec.Source.Registry.ImagePullSecrets {
+			add(ref.Name)
+		}
+	}
+
+	if img.Spec.Build != nil {
+		for _, binding := range img.Spec.Build.Bindings {
+			if binding.SecretRef != nil {
+				add(binding.SecretRef.Name)
+			}
+		}
+	}
+
+	return secrets, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	kpackfakes "github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfakes "k8s.io/client-go/kubernetes/fake"
+
+	imgcmds "github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/image"
+	"github.com/pivotal/build-service-cli/pkg/image/fakes"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	srcfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImageCloneCommand(t *testing.T) {
+	spec.Run(t, "TestImageCloneCommand", testImageCloneCommand)
+}
+
+func testImageCloneCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		targetNamespace  = "some-target-namespace"
+	)
+
+	imageFactory := &image.Factory{
+		SourceUploader: &srcfakes.SourceUploader{
+			ImageRef: "some-registry.io/some-repo-source:source-id",
+		},
+	}
+
+	cmdFunc := func(k8sClientSet *k8sfakes.Clientset, kpackClientSet *kpackfakes.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeK8sAndKpackProvider(k8sClientSet, kpackClientSet, defaultNamespace)
+		return imgcmds.NewCloneCommand(clientSetProvider, imageFactory, func(set k8s.ClientSet) imgcmds.ImageWaiter {
+			return &fakes.FakeImageWaiter{}
+		})
+	}
+
+	cacheSize := resource.MustParse("2G")
+
+	sourceImage := &v1alpha1.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "some-image",
+			Namespace:       defaultNamespace,
+			ResourceVersion: "12",
+			UID:             "some-uid",
+			Labels:          map[string]string{"team": "some-team"},
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"kpack.io/rollback": "{}",
+			},
+		},
+		Spec: v1alpha1.ImageSpec{
+			Tag: "some-registry.io/some-repo",
+			Builder: corev1.ObjectReference{
+				Kind:      v1alpha1.BuilderKind,
+				Namespace: defaultNamespace,
+				Name:      "some-builder",
+			},
+			ServiceAccount: "default",
+			Source: v1alpha1.SourceConfig{
+				Git: &v1alpha1.Git{
+					URL:      "some-git-url",
+					Revision: "some-git-rev",
+				},
+				SubPath: "some-sub-path",
+			},
+			CacheSize: &cacheSize,
+			Build: &v1alpha1.ImageBuild{
+				Env: []corev1.EnvVar{
+					{Name: "some-key", Value: "some-val"},
+				},
+			},
+		},
+		Status: v1alpha1.ImageStatus{
+			Status: corev1alpha1.Status{
+				ObservedGeneration: 2,
+			},
+			LatestBuildRef: "some-build",
+		},
+	}
+
+	makeClone := func(namespace string, modify func(img *v1alpha1.Image)) *v1alpha1.Image {
+		img := &v1alpha1.Image{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Image",
+				APIVersion: "kpack.io/v1alpha1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-new-image",
+				Namespace: namespace,
+				Labels:    map[string]string{"team": "some-team"},
+			},
+			Spec: *sourceImage.Spec.DeepCopy(),
+		}
+		img.Spec.Tag = "some-registry.io/some-new-repo"
+		if img.Spec.Builder.Kind == v1alpha1.BuilderKind {
+			img.Spec.Builder.Namespace = namespace
+		}
+		if modify != nil {
+			modify(img)
+		}
+		k8s.SetLastAppliedCfg(img)
+		return img
+	}
+
+	when("cloning within the namespace", func() {
+		it("creates a copy of the image with the new tag", func() {
+			testhelpers.CommandTest{
+				KpackObjects: []runtime.Object{
+					sourceImage,
+				},
+				Args: []string{
+					"some-image",
+					"some-new-image",
+					"--tag", "some-registry.io/some-new-repo",
+				},
+				ExpectedOutput: "Image \"some-new-image\" created\n",
+				ExpectCreates: []runtime.Object{
+					makeClone(defaultNamespace, nil),
+				},
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+
+		it("overrides the copied configuration with the provided flags", func() {
+			testhelpers.CommandTest{
+				KpackObjects: []runtime.Object{
+					sourceImage,
+				},
+				Args: []string{
+					"some-image",
+					"some-new-image",
+					"--tag", "some-registry.io/some-new-repo",
+					"--blob", "some-blob",
+					"--sub-path", "",
+					"--cluster-builder", "some-cluster-builder",
+					"--env", "some-key=some-other-val",
+					"--env", "other-key=other-val",
+					"--cache-size", "1G",
+				},
+				ExpectedOutput: "Image \"some-new-image\" created\n",
+				ExpectCreates: []runtime.Object{
+					makeClone(defaultNamespace, func(img *v1alpha1.Image) {
+						smallerCache := resource.MustParse("1G")
+						img.Spec.CacheSize = &smallerCache
+						img.Spec.Source = v1alpha1.SourceConfig{
+							Blob: &v1alpha1.Blob{URL: "some-blob"},
+						}
+						img.Spec.Builder = corev1.ObjectReference{
+							Kind: v1alpha1.ClusterBuilderKind,
+							Name: "some-cluster-builder",
+						}
+						img.Spec.Build.Env = []corev1.EnvVar{
+							{Name: "some-key", Value: "some-other-val"},
+							{Name: "other-key", Value: "other-val"},
+						}
+					}),
+				},
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+
+		it("does not create the image on dry run", func() {
+			testhelpers.CommandTest{
+				KpackObjects: []runtime.Object{
+					sourceImage,
+				},
+				Args: []string{
+					"some-image",
+					"some-new-image",
+					"--tag", "some-registry.io/some-new-repo",
+					"--dry-run",
+				},
+				ExpectedOutput: "Image \"some-new-image\" created (dry run)\n",
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+	})
+
+	when("cloning to another namespace", func() {
+		serviceAccount := func(namespace string, secrets ...string) *corev1.ServiceAccount {
+			sa := &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default",
+					Namespace: namespace,
+				},
+			}
+			for _, s := range secrets {
+				sa.Secrets = append(sa.Secrets, corev1.ObjectReference{Name: s})
+			}
+			return sa
+		}
+
+		secret := func(name, namespace string, secretType corev1.SecretType) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Type: secretType,
+			}
+		}
+
+		it("warns about referenced resources missing in the target namespace", func() {
+			testhelpers.CommandTest{
+				K8sObjects: []runtime.Object{
+					serviceAccount(defaultNamespace, "default-token-abcde", "some-registry-secret", "some-git-secret"),
+					secret("default-token-abcde", defaultNamespace, corev1.SecretTypeServiceAccountToken),
+					secret("some-registry-secret", defaultNamespace, corev1.SecretTypeDockerConfigJson),
+					secret("some-git-secret", defaultNamespace, corev1.SecretTypeBasicAuth),
+					serviceAccount(targetNamespace),
+					secret("some-git-secret", targetNamespace, corev1.SecretTypeBasicAuth),
+				},
+				KpackObjects: []runtime.Object{
+					sourceImage,
+				},
+				Args: []string{
+					"some-image",
+					"some-new-image",
+					"--tag", "some-registry.io/some-new-repo",
+					"--to-namespace", targetNamespace,
+				},
+				ExpectedOutput: `Warning: Builder "some-builder" does not exist in namespace "some-target-namespace"
+Warning: Secret "some-registry-secret" does not exist in namespace "some-target-namespace"
+Image "some-new-image" created
+`,
+				ExpectCreates: []runtime.Object{
+					makeClone(targetNamespace, nil),
+				},
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+
+		it("warns when the service account is missing in the target namespace", func() {
+			testhelpers.CommandTest{
+				KpackObjects: []runtime.Object{
+					sourceImage,
+					&v1alpha1.Builder{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "some-builder",
+							Namespace: targetNamespace,
+						},
+					},
+				},
+				Args: []string{
+					"some-image",
+					"some-new-image",
+					"--tag", "some-registry.io/some-new-repo",
+					"--to-namespace", targetNamespace,
+				},
+				ExpectedOutput: `Warning: Service Account "default" does not exist in namespace "some-target-namespace"
+Image "some-new-image" created
+`,
+				ExpectCreates: []runtime.Object{
+					makeClone(targetNamespace, nil),
+				},
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+	})
+
+	when("the source image does not exist", func() {
+		it("returns an error", func() {
+			testhelpers.CommandTest{
+				Args: []string{
+					"some-image",
+					"some-new-image",
+					"--tag", "some-registry.io/some-new-repo",
+				},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: images.kpack.io \"some-image\" not found\n",
+			}.TestK8sAndKpack(t, cmdFunc)
+		})
+	})
+}
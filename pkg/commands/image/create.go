@@ -12,13 +12,18 @@ import (
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 )
 
-func NewCreateCommand(clientSetProvider k8s.ClientSetProvider, factory *image.Factory, newImageWaiter func(k8s.ClientSet) ImageWaiter) *cobra.Command {
+func NewCreateCommand(clientSetProvider k8s.ClientSetProvider, factory *image.Factory, newImageWaiter func(k8s.ClientSet) ImageWaiter, newCredentialChecker func(k8s.ClientSet) CredentialChecker, credentialHandOff CredentialHandOff) *cobra.Command {
 	var (
 		tag       string
 		namespace string
 		subPath   string
 	)
 
+	check := credentialCheck{
+		newCredentialChecker: newCredentialChecker,
+		handOff:              credentialHandOff,
+	}
+
 	cmd := &cobra.Command{
 		Use:   "create <name> --tag <tag>",
 		Short: "Create an image configuration",
@@ -37,6 +42,15 @@ Local source code will be pushed to the same registry provided for the image tag
 Therefore, you must have credentials to access the registry on your machine.
 --registry-ca-cert-path and --registry-verify-certs are only used for local source type.
 
+The service account of the image is checked for credentials for the registry of the image tag
+and for the git repository, unless the repository can be read without credentials.
+Whether an https git repository can be read without credentials is checked with an anonymous request
+that times out after 10 seconds. Repositories that cannot be reached are assumed to be public.
+The git repository is not checked with "--dry-run".
+Use "--skip-credential-check" to skip the check, for example when working offline.
+Missing credentials are reported as warnings, or as an error with "--require-credentials".
+When stdin is a terminal, creating the missing secrets is offered.
+
 Environment variables may be provided by using the "--env" flag.
 For each environment variable, supply the "--env" flag followed by the key value pair.
 For example, "--env key1=value1 --env key2=value2 ...".`,
@@ -63,7 +77,7 @@ kp image create my-image --tag my-registry.com/my-repo --blob https://my-blob-ho
 			factory.Printer = ch
 			factory.SubPath = &subPath
 
-			img, err := create(name, tag, factory, ch, cs)
+			img, err := create(cmd, name, tag, factory, check, ch, cs)
 			if err != nil {
 				return err
 			}
@@ -89,18 +103,23 @@ kp image create my-image --tag my-registry.com/my-repo --blob https://my-blob-ho
 	cmd.Flags().StringArrayVar(&factory.Env, "env", []string{}, "build time environment variables")
 	cmd.Flags().StringVar(&factory.CacheSize, "cache-size", "", "cache size as a kubernetes quantity (default \"2G\")")
 	cmd.Flags().BoolP("wait", "w", false, "wait for image create to be reconciled and tail resulting build logs")
+	setCredentialCheckFlags(cmd, &check)
 	commands.SetDryRunOutputFlags(cmd)
 	commands.SetTLSFlags(cmd, &factory.TLSConfig)
 	_ = cmd.MarkFlagRequired("tag")
 	return cmd
 }
 
-func create(name, tag string, factory *image.Factory, ch *commands.CommandHelper, cs k8s.ClientSet) (*v1alpha1.Image, error) {
+func create(cmd *cobra.Command, name, tag string, factory *image.Factory, check credentialCheck, ch *commands.CommandHelper, cs k8s.ClientSet) (*v1alpha1.Image, error) {
 	img, err := factory.MakeImage(name, cs.Namespace, tag)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = check.run(cmd, ch, cs, img, nil); err != nil {
+		return nil, err
+	}
+
 	k8s.SetLastAppliedCfg(img)
 	if err != nil {
 		return nil, err
@@ -4,6 +4,7 @@
 package image_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
@@ -11,6 +12,7 @@ import (
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,12 +41,16 @@ func testImageCreateCommand(t *testing.T, when spec.G, it spec.S) {
 		SourceUploader: sourceUploader,
 	}
 	fakeImageWaiter := &fakes.FakeImageWaiter{}
+	fakeCredentialChecker := &fakes.FakeCredentialChecker{}
+	fakeCredentialHandOff := &fakes.FakeCredentialHandOff{}
 
 	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
 		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 		return imgcmds.NewCreateCommand(clientSetProvider, imageFactory, func(set k8s.ClientSet) imgcmds.ImageWaiter {
 			return fakeImageWaiter
-		})
+		}, func(set k8s.ClientSet) imgcmds.CredentialChecker {
+			return fakeCredentialChecker
+		}, fakeCredentialHandOff)
 	}
 
 	when("a namespace is provided", func() {
@@ -615,4 +621,170 @@ status: {}
 			})
 		})
 	})
+
+	when("the service account is missing credentials", func() {
+		expectedImage := &v1alpha1.Image{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Image",
+				APIVersion: "kpack.io/v1alpha1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-image",
+				Namespace: defaultNamespace,
+				Annotations: map[string]string{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"kind":"Image","apiVersion":"kpack.io/v1alpha1","metadata":{"name":"some-image","namespace":"some-default-namespace","creationTimestamp":null},"spec":{"tag":"some-registry.io/some-repo","builder":{"kind":"ClusterBuilder","name":"default"},"serviceAccount":"default","source":{"git":{"url":"git@github.com:some-org/some-repo.git","revision":"master"}},"build":{"resources":{}}},"status":{}}`,
+				},
+			},
+			Spec: v1alpha1.ImageSpec{
+				Tag: "some-registry.io/some-repo",
+				Builder: corev1.ObjectReference{
+					Kind: v1alpha1.ClusterBuilderKind,
+					Name: "default",
+				},
+				ServiceAccount: "default",
+				Source: v1alpha1.SourceConfig{
+					Git: &v1alpha1.Git{
+						URL:      "git@github.com:some-org/some-repo.git",
+						Revision: "master",
+					},
+				},
+				Build: &v1alpha1.ImageBuild{},
+			},
+		}
+
+		it.Before(func() {
+			fakeCredentialChecker.Missing = []image.MissingCredential{
+				{Type: image.RegistryCredential, Target: "some-registry.io"},
+				{Type: image.GitCredential, Target: "git@github.com:some-org/some-repo.git"},
+			}
+		})
+
+		it.After(func() {
+			fakeCredentialChecker.Missing = nil
+			fakeCredentialChecker.Calls = nil
+			fakeCredentialHandOff.Create = false
+			fakeCredentialHandOff.Output = ""
+			fakeCredentialHandOff.Offers = nil
+		})
+
+		it("warns and suggests how to create the credentials", func() {
+			testhelpers.CommandTest{
+				Args: []string{
+					"some-image",
+					"--tag", "some-registry.io/some-repo",
+					"--git", "git@github.com:some-org/some-repo.git",
+				},
+				ExpectedOutput: `Warning: service account "default" has no credentials for registry "some-registry.io"
+Create them with: kp secret create some-registry-io-registry -n some-default-namespace --registry some-registry.io --registry-user <registry-user>
+Warning: service account "default" has no credentials for git "git@github.com:some-org/some-repo.git"
+Create them with: kp secret create github-com-git -n some-default-namespace --git-url git@github.com --git-ssh-key <git-ssh-key-path>
+Image "some-image" created
+`,
+				ExpectCreates: []runtime.Object{
+					expectedImage,
+				},
+			}.TestKpack(t, cmdFunc)
+
+			assert.Len(t, fakeCredentialHandOff.Offers, 2)
+		})
+
+		it("does not suggest credentials that were created by the hand off", func() {
+			fakeCredentialHandOff.Create = true
+
+			testhelpers.CommandTest{
+				Args: []string{
+					"some-image",
+					"--tag", "some-registry.io/some-repo",
+					"--git", "git@github.com:some-org/some-repo.git",
+					"--require-credentials",
+				},
+				ExpectedOutput: `Warning: service account "default" has no credentials for registry "some-registry.io"
+Warning: service account "default" has no credentials for git "git@github.com:some-org/some-repo.git"
+Image "some-image" created
+`,
+				ExpectCreates: []runtime.Object{
+					expectedImage,
+				},
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("writes the output of the hand off to stderr when the image is printed", func() {
+			fakeCredentialHandOff.Create = true
+			fakeCredentialHandOff.Output = "Secret created\n"
+
+			cmd := cmdFunc(fake.NewSimpleClientset())
+			cmd.SetArgs([]string{
+				"some-image",
+				"--tag", "some-registry.io/some-repo",
+				"--git", "git@github.com:some-org/some-repo.git",
+				"--output", "yaml",
+			})
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			cmd.SetOut(out)
+			cmd.SetErr(errOut)
+
+			require.NoError(t, cmd.Execute())
+			assert.NotContains(t, out.String(), "Secret created")
+			assert.Contains(t, out.String(), "kind: Image")
+			assert.Equal(t, `Warning: service account "default" has no credentials for registry "some-registry.io"
+Secret created
+Warning: service account "default" has no credentials for git "git@github.com:some-org/some-repo.git"
+Secret created
+`, errOut.String())
+		})
+
+		it("fails without creating the image when credentials are required", func() {
+			testhelpers.CommandTest{
+				Args: []string{
+					"some-image",
+					"--tag", "some-registry.io/some-repo",
+					"--git", "git@github.com:some-org/some-repo.git",
+					"--require-credentials",
+				},
+				ExpectErr: true,
+				ExpectedOutput: `Warning: service account "default" has no credentials for registry "some-registry.io"
+Create them with: kp secret create some-registry-io-registry -n some-default-namespace --registry some-registry.io --registry-user <registry-user>
+Warning: service account "default" has no credentials for git "git@github.com:some-org/some-repo.git"
+Create them with: kp secret create github-com-git -n some-default-namespace --git-url git@github.com --git-ssh-key <git-ssh-key-path>
+Error: service account "default" is missing credentials required by the image
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+
+		it("does not offer to create credentials or check the git repository for dry runs", func() {
+			fakeCredentialChecker.Missing = fakeCredentialChecker.Missing[:1]
+
+			testhelpers.CommandTest{
+				Args: []string{
+					"some-image",
+					"--tag", "some-registry.io/some-repo",
+					"--git", "https://github.com/some-org/some-repo",
+					"--dry-run",
+				},
+				ExpectedOutput: `Warning: service account "default" has no credentials for registry "some-registry.io"
+Create them with: kp secret create some-registry-io-registry -n some-default-namespace --registry some-registry.io --registry-user <registry-user>
+Image "some-image" created (dry run)
+`,
+			}.TestKpack(t, cmdFunc)
+
+			assert.Len(t, fakeCredentialHandOff.Offers, 0)
+			require.Len(t, fakeCredentialChecker.Calls, 1)
+			assert.Nil(t, fakeCredentialChecker.Calls[0].Spec.Source.Git)
+			assert.Equal(t, "some-registry.io/some-repo", fakeCredentialChecker.Calls[0].Spec.Tag)
+		})
+
+		it("skips the check with --skip-credential-check", func() {
+			testhelpers.CommandTest{
+				Args: []string{
+					"some-image",
+					"--tag", "some-registry.io/some-repo",
+					"--git", "git@github.com:some-org/some-repo.git",
+					"--skip-credential-check",
+					"--dry-run",
+				},
+				ExpectedOutput: `Image "some-image" created (dry run)
+`,
+			}.TestKpack(t, cmdFunc)
+		})
+	})
 }
@@ -0,0 +1,154 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/image"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+type CredentialChecker interface {
+	MissingCredentials(img *v1alpha1.Image) ([]image.MissingCredential, error)
+}
+
+type CredentialHandOff interface {
+	// Offer offers to create a missing credential, writing the result of the creation to out, and reports whether it was created.
+	Offer(cmd *cobra.Command, out io.Writer, img *v1alpha1.Image, missing image.MissingCredential) (bool, error)
+}
+
+type credentialCheck struct {
+	newCredentialChecker func(k8s.ClientSet) CredentialChecker
+	handOff              CredentialHandOff
+	require              bool
+	skip                 bool
+}
+
+func setCredentialCheckFlags(cmd *cobra.Command, check *credentialCheck) {
+	cmd.Flags().BoolVar(&check.require, "require-credentials", false, "fail if the service account has no credentials for the image tag or git repository")
+	cmd.Flags().BoolVar(&check.skip, "skip-credential-check", false, "do not check the service account for credentials for the image tag or git repository")
+}
+
+// run warns about or, with --require-credentials, fails on the credentials an image needs that its service account does not have.
+// Because checking the git repository may probe it over the network, it is not checked in dry runs
+// and is only checked when it differs from the one of the previous image, which is nil for new images.
+// Outside of dry runs, creating each missing credential is offered first.
+func (c credentialCheck) run(cmd *cobra.Command, ch *commands.CommandHelper, cs k8s.ClientSet, img, previous *v1alpha1.Image) error {
+	if c.skip {
+		return nil
+	}
+
+	checked := img
+	if img.Spec.Source.Git != nil && (ch.IsDryRun() || previous != nil && previous.Spec.Source.Git != nil && previous.Spec.Source.Git.URL == img.Spec.Source.Git.URL) {
+		checked = img.DeepCopy()
+		checked.Spec.Source.Git = nil
+	}
+
+	missing, err := c.newCredentialChecker(cs).MissingCredentials(checked)
+	if err != nil {
+		return err
+	}
+
+	var unresolved int
+	for _, m := range missing {
+		if err := ch.Printlnf("Warning: service account %q has no credentials for %s %q", img.Spec.ServiceAccount, m.Type, m.Target); err != nil {
+			return err
+		}
+
+		if !ch.IsDryRun() {
+			created, err := c.handOff.Offer(cmd, ch.OutOrErrWriter(), img, m)
+			if err != nil {
+				return err
+			} else if created {
+				continue
+			}
+		}
+
+		unresolved++
+		if err := ch.Printlnf("Create them with: kp secret create %s", strings.Join(secretCreateArgs(img, m), " ")); err != nil {
+			return err
+		}
+	}
+
+	if unresolved > 0 && c.require {
+		return errors.Errorf("service account %q is missing credentials required by the image", img.Spec.ServiceAccount)
+	}
+	return nil
+}
+
+func secretCreateArgs(img *v1alpha1.Image, missing image.MissingCredential) []string {
+	args := []string{missing.SecretName(), "-n", img.Namespace}
+	if img.Spec.ServiceAccount != "" && img.Spec.ServiceAccount != "default" {
+		args = append(args, "--service-account", img.Spec.ServiceAccount)
+	}
+	return append(args, missing.SecretCreateFlags()...)
+}
+
+type secretCreateHandOff struct {
+	confirmationProvider   ConfirmationProvider
+	newSecretCreateCommand func() *cobra.Command
+}
+
+// NewSecretCreateHandOff offers to run "kp secret create" for missing credentials when stdin is a terminal,
+// prompting for the values that cannot be derived from the image.
+func NewSecretCreateHandOff(confirmationProvider ConfirmationProvider, newSecretCreateCommand func() *cobra.Command) CredentialHandOff {
+	return secretCreateHandOff{
+		confirmationProvider:   confirmationProvider,
+		newSecretCreateCommand: newSecretCreateCommand,
+	}
+}
+
+func (h secretCreateHandOff) Offer(cmd *cobra.Command, out io.Writer, img *v1alpha1.Image, missing image.MissingCredential) (bool, error) {
+	stdin, ok := cmd.InOrStdin().(*os.File)
+	if !ok || !terminal.IsTerminal(int(stdin.Fd())) {
+		return false, nil
+	}
+
+	confirmed, err := h.confirmationProvider.Confirm(fmt.Sprintf("Create a secret for %s %q now? (y/n): ", missing.Type, missing.Target))
+	if err != nil || !confirmed {
+		return false, err
+	}
+
+	args := secretCreateArgs(img, missing)
+	reader := bufio.NewReader(stdin)
+	for i, arg := range args {
+		if !secret.IsPlaceholder(arg) {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(cmd.ErrOrStderr(), "%s: ", strings.Trim(arg, "<>")); err != nil {
+			return false, err
+		}
+
+		value, err := reader.ReadString('\n')
+		if err != nil {
+			return false, err
+		}
+
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return false, errors.Errorf("%s is required", strings.Trim(arg, "<>"))
+		}
+		args[i] = value
+	}
+
+	createCmd := h.newSecretCreateCommand()
+	createCmd.SetArgs(args)
+	createCmd.SetIn(reader)
+	createCmd.SetOut(out)
+	createCmd.SetErr(cmd.ErrOrStderr())
+	return true, createCmd.Execute()
+}
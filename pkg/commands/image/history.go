@@ -0,0 +1,129 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+func NewHistoryCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	var (
+		namespace string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history <name>",
+		Short: "Display the build history of an image",
+		Long: `Prints a table of the successful builds of a specific image in the provided namespace.
+
+Each build is listed with its digest, build reason, the buildpack changes since the previous successful build and when it finished.
+Use the build number with "kp image rollback" to re-tag the image with a previous build.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp image history my-image\nkp image history my-image -n my-namespace",
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			builds, err := listSuccessfulBuilds(cs, img.Name)
+			if err != nil {
+				return err
+			}
+
+			if len(builds) == 0 {
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), "No successful builds found")
+				return err
+			}
+
+			return displayImageHistory(cmd, img, builds)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+
+	return cmd
+}
+
+func displayImageHistory(cmd *cobra.Command, img *v1alpha1.Image, builds []v1alpha1.Build) error {
+	writer, err := commands.NewTableWriter(cmd.OutOrStdout(), "Build", "Digest", "Reason", "Buildpack Changes", "Finished")
+	if err != nil {
+		return err
+	}
+
+	for i, bld := range builds {
+		changes := ""
+		if i > 0 {
+			changes = formatBuildpackChanges(build.Diff(builds[i-1], bld).Buildpacks)
+		}
+
+		err := writer.AddRow(
+			bld.Labels[v1alpha1.BuildNumberLabel],
+			valueOrDashes(getDigest(bld.Status.LatestImage)),
+			valueOrDashes(bld.Annotations[v1alpha1.BuildReasonAnnotation]),
+			valueOrDashes(changes),
+			bld.Status.GetCondition(corev1alpha1.ConditionSucceeded).LastTransitionTime.Inner.Format("2006-01-02 15:04:05"),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Write(); err != nil {
+		return err
+	}
+
+	if record, ok := getRollbackRecord(img, builds); ok {
+		_, err := fmt.Fprintf(cmd.OutOrStdout(), "Tag %q is pinned to build %q by a rollback\n", img.Spec.Tag, record.Build)
+		return err
+	}
+	return nil
+}
+
+func formatBuildpackChanges(changes []build.BuildpackChange) string {
+	var formatted []string
+	for _, c := range changes {
+		switch c.Change {
+		case build.BuildpackAdded:
+			formatted = append(formatted, fmt.Sprintf("+%s@%s", c.Id, c.To))
+		case build.BuildpackRemoved:
+			formatted = append(formatted, fmt.Sprintf("-%s@%s", c.Id, c.From))
+		default:
+			formatted = append(formatted, fmt.Sprintf("%s %s->%s", c.Id, c.From, c.To))
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func getDigest(ref string) string {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref[i+1:]
+	}
+	return ""
+}
+
+func valueOrDashes(value string) string {
+	if value == "" {
+		return "--"
+	}
+	return value
+}
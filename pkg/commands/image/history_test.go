@@ -0,0 +1,152 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImageHistoryCommand(t *testing.T) {
+	spec.Run(t, "TestImageHistoryCommand", testImageHistoryCommand)
+}
+
+func testImageHistoryCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		imageName        = "test-image"
+	)
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return image.NewHistoryCommand(clientSetProvider)
+	}
+
+	img := &v1alpha1.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      imageName,
+			Namespace: defaultNamespace,
+		},
+		Spec: v1alpha1.ImageSpec{
+			Tag: "test-registry.io/test-image",
+		},
+	}
+
+	it("lists the successful builds with buildpack changes", func() {
+		failedBuild := makeHistoryBuild(imageName, defaultNamespace, "2", "", "COMMIT", corev1.ConditionFalse)
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				img,
+				makeHistoryBuild(imageName, defaultNamespace, "1", "sha256:111", "CONFIG", corev1.ConditionTrue,
+					v1alpha1.BuildpackMetadata{Id: "bp-a", Version: "1.0.0"},
+					v1alpha1.BuildpackMetadata{Id: "bp-b", Version: "1.0.0"},
+				),
+				failedBuild,
+				makeHistoryBuild(imageName, defaultNamespace, "3", "sha256:333", "BUILDPACK", corev1.ConditionTrue,
+					v1alpha1.BuildpackMetadata{Id: "bp-a", Version: "1.1.0"},
+					v1alpha1.BuildpackMetadata{Id: "bp-c", Version: "2.0.0"},
+				),
+				makeHistoryBuild(imageName, defaultNamespace, "4", "sha256:444", "COMMIT", corev1.ConditionTrue,
+					v1alpha1.BuildpackMetadata{Id: "bp-a", Version: "1.1.0"},
+					v1alpha1.BuildpackMetadata{Id: "bp-c", Version: "2.0.0"},
+				),
+			},
+			Args: []string{imageName},
+			ExpectedOutput: `BUILD    DIGEST        REASON       BUILDPACK CHANGES                              FINISHED
+1        sha256:111    CONFIG       --                                             2020-01-01 01:00:00
+3        sha256:333    BUILDPACK    bp-a 1.0.0->1.1.0, -bp-b@1.0.0, +bp-c@2.0.0    2020-01-01 03:00:00
+4        sha256:444    COMMIT       --                                             2020-01-01 04:00:00
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("shows when the tag is pinned by a rollback", func() {
+		pinned := img.DeepCopy()
+		pinned.Annotations = map[string]string{
+			image.RollbackAnnotation: `{"build":"1","image":"test-registry.io/test-image@sha256:111","latestBuild":"3","time":"2020-01-02T00:00:00Z"}`,
+		}
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				pinned,
+				makeHistoryBuild(imageName, defaultNamespace, "1", "sha256:111", "CONFIG", corev1.ConditionTrue),
+				makeHistoryBuild(imageName, defaultNamespace, "3", "sha256:333", "COMMIT", corev1.ConditionTrue),
+			},
+			Args: []string{imageName},
+			ExpectedOutput: `BUILD    DIGEST        REASON    BUILDPACK CHANGES    FINISHED
+1        sha256:111    CONFIG    --                   2020-01-01 01:00:00
+3        sha256:333    COMMIT    --                   2020-01-01 03:00:00
+
+Tag "test-registry.io/test-image" is pinned to build "1" by a rollback
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("prints a message when there are no successful builds", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{img},
+			Args:           []string{imageName},
+			ExpectedOutput: "No successful builds found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("returns an error when the image does not exist", func() {
+		testhelpers.CommandTest{
+			Args:           []string{imageName},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: images.kpack.io \"test-image\" not found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
+
+func makeHistoryBuild(imageName, namespace, number, digest, reason string, status corev1.ConditionStatus, buildpacks ...v1alpha1.BuildpackMetadata) *v1alpha1.Build {
+	hour, _ := time.ParseDuration(number + "h")
+	latestImage := ""
+	if digest != "" {
+		latestImage = "test-registry.io/test-image@" + digest
+	}
+
+	return &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "build-" + number,
+			Namespace: namespace,
+			Labels: map[string]string{
+				v1alpha1.ImageLabel:       imageName,
+				v1alpha1.BuildNumberLabel: number,
+			},
+			Annotations: map[string]string{
+				v1alpha1.BuildReasonAnnotation: reason,
+			},
+		},
+		Status: v1alpha1.BuildStatus{
+			Status: corev1alpha1.Status{
+				Conditions: corev1alpha1.Conditions{
+					{
+						Type:   corev1alpha1.ConditionSucceeded,
+						Status: status,
+						LastTransitionTime: corev1alpha1.VolatileTime{
+							Inner: metav1.Time{Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Add(hour)},
+						},
+					},
+				},
+			},
+			BuildMetadata: buildpacks,
+			LatestImage:   latestImage,
+		},
+	}
+}
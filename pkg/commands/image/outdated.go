@@ -0,0 +1,261 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+type outdatedImage struct {
+	namespace string
+	name      string
+	build     string
+	lag       []string
+}
+
+func NewOutdatedCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	var (
+		namespace     string
+		allNamespaces bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "outdated",
+		Short: "List images that have not been rebuilt with the latest stack and buildpacks",
+		Long: `Prints a table of the images in the provided namespace, or in all namespaces, whose latest successful build
+used a run image or buildpacks that differ from the current cluster stack and builder of the image.
+
+The number of run images an image is behind is counted from the run images used by the builds in the namespace.
+Buildpacks are compared with the highest version of each buildpack provided by the builder.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp image outdated\nkp image outdated -A\nkp image outdated -n my-namespace",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			listNamespace := cs.Namespace
+			if allNamespaces {
+				listNamespace = metav1.NamespaceAll
+			}
+
+			imageList, err := cs.KpackClient.KpackV1alpha1().Images(listNamespace).List(metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+
+			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(listNamespace).List(metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+
+			outdated, err := findOutdatedImages(cs, imageList.Items, buildList.Items)
+			if err != nil {
+				return err
+			}
+
+			if len(outdated) == 0 {
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), "All images are up to date")
+				return err
+			}
+
+			return displayOutdatedImages(cmd, outdated, allNamespaces)
+		},
+	}
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "include images in all namespaces")
+
+	return cmd
+}
+
+func findOutdatedImages(cs k8s.ClientSet, images []v1alpha1.Image, builds []v1alpha1.Build) ([]outdatedImage, error) {
+	buildsByImage := map[string][]v1alpha1.Build{}
+	buildsByNamespace := map[string][]v1alpha1.Build{}
+	for _, bld := range builds {
+		key := bld.Namespace + "/" + bld.Labels[v1alpha1.ImageLabel]
+		buildsByImage[key] = append(buildsByImage[key], bld)
+		buildsByNamespace[bld.Namespace] = append(buildsByNamespace[bld.Namespace], bld)
+	}
+
+	resolver := &builderResolver{cs: cs, stacks: map[string]*v1alpha1.ClusterStack{}}
+
+	var outdated []outdatedImage
+	for _, img := range images {
+		latest, ok := latestSuccessfulBuild(buildsByImage[img.Namespace+"/"+img.Name])
+		if !ok {
+			continue
+		}
+
+		buildpacks, runImage, found, err := resolver.resolve(img)
+		if err != nil {
+			return nil, err
+		} else if !found {
+			continue
+		}
+
+		var lag []string
+		if runImage != "" && latest.Status.Stack.RunImage != "" && latest.Status.Stack.RunImage != runImage {
+			lag = append(lag, runImageLag(buildsByNamespace[img.Namespace], latest.Status.Stack.RunImage, runImage))
+		}
+
+		if changes := build.OutdatedBuildpacks(latest.Status.BuildMetadata, buildpacks); len(changes) > 0 {
+			lag = append(lag, formatBuildpackChanges(changes))
+		}
+
+		if len(lag) > 0 {
+			outdated = append(outdated, outdatedImage{
+				namespace: img.Namespace,
+				name:      img.Name,
+				build:     latest.Labels[v1alpha1.BuildNumberLabel],
+				lag:       lag,
+			})
+		}
+	}
+
+	sort.Slice(outdated, func(i, j int) bool {
+		if outdated[i].namespace != outdated[j].namespace {
+			return outdated[i].namespace < outdated[j].namespace
+		}
+		return outdated[i].name < outdated[j].name
+	})
+	return outdated, nil
+}
+
+func latestSuccessfulBuild(builds []v1alpha1.Build) (v1alpha1.Build, bool) {
+	sort.Slice(builds, build.Sort(builds))
+
+	for i := len(builds) - 1; i >= 0; i-- {
+		if builds[i].IsSuccess() {
+			return builds[i], true
+		}
+	}
+	return v1alpha1.Build{}, false
+}
+
+func runImageLag(builds []v1alpha1.Build, runImage, current string) string {
+	behind, ok := build.RunImagesBehind(builds, runImage, current)
+	switch {
+	case !ok:
+		return "run image outdated"
+	case behind == 1:
+		return "run image 1 version behind"
+	default:
+		return fmt.Sprintf("run image %d versions behind", behind)
+	}
+}
+
+func displayOutdatedImages(cmd *cobra.Command, outdated []outdatedImage, allNamespaces bool) error {
+	headers := []string{"Image", "Build", "Lag"}
+	if allNamespaces {
+		headers = append([]string{"Namespace"}, headers...)
+	}
+
+	writer, err := commands.NewTableWriter(cmd.OutOrStdout(), headers...)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range outdated {
+		row := []string{o.name, o.build, strings.Join(o.lag, ", ")}
+		if allNamespaces {
+			row = append([]string{o.namespace}, row...)
+		}
+
+		if err := writer.AddRow(row...); err != nil {
+			return err
+		}
+	}
+
+	return writer.Write()
+}
+
+// builderResolver looks up the current buildpacks and stack of the builder of an image.
+type builderResolver struct {
+	cs     k8s.ClientSet
+	stacks map[string]*v1alpha1.ClusterStack
+}
+
+func (r *builderResolver) resolve(img v1alpha1.Image) (v1alpha1.BuildpackMetadataList, string, bool, error) {
+	spec, status, ok, err := r.getBuilder(img)
+	if err != nil || !ok {
+		return nil, "", false, err
+	}
+
+	runImage := status.Stack.RunImage
+	if spec.Stack.Name != "" {
+		stack, err := r.getStack(spec.Stack.Name)
+		if err != nil {
+			return nil, "", false, err
+		}
+
+		if stack != nil && stack.Status.RunImage.LatestImage != "" {
+			runImage = stack.Status.RunImage.LatestImage
+		}
+	}
+
+	return status.BuilderMetadata, runImage, true, nil
+}
+
+// resolveStack returns the cluster stack of the builder of an image, or nil when the builder or stack does not exist.
+func (r *builderResolver) resolveStack(img v1alpha1.Image) (*v1alpha1.ClusterStack, error) {
+	spec, _, ok, err := r.getBuilder(img)
+	if err != nil || !ok || spec.Stack.Name == "" {
+		return nil, err
+	}
+	return r.getStack(spec.Stack.Name)
+}
+
+func (r *builderResolver) getBuilder(img v1alpha1.Image) (v1alpha1.BuilderSpec, v1alpha1.BuilderStatus, bool, error) {
+	switch img.Spec.Builder.Kind {
+	case v1alpha1.BuilderKind:
+		builder, err := r.cs.KpackClient.KpackV1alpha1().Builders(img.Namespace).Get(img.Spec.Builder.Name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return v1alpha1.BuilderSpec{}, v1alpha1.BuilderStatus{}, false, nil
+		} else if err != nil {
+			return v1alpha1.BuilderSpec{}, v1alpha1.BuilderStatus{}, false, err
+		}
+		return builder.Spec.BuilderSpec, builder.Status, true, nil
+	case v1alpha1.ClusterBuilderKind:
+		builder, err := r.cs.KpackClient.KpackV1alpha1().ClusterBuilders().Get(img.Spec.Builder.Name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return v1alpha1.BuilderSpec{}, v1alpha1.BuilderStatus{}, false, nil
+		} else if err != nil {
+			return v1alpha1.BuilderSpec{}, v1alpha1.BuilderStatus{}, false, err
+		}
+		return builder.Spec.BuilderSpec, builder.Status, true, nil
+	default:
+		return v1alpha1.BuilderSpec{}, v1alpha1.BuilderStatus{}, false, nil
+	}
+}
+
+func (r *builderResolver) getStack(name string) (*v1alpha1.ClusterStack, error) {
+	if stack, ok := r.stacks[name]; ok {
+		return stack, nil
+	}
+
+	stack, err := r.cs.KpackClient.KpackV1alpha1().ClusterStacks().Get(name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		stack = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	r.stacks[name] = stack
+	return stack, nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImageOutdatedCommand(t *testing.T) {
+	spec.Run(t, "TestImageOutdatedCommand", testImageOutdatedCommand)
+}
+
+func testImageOutdatedCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		otherNamespace   = "some-other-namespace"
+	)
+
+	var (
+		runImageA     = "some-registry.io/run@sha256:" + strings.Repeat("a", 64)
+		runImageB     = "some-registry.io/run@sha256:" + strings.Repeat("b", 64)
+		runImageC     = "some-registry.io/run@sha256:" + strings.Repeat("c", 64)
+		otherRunImage = "other-registry.io/run@sha256:" + strings.Repeat("d", 64)
+	)
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return image.NewOutdatedCommand(clientSetProvider)
+	}
+
+	makeImage := func(name, namespace, builderKind, builderName string) *v1alpha1.Image {
+		return &v1alpha1.Image{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: v1alpha1.ImageSpec{
+				Builder: corev1.ObjectReference{
+					Kind: builderKind,
+					Name: builderName,
+				},
+			},
+		}
+	}
+
+	makeBuild := func(imageName, namespace, number, runImage string, status corev1.ConditionStatus, buildpacks ...v1alpha1.BuildpackMetadata) *v1alpha1.Build {
+		bld := makeHistoryBuild(imageName, namespace, number, "", "STACK", status, buildpacks...)
+		bld.Name = imageName + "-build-" + number
+		bld.Status.Stack.RunImage = runImage
+		return bld
+	}
+
+	created := func(bld *v1alpha1.Build, hour int) *v1alpha1.Build {
+		bld.CreationTimestamp = metav1.Time{Time: time.Date(2020, 1, 1, hour, 0, 0, 0, time.UTC)}
+		return bld
+	}
+
+	stackRef := corev1.ObjectReference{Kind: v1alpha1.ClusterStackKind, Name: "some-stack"}
+
+	builderMetadata := v1alpha1.BuildpackMetadataList{
+		{Id: "paketo/java", Version: "4.1"},
+		{Id: "paketo/java", Version: "4.3"},
+		{Id: "paketo/node", Version: "1.0"},
+	}
+
+	objects := []runtime.Object{
+		&v1alpha1.ClusterStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-stack"},
+			Status: v1alpha1.ClusterStackStatus{
+				ResolvedClusterStack: v1alpha1.ResolvedClusterStack{
+					RunImage: v1alpha1.ClusterStackStatusImage{LatestImage: runImageC},
+				},
+			},
+		},
+		&v1alpha1.ClusterBuilder{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec:       v1alpha1.ClusterBuilderSpec{BuilderSpec: v1alpha1.BuilderSpec{Stack: stackRef}},
+			Status:     v1alpha1.BuilderStatus{BuilderMetadata: builderMetadata},
+		},
+		&v1alpha1.Builder{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-builder", Namespace: otherNamespace},
+			Spec:       v1alpha1.NamespacedBuilderSpec{BuilderSpec: v1alpha1.BuilderSpec{Stack: stackRef}},
+			Status:     v1alpha1.BuilderStatus{BuilderMetadata: builderMetadata},
+		},
+		makeImage("image-a", defaultNamespace, v1alpha1.ClusterBuilderKind, "default"),
+		makeImage("image-b", defaultNamespace, v1alpha1.ClusterBuilderKind, "default"),
+		makeImage("image-c", defaultNamespace, v1alpha1.ClusterBuilderKind, "default"),
+		makeImage("image-d", otherNamespace, v1alpha1.BuilderKind, "some-builder"),
+		created(makeBuild("image-a", defaultNamespace, "1", runImageA, corev1.ConditionTrue,
+			v1alpha1.BuildpackMetadata{Id: "paketo/java", Version: "4.1"}), 1),
+		created(makeBuild("image-a", defaultNamespace, "2", runImageC, corev1.ConditionFalse), 4),
+		created(makeBuild("image-b", defaultNamespace, "1", runImageB, corev1.ConditionTrue,
+			v1alpha1.BuildpackMetadata{Id: "paketo/node", Version: "1.0"}), 2),
+		created(makeBuild("image-b", defaultNamespace, "2", runImageC, corev1.ConditionTrue,
+			v1alpha1.BuildpackMetadata{Id: "paketo/node", Version: "1.0"}), 3),
+		created(makeBuild("image-c", defaultNamespace, "1", "", corev1.ConditionFalse), 5),
+		created(makeBuild("image-d", otherNamespace, "1", otherRunImage, corev1.ConditionTrue,
+			v1alpha1.BuildpackMetadata{Id: "paketo/node", Version: "1.0"}), 1),
+	}
+
+	it("lists the images that are behind the current stack and builder", func() {
+		testhelpers.CommandTest{
+			Objects: objects,
+			ExpectedOutput: `IMAGE      BUILD    LAG
+image-a    1        run image 2 versions behind, paketo/java 4.1->4.3
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("lists the outdated images in all namespaces", func() {
+		testhelpers.CommandTest{
+			Objects: objects,
+			Args:    []string{"-A"},
+			ExpectedOutput: `NAMESPACE                 IMAGE      BUILD    LAG
+some-default-namespace    image-a    1        run image 2 versions behind, paketo/java 4.1->4.3
+some-other-namespace      image-d    1        run image outdated
+
+`,
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("prints a message when all images are up to date", func() {
+		testhelpers.CommandTest{
+			Objects:        objects,
+			Args:           []string{"-n", "some-empty-namespace"},
+			ExpectedOutput: "All images are up to date\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
@@ -16,12 +16,17 @@ import (
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 )
 
-func NewPatchCommand(clientSetProvider k8s.ClientSetProvider, factory *image.Factory, newImageWaiter func(k8s.ClientSet) ImageWaiter) *cobra.Command {
+func NewPatchCommand(clientSetProvider k8s.ClientSetProvider, factory *image.Factory, newImageWaiter func(k8s.ClientSet) ImageWaiter, newCredentialChecker func(k8s.ClientSet) CredentialChecker, credentialHandOff CredentialHandOff) *cobra.Command {
 	var (
 		namespace string
 		subPath   string
 	)
 
+	check := credentialCheck{
+		newCredentialChecker: newCredentialChecker,
+		handOff:              credentialHandOff,
+	}
+
 	cmd := &cobra.Command{
 		Use:   "patch <name>",
 		Short: "Patch an existing image configuration",
@@ -39,6 +44,16 @@ The flags for this command determine how the build will retrieve source code:
 Local source code will be pushed to the same registry as the existing image tag.
 Therefore, you must have credentials to access the registry on your machine.
 
+The service account of the image is checked for credentials for the registry of the image tag
+and for the git repository, unless the repository can be read without credentials.
+Whether an https git repository can be read without credentials is checked with an anonymous request
+that times out after 10 seconds. Repositories that cannot be reached are assumed to be public.
+The git repository of an existing image is only checked when "--git" changes it.
+The git repository is not checked with "--dry-run".
+Use "--skip-credential-check" to skip the check, for example when working offline.
+Missing credentials are reported as warnings, or as an error with "--require-credentials".
+When stdin is a terminal, creating the missing secrets is offered.
+
 Environment variables may be provided by using the "--env" flag.
 For each environment variable, supply the "--env" flag followed by the key value pair.
 For example, "--env key1=value1 --env key2=value2 ...".
@@ -77,7 +92,7 @@ kp image patch my-image --env foo=bar --env color=red --delete-env apple --delet
 				factory.SubPath = &subPath
 			}
 
-			patched, img, err := patch(img, factory, ch, cs)
+			patched, img, err := patch(cmd, img, factory, check, ch, cs)
 			if err != nil {
 				return err
 			}
@@ -104,18 +119,25 @@ kp image patch my-image --env foo=bar --env color=red --delete-env apple --delet
 	cmd.Flags().StringArrayVarP(&factory.DeleteEnv, "delete-env", "d", []string{}, "build time environment variables to remove")
 	cmd.Flags().StringVar(&factory.CacheSize, "cache-size", "", "cache size as a kubernetes quantity")
 	cmd.Flags().BoolP("wait", "w", false, "wait for image patch to be reconciled and tail resulting build logs")
+	setCredentialCheckFlags(cmd, &check)
 	commands.SetDryRunOutputFlags(cmd)
 	commands.SetTLSFlags(cmd, &factory.TLSConfig)
 	return cmd
 }
 
-func patch(img *v1alpha1.Image, factory *image.Factory, ch *commands.CommandHelper, cs k8s.ClientSet) (bool, *v1alpha1.Image, error) {
+func patch(cmd *cobra.Command, img *v1alpha1.Image, factory *image.Factory, check credentialCheck, ch *commands.CommandHelper, cs k8s.ClientSet) (bool, *v1alpha1.Image, error) {
 	patchedImage, patch, err := factory.MakePatch(img)
 	if err != nil {
 		return false, nil, err
 	}
 
 	hasPatch := len(patch) > 0
+	if hasPatch {
+		if err = check.run(cmd, ch, cs, patchedImage, img); err != nil {
+			return false, nil, err
+		}
+	}
+
 	if hasPatch && !ch.IsDryRun() {
 		patchedImage, err = cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Patch(img.Name, types.MergePatchType, patch)
 		if err != nil {
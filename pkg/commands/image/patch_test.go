@@ -38,12 +38,16 @@ func testImagePatchCommand(t *testing.T, when spec.G, it spec.S) {
 		SourceUploader: sourceUploader,
 	}
 	fakeImageWaiter := &fakes.FakeImageWaiter{}
+	fakeCredentialChecker := &fakes.FakeCredentialChecker{}
+	fakeCredentialHandOff := &fakes.FakeCredentialHandOff{}
 
 	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
 		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 		return imgcmds.NewPatchCommand(clientSetProvider, patchFactory, func(set k8s.ClientSet) imgcmds.ImageWaiter {
 			return fakeImageWaiter
-		})
+		}, func(set k8s.ClientSet) imgcmds.CredentialChecker {
+			return fakeCredentialChecker
+		}, fakeCredentialHandOff)
 	}
 
 	img := &v1alpha1.Image{
@@ -538,4 +542,77 @@ status: {}
 			})
 		})
 	})
+
+	when("the service account is missing credentials", func() {
+		it.Before(func() {
+			fakeCredentialChecker.Missing = []image.MissingCredential{
+				{Type: image.GitCredential, Target: "https://gitlab.com/some-org/some-repo"},
+			}
+		})
+
+		it.After(func() {
+			fakeCredentialChecker.Missing = nil
+			fakeCredentialChecker.Calls = nil
+		})
+
+		it("checks the patched image", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					img,
+				},
+				Args: []string{
+					"some-image",
+					"--git", "https://gitlab.com/some-org/some-repo",
+				},
+				ExpectedOutput: `Warning: service account "" has no credentials for git "https://gitlab.com/some-org/some-repo"
+Create them with: kp secret create gitlab-com-git -n some-default-namespace --git-url https://gitlab.com --git-token
+Image "some-image" patched
+`,
+				ExpectPatches: []string{
+					`{"spec":{"source":{"git":{"revision":"master","url":"https://gitlab.com/some-org/some-repo"}}}}`,
+				},
+			}.TestKpack(t, cmdFunc)
+
+			assert.Len(t, fakeCredentialChecker.Calls, 1)
+			assert.Equal(t, "https://gitlab.com/some-org/some-repo", fakeCredentialChecker.Calls[0].Spec.Source.Git.URL)
+		})
+
+		it("does not check the git repository when it is not changed", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					img,
+				},
+				Args: []string{
+					"some-image",
+					"--env", "key3=value3",
+				},
+				ExpectedOutput: `Warning: service account "" has no credentials for git "https://gitlab.com/some-org/some-repo"
+Create them with: kp secret create gitlab-com-git -n some-default-namespace --git-url https://gitlab.com --git-token
+Image "some-image" patched
+`,
+				ExpectPatches: []string{
+					`{"spec":{"build":{"env":[{"name":"key1","value":"value1"},{"name":"key2","value":"value2"},{"name":"key3","value":"value3"}]}}}`,
+				},
+			}.TestKpack(t, cmdFunc)
+
+			assert.Len(t, fakeCredentialChecker.Calls, 1)
+			assert.Nil(t, fakeCredentialChecker.Calls[0].Spec.Source.Git)
+			assert.Equal(t, "some-tag", fakeCredentialChecker.Calls[0].Spec.Tag)
+		})
+
+		it("does not check unchanged images", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					img,
+				},
+				Args: []string{
+					"some-image",
+				},
+				ExpectedOutput: `Image "some-image" patched (no change)
+`,
+			}.TestKpack(t, cmdFunc)
+
+			assert.Len(t, fakeCredentialChecker.Calls, 0)
+		})
+	})
 }
@@ -0,0 +1,182 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+type ImageRelocator interface {
+	ImageTagger
+	Relocate(srcImage v1.Image, dstRepoStr string, writer io.Writer, tlsCfg registry.TLSConfig) (string, error)
+}
+
+// promotionRecord describes an image promoted from a build to another repository.
+type promotionRecord struct {
+	Image       string                         `json:"image"`
+	Namespace   string                         `json:"namespace"`
+	Build       string                         `json:"build"`
+	BuildReason string                         `json:"buildReason"`
+	Builder     string                         `json:"builder,omitempty"`
+	RunImage    string                         `json:"runImage,omitempty"`
+	Buildpacks  v1alpha1.BuildpackMetadataList `json:"buildpacks,omitempty"`
+	Source      string                         `json:"source"`
+	Destination string                         `json:"destination"`
+	Promoted    string                         `json:"promoted"`
+	PromotedAt  string                         `json:"promotedAt"`
+}
+
+func NewPromoteCommand(clientSetProvider k8s.ClientSetProvider, fetcher ImageFetcher, relocator ImageRelocator) *cobra.Command {
+	var (
+		namespace   string
+		buildNumber string
+		to          string
+		output      string
+		tlsCfg      registry.TLSConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "promote <name>",
+		Short: "Copy the image of a build to another repository",
+		Long: `Copy the image produced by a successful build of an image in the provided namespace to another repository.
+
+The build defaults to the latest successful build.
+When the destination includes a tag the image is written to that tag, otherwise it is written to the repository with a timestamp tag.
+Only blobs that are missing from the destination repository are uploaded.
+Registry credentials for the source and destination must be available locally.
+
+Use "--output" to print a record of the promoted image, its build and the buildpacks it was built with.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example: `kp image promote my-image --to prod-registry.io/my-repo
+kp image promote my-image -b 3 --to prod-registry.io/my-repo:v1.2.0 --output json`,
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if to == "" {
+				return errors.New("--to is required")
+			}
+
+			if output != "" && output != "json" && output != "yaml" {
+				return errors.Errorf("invalid output format %q, must be one of json or yaml", output)
+			}
+
+			destination, err := name.ParseReference(to, name.WeakValidation)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := destination.(name.Digest); ok {
+				return errors.New("--to must be a repository or tag, not a digest")
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			builds, err := listSuccessfulBuilds(cs, args[0])
+			if err != nil {
+				return err
+			}
+
+			if len(builds) == 0 {
+				return errors.Errorf("no successful builds found for image %q", args[0])
+			}
+
+			bld := builds[len(builds)-1]
+			if buildNumber != "" {
+				var ok bool
+				if bld, ok = findBuildNumber(builds, buildNumber); !ok {
+					return errors.Errorf("successful build %q of image %q not found", buildNumber, args[0])
+				}
+			}
+
+			if bld.Status.LatestImage == "" {
+				return errors.Errorf("build %q of image %q did not produce an image", bld.Labels[v1alpha1.BuildNumberLabel], args[0])
+			}
+
+			srcImage, err := fetcher.Fetch(bld.Status.LatestImage, tlsCfg)
+			if err != nil {
+				return err
+			}
+
+			writer := cmd.OutOrStdout()
+			if output != "" {
+				writer = cmd.ErrOrStderr()
+			}
+
+			var promoted string
+			if strings.Contains(path.Base(to), ":") {
+				promoted, err = relocator.Tag(srcImage, to, writer, tlsCfg)
+			} else {
+				promoted, err = relocator.Relocate(srcImage, to, writer, tlsCfg)
+			}
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(writer, "Promoted build %q of image %q to %s\n", bld.Labels[v1alpha1.BuildNumberLabel], args[0], promoted)
+			if err != nil || output == "" {
+				return err
+			}
+
+			return writePromotionRecord(cmd.OutOrStdout(), output, promotionRecord{
+				Image:       args[0],
+				Namespace:   cs.Namespace,
+				Build:       bld.Labels[v1alpha1.BuildNumberLabel],
+				BuildReason: bld.Annotations[v1alpha1.BuildReasonAnnotation],
+				Builder:     bld.Spec.Builder.Image,
+				RunImage:    bld.Status.Stack.RunImage,
+				Buildpacks:  bld.Status.BuildMetadata,
+				Source:      bld.Status.LatestImage,
+				Destination: to,
+				Promoted:    promoted,
+				PromotedAt:  time.Now().UTC().Format(time.RFC3339),
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().StringVar(&to, "to", "", "repository or tag to copy the image to")
+	cmd.Flags().StringVar(&output, "output", "", "print a record of the promotion. supported formats are: json, yaml")
+	commands.SetTLSFlags(cmd, &tlsCfg)
+
+	return cmd
+}
+
+func writePromotionRecord(w io.Writer, format string, record promotionRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if format == "yaml" {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	} else {
+		data = append(data, '\n')
+	}
+
+	_, err = w.Write(data)
+	return err
+}
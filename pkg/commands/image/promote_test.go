@@ -0,0 +1,113 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/image/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImagePromoteCommand(t *testing.T) {
+	spec.Run(t, "TestImagePromoteCommand", testImagePromoteCommand)
+}
+
+func testImagePromoteCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		imageName        = "test-image"
+		imageTag         = "test-registry.io/test-image"
+	)
+
+	var (
+		fetcher   *fakes.Fetcher
+		relocator *fakes.Relocator
+		digests   map[string]v1.Hash
+		out       *bytes.Buffer
+		errOut    *bytes.Buffer
+	)
+
+	it.Before(func() {
+		fetcher = &fakes.Fetcher{}
+		relocator = &fakes.Relocator{}
+		digests = map[string]v1.Hash{}
+		out = &bytes.Buffer{}
+		errOut = &bytes.Buffer{}
+
+		for _, digest := range []string{"sha256:111", "sha256:333"} {
+			randomImage, err := random.Image(0, 0)
+			require.NoError(t, err)
+			fetcher.AddImage(imageTag+"@"+digest, randomImage)
+
+			digests[digest], err = randomImage.Digest()
+			require.NoError(t, err)
+		}
+	})
+
+	execute := func(args ...string) error {
+		clientSet := fake.NewSimpleClientset(
+			makeHistoryBuild(imageName, defaultNamespace, "1", "sha256:111", "CONFIG", corev1.ConditionTrue),
+			makeHistoryBuild(imageName, defaultNamespace, "2", "", "COMMIT", corev1.ConditionFalse),
+			makeHistoryBuild(imageName, defaultNamespace, "3", "sha256:333", "COMMIT", corev1.ConditionTrue),
+		)
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		cmd := image.NewPromoteCommand(clientSetProvider, fetcher, relocator)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	it("copies the image of the latest successful build to the repository", func() {
+		require.NoError(t, execute(imageName, "--to", "prod-registry.io/my-repo"))
+		require.Equal(t, "Promoted build \"3\" of image \"test-image\" to prod-registry.io/my-repo@"+digests["sha256:333"].String()+"\n", out.String())
+		require.Len(t, relocator.Tags, 0)
+	})
+
+	it("copies the image of a specific build to a tag", func() {
+		require.NoError(t, execute(imageName, "-b", "1", "--to", "prod-registry.io/my-repo:v1"))
+		require.Equal(t, "Promoted build \"1\" of image \"test-image\" to prod-registry.io/my-repo@"+digests["sha256:111"].String()+"\n", out.String())
+		require.Equal(t, []string{"prod-registry.io/my-repo:v1"}, relocator.Tags)
+	})
+
+	it("prints a record of the promotion", func() {
+		require.NoError(t, execute(imageName, "--to", "prod-registry.io/my-repo", "--output", "json"))
+		require.Equal(t, "Promoted build \"3\" of image \"test-image\" to prod-registry.io/my-repo@"+digests["sha256:333"].String()+"\n", errOut.String())
+
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+		require.Equal(t, imageName, record["image"])
+		require.Equal(t, defaultNamespace, record["namespace"])
+		require.Equal(t, "3", record["build"])
+		require.Equal(t, imageTag+"@sha256:333", record["source"])
+		require.Equal(t, "prod-registry.io/my-repo", record["destination"])
+		require.Equal(t, "prod-registry.io/my-repo@"+digests["sha256:333"].String(), record["promoted"])
+		require.NotEmpty(t, record["promotedAt"])
+	})
+
+	it("returns an error when the build did not succeed", func() {
+		err := execute(imageName, "-b", "2", "--to", "prod-registry.io/my-repo")
+		require.EqualError(t, err, "successful build \"2\" of image \"test-image\" not found")
+	})
+
+	it("returns an error when the destination is a digest", func() {
+		err := execute(imageName, "--to", "prod-registry.io/my-repo@"+digests["sha256:111"].String())
+		require.EqualError(t, err, "--to must be a repository or tag, not a digest")
+	})
+
+	it("returns an error when no destination is provided", func() {
+		require.EqualError(t, execute(imageName), "--to is required")
+	})
+}
@@ -0,0 +1,145 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+func NewProvenanceCommand(clientSetProvider k8s.ClientSetProvider, fetcher ImageFetcher) *cobra.Command {
+	var (
+		namespace   string
+		buildNumber string
+		signingKey  string
+		tlsCfg      registry.TLSConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "provenance <name>",
+		Short: "Display the provenance of an image build",
+		Long: `Prints an in-toto statement with a SLSA provenance predicate describing how a successful build of an image in the provided namespace was produced.
+
+The statement includes the image digest, the builder image, the stack and its run and build images, the buildpacks,
+the source, the build environment variables, the build reason and the build timestamps.
+The stack and buildpacks are read from the labels of the built image in the registry.
+Builds do not record the build image of their stack, so it is read from the cluster stack of the builder of the image.
+It is omitted and the materials are marked as incomplete when the cluster stack no longer has the run image used by the build.
+
+Use "--signing-key" to sign the statement with an unencrypted PEM encoded ECDSA, RSA or Ed25519 private key
+in PKCS#8, PKCS#1 or SEC 1 format. Encrypted keys, such as the keys generated by "cosign generate-key-pair", are not supported.
+The signed statement is printed as a DSSE envelope.
+
+The build defaults to the latest successful build.
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp image provenance my-image\nkp image provenance my-image -b 3 -n my-namespace\nkp image provenance my-image --signing-key ./ec-private-key.pem",
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			builds, err := listSuccessfulBuilds(cs, args[0])
+			if err != nil {
+				return err
+			}
+
+			if len(builds) == 0 {
+				return errors.Errorf("no successful builds found for image %q", args[0])
+			}
+
+			bld := builds[len(builds)-1]
+			if buildNumber != "" {
+				var ok bool
+				if bld, ok = findBuildNumber(builds, buildNumber); !ok {
+					return errors.Errorf("successful build %q of image %q not found", buildNumber, args[0])
+				}
+			}
+
+			if bld.Status.LatestImage == "" {
+				return errors.Errorf("build %q of image %q did not produce an image", bld.Labels[v1alpha1.BuildNumberLabel], args[0])
+			}
+
+			img, err := fetcher.Fetch(bld.Status.LatestImage, tlsCfg)
+			if err != nil {
+				return err
+			}
+
+			digest, err := img.Digest()
+			if err != nil {
+				return err
+			}
+
+			md, err := build.ReadImageMetadata(img)
+			if err != nil {
+				return err
+			}
+
+			stack, err := getImageStack(cs, args[0])
+			if err != nil {
+				return err
+			}
+
+			statement, err := build.NewProvenanceStatement(bld, digest, md, stack)
+			if err != nil {
+				return err
+			}
+
+			if signingKey == "" {
+				return writeIndentedJSON(cmd.OutOrStdout(), statement)
+			}
+
+			payload, err := json.Marshal(statement)
+			if err != nil {
+				return err
+			}
+
+			envelope, err := build.SignPayload(build.InTotoPayloadType, payload, signingKey)
+			if err != nil {
+				return err
+			}
+
+			return writeIndentedJSON(cmd.OutOrStdout(), envelope)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&buildNumber, "build", "b", "", "build number")
+	cmd.Flags().StringVar(&signingKey, "signing-key", "", "path to an unencrypted PEM encoded ECDSA, RSA or Ed25519 private key used to sign the statement")
+	commands.SetTLSFlags(cmd, &tlsCfg)
+
+	return cmd
+}
+
+// getImageStack returns the cluster stack of the builder of an image, or nil when the image, builder or stack no longer exists.
+func getImageStack(cs k8s.ClientSet, imageName string) (*v1alpha1.ClusterStack, error) {
+	img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(imageName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	resolver := &builderResolver{cs: cs, stacks: map[string]*v1alpha1.ClusterStack{}}
+	return resolver.resolveStack(*img)
+}
+
+func writeIndentedJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
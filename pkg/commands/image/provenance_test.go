@@ -0,0 +1,321 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/pivotal/kpack/pkg/registry/imagehelpers"
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	buildpkg "github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/image/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImageProvenanceCommand(t *testing.T) {
+	spec.Run(t, "TestImageProvenanceCommand", testImageProvenanceCommand)
+}
+
+func testImageProvenanceCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		imageName        = "test-image"
+	)
+
+	var (
+		builderDigest    = strings.Repeat("b", 64)
+		runImageDigest   = strings.Repeat("a", 64)
+		buildImageDigest = strings.Repeat("c", 64)
+	)
+
+	var (
+		fetcher *fakes.Fetcher
+		digest  string
+		objects []runtime.Object
+	)
+
+	it.Before(func() {
+		builtImage, err := random.Image(0, 0)
+		require.NoError(t, err)
+
+		builtImage, err = imagehelpers.SetStringLabels(builtImage, map[string]string{
+			buildpkg.StackIdLabel:       "some-stack-id",
+			buildpkg.BuildMetadataLabel: `{"buildpacks": [{"id": "bp-id-1", "version": "bp-version-1"}]}`,
+			buildpkg.LifecycleMetadataLabel: `{
+  "runImage": {"topLayer": "sha256:top", "reference": "sha256:` + runImageDigest + `"},
+  "stack": {"runImage": {"image": "some-repo.com/run-image:latest"}}
+}`,
+		})
+		require.NoError(t, err)
+
+		hash, err := builtImage.Digest()
+		require.NoError(t, err)
+		digest = hash.Hex
+
+		fetcher = &fakes.Fetcher{}
+		fetcher.AddImage("test-registry.io/test-image@"+hash.String(), builtImage)
+
+		bld := makeHistoryBuild(imageName, defaultNamespace, "2", hash.String(), "COMMIT", corev1.ConditionTrue)
+		bld.CreationTimestamp = metav1.Time{Time: time.Date(2020, 1, 1, 1, 30, 0, 0, time.UTC)}
+		bld.Spec = v1alpha1.BuildSpec{
+			Builder: v1alpha1.BuildBuilderSpec{
+				Image: "some-registry.io/builder@sha256:" + builderDigest,
+			},
+			Source: v1alpha1.SourceConfig{
+				Git: &v1alpha1.Git{
+					URL:      "https://github.com/some/repo",
+					Revision: "abc123",
+				},
+			},
+			Env: []corev1.EnvVar{
+				{Name: "BP_JAVA_VERSION", Value: "11"},
+			},
+		}
+
+		objects = []runtime.Object{
+			&v1alpha1.Image{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      imageName,
+					Namespace: defaultNamespace,
+				},
+				Spec: v1alpha1.ImageSpec{
+					Builder: corev1.ObjectReference{
+						Kind: v1alpha1.ClusterBuilderKind,
+						Name: "default",
+					},
+				},
+			},
+			&v1alpha1.ClusterBuilder{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default",
+				},
+				Spec: v1alpha1.ClusterBuilderSpec{
+					BuilderSpec: v1alpha1.BuilderSpec{
+						Stack: corev1.ObjectReference{
+							Kind: v1alpha1.ClusterStackKind,
+							Name: "some-stack",
+						},
+					},
+				},
+			},
+			&v1alpha1.ClusterStack{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "some-stack",
+				},
+				Status: v1alpha1.ClusterStackStatus{
+					ResolvedClusterStack: v1alpha1.ResolvedClusterStack{
+						Id: "some-stack-id",
+						BuildImage: v1alpha1.ClusterStackStatusImage{
+							LatestImage: "some-repo.com/build-image@sha256:" + buildImageDigest,
+						},
+						RunImage: v1alpha1.ClusterStackStatusImage{
+							LatestImage: "some-registry.io/run-image@sha256:" + runImageDigest,
+						},
+					},
+				},
+			},
+			makeHistoryBuild(imageName, defaultNamespace, "1", "sha256:111", "CONFIG", corev1.ConditionTrue),
+			bld,
+			makeHistoryBuild(imageName, defaultNamespace, "3", "", "COMMIT", corev1.ConditionFalse),
+		}
+	})
+
+	cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		return image.NewProvenanceCommand(clientSetProvider, fetcher)
+	}
+
+	it("prints the provenance of the latest successful build", func() {
+		testhelpers.CommandTest{
+			Objects: objects,
+			Args:    []string{imageName},
+			ExpectedOutput: fmt.Sprintf(`{
+  "_type": "https://in-toto.io/Statement/v0.1",
+  "subject": [
+    {
+      "name": "test-registry.io/test-image",
+      "digest": {
+        "sha256": "%[1]s"
+      }
+    }
+  ],
+  "predicateType": "https://slsa.dev/provenance/v0.1",
+  "predicate": {
+    "builder": {
+      "id": "some-registry.io/builder@sha256:%[2]s"
+    },
+    "recipe": {
+      "type": "https://kpack.io/Build@v1alpha1",
+      "definedInMaterial": 0,
+      "entryPoint": "test-image",
+      "arguments": {
+        "buildReason": "COMMIT",
+        "stack": {
+          "id": "some-stack-id",
+          "runImage": "some-repo.com/run-image@sha256:%[3]s",
+          "buildImage": "some-repo.com/build-image@sha256:%[4]s"
+        },
+        "buildpacks": [
+          {
+            "id": "bp-id-1",
+            "version": "bp-version-1"
+          }
+        ]
+      },
+      "environment": {
+        "BP_JAVA_VERSION": "11"
+      }
+    },
+    "metadata": {
+      "buildInvocationId": "some-default-namespace/build-2",
+      "buildStartedOn": "2020-01-01T01:30:00Z",
+      "buildFinishedOn": "2020-01-01T02:00:00Z",
+      "completeness": {
+        "arguments": true,
+        "environment": true,
+        "materials": true
+      },
+      "reproducible": false
+    },
+    "materials": [
+      {
+        "uri": "git+https://github.com/some/repo",
+        "digest": {
+          "sha1": "abc123"
+        }
+      },
+      {
+        "uri": "some-registry.io/builder",
+        "digest": {
+          "sha256": "%[2]s"
+        }
+      },
+      {
+        "uri": "some-repo.com/run-image",
+        "digest": {
+          "sha256": "%[3]s"
+        }
+      },
+      {
+        "uri": "some-repo.com/build-image",
+        "digest": {
+          "sha256": "%[4]s"
+        }
+      }
+    ]
+  }
+}
+`, digest, builderDigest, runImageDigest, buildImageDigest),
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("omits the build image when the cluster stack was updated after the build", func() {
+		stack := objects[2].(*v1alpha1.ClusterStack)
+		stack.Status.RunImage.LatestImage = "some-registry.io/run-image@sha256:" + strings.Repeat("d", 64)
+
+		cmd := cmdFunc(fake.NewSimpleClientset(objects...))
+		cmd.SetArgs([]string{imageName})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		require.NoError(t, cmd.Execute())
+
+		var statement buildpkg.ProvenanceStatement
+		require.NoError(t, json.Unmarshal(out.Bytes(), &statement))
+		require.Empty(t, statement.Predicate.Recipe.Arguments.Stack.BuildImage)
+		require.Len(t, statement.Predicate.Materials, 3)
+		require.False(t, statement.Predicate.Metadata.Completeness.Materials)
+	})
+
+	it("omits the build image when the image no longer exists", func() {
+		cmd := cmdFunc(fake.NewSimpleClientset(objects[1:]...))
+		cmd.SetArgs([]string{imageName})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		require.NoError(t, cmd.Execute())
+
+		var statement buildpkg.ProvenanceStatement
+		require.NoError(t, json.Unmarshal(out.Bytes(), &statement))
+		require.Len(t, statement.Predicate.Materials, 3)
+		require.False(t, statement.Predicate.Metadata.Completeness.Materials)
+	})
+
+	it("signs the statement with the signing key", func() {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		require.NoError(t, err)
+
+		dir, err := ioutil.TempDir("", "provenance-test")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		keyPath := filepath.Join(dir, "key.pem")
+		require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+		cmd := cmdFunc(fake.NewSimpleClientset(objects...))
+		cmd.SetArgs([]string{imageName, "-b", "2", "--signing-key", keyPath})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		require.NoError(t, cmd.Execute())
+
+		var envelope buildpkg.Envelope
+		require.NoError(t, json.Unmarshal(out.Bytes(), &envelope))
+		require.Equal(t, "application/vnd.in-toto+json", envelope.PayloadType)
+		require.Len(t, envelope.Signatures, 1)
+
+		payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+		require.NoError(t, err)
+
+		sig, err := base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
+		require.NoError(t, err)
+
+		pae := fmt.Sprintf("DSSEv1 %d %s %d %s", len(envelope.PayloadType), envelope.PayloadType, len(payload), payload)
+		require.True(t, ed25519.Verify(publicKey, []byte(pae), sig))
+
+		var statement buildpkg.ProvenanceStatement
+		require.NoError(t, json.Unmarshal(payload, &statement))
+		require.Equal(t, "some-default-namespace/build-2", statement.Predicate.Metadata.BuildInvocationID)
+	})
+
+	it("errors when the build is not a successful build", func() {
+		testhelpers.CommandTest{
+			Objects:        objects,
+			Args:           []string{imageName, "-b", "3"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: successful build \"3\" of image \"test-image\" not found\n",
+		}.TestKpack(t, cmdFunc)
+	})
+
+	it("errors when there are no successful builds", func() {
+		testhelpers.CommandTest{
+			Args:           []string{imageName},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: no successful builds found for image \"test-image\"\n",
+		}.TestKpack(t, cmdFunc)
+	})
+}
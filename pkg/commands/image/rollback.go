@@ -0,0 +1,210 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/build"
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
+)
+
+const RollbackAnnotation = "kpack.io/rollback"
+
+type ImageFetcher interface {
+	Fetch(src string, tlsCfg registry.TLSConfig) (v1.Image, error)
+}
+
+type ImageTagger interface {
+	Tag(srcImage v1.Image, tag string, writer io.Writer, tlsCfg registry.TLSConfig) (string, error)
+}
+
+// rollbackRecord is stored in the rollback annotation of an image when its tag is pinned to an older build.
+type rollbackRecord struct {
+	Build       string `json:"build"`
+	Image       string `json:"image"`
+	LatestBuild string `json:"latestBuild"`
+	Time        string `json:"time"`
+}
+
+func NewRollbackCommand(clientSetProvider k8s.ClientSetProvider, fetcher ImageFetcher, tagger ImageTagger) *cobra.Command {
+	var (
+		namespace   string
+		buildNumber string
+		tlsCfg      registry.TLSConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rollback <name>",
+		Short: "Roll back an image to a previous build",
+		Long: `Re-tag the tag of an image in the provided namespace with the image produced by a previous successful build.
+
+The rollback is recorded on the image and shown by "kp image status" until a newer build succeeds.
+The next build of the image overwrites the tag, rolling back to the latest build re-tags it and clears the record.
+Registry credentials for the image tag must be available locally.
+
+The namespace defaults to the kubernetes current-context namespace.`,
+		Example:      "kp image rollback my-image --to-build 3\nkp image rollback my-image --to-build 3 -n my-namespace --dry-run",
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if buildNumber == "" {
+				return errors.New("--to-build is required")
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			builds, err := listSuccessfulBuilds(cs, img.Name)
+			if err != nil {
+				return err
+			}
+
+			bld, ok := findBuildNumber(builds, buildNumber)
+			if !ok {
+				return errors.Errorf("successful build %q of image %q not found", buildNumber, img.Name)
+			}
+
+			if bld.Status.LatestImage == "" {
+				return errors.Errorf("build %q of image %q did not produce an image", buildNumber, img.Name)
+			}
+
+			srcImage, err := fetcher.Fetch(bld.Status.LatestImage, tlsCfg)
+			if err != nil {
+				return err
+			}
+
+			if err := ch.Printlnf("Rolling back Image %q to build %q", img.Name, buildNumber); err != nil {
+				return err
+			}
+
+			if !ch.IsDryRun() {
+				if _, err := tagger.Tag(srcImage, img.Spec.Tag, ch.Writer(), tlsCfg); err != nil {
+					return err
+				}
+			}
+
+			latest := builds[len(builds)-1]
+			img = img.DeepCopy()
+			if latest.Name == bld.Name {
+				delete(img.Annotations, RollbackAnnotation)
+			} else {
+				record, err := json.Marshal(rollbackRecord{
+					Build:       buildNumber,
+					Image:       bld.Status.LatestImage,
+					LatestBuild: latest.Labels[v1alpha1.BuildNumberLabel],
+					Time:        time.Now().UTC().Format(time.RFC3339),
+				})
+				if err != nil {
+					return err
+				}
+
+				if img.Annotations == nil {
+					img.Annotations = map[string]string{}
+				}
+				img.Annotations[RollbackAnnotation] = string(record)
+			}
+
+			if !ch.IsDryRun() {
+				img, err = cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Update(img)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := ch.PrintObj(img); err != nil {
+				return err
+			}
+
+			return ch.PrintResult("Image %q tag %q now points to build %q", img.Name, img.Spec.Tag, buildNumber)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVar(&buildNumber, "to-build", "", "build number to roll back to")
+	commands.SetDryRunOutputFlags(cmd)
+	commands.SetTLSFlags(cmd, &tlsCfg)
+	return cmd
+}
+
+// listSuccessfulBuilds returns the successful builds of an image sorted by build number.
+func listSuccessfulBuilds(cs k8s.ClientSet, imageName string) ([]v1alpha1.Build, error) {
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
+		LabelSelector: v1alpha1.ImageLabel + "=" + imageName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(buildList.Items, build.Sort(buildList.Items))
+
+	var builds []v1alpha1.Build
+	for _, bld := range buildList.Items {
+		if bld.IsSuccess() {
+			builds = append(builds, bld)
+		}
+	}
+	return builds, nil
+}
+
+func findBuildNumber(builds []v1alpha1.Build, buildNumber string) (v1alpha1.Build, bool) {
+	for _, bld := range builds {
+		if bld.Labels[v1alpha1.BuildNumberLabel] == buildNumber {
+			return bld, true
+		}
+	}
+	return v1alpha1.Build{}, false
+}
+
+// getRollbackRecord returns the rollback recorded on an image while no build newer than
+// the latest build at the time of the rollback has succeeded.
+func getRollbackRecord(img *v1alpha1.Image, builds []v1alpha1.Build) (rollbackRecord, bool) {
+	var record rollbackRecord
+
+	value, ok := img.Annotations[RollbackAnnotation]
+	if !ok {
+		return record, false
+	}
+
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return record, false
+	}
+
+	recordedLatest, err := strconv.Atoi(record.LatestBuild)
+	if err != nil {
+		return record, false
+	}
+
+	for _, bld := range builds {
+		n, err := strconv.Atoi(bld.Labels[v1alpha1.BuildNumberLabel])
+		if err == nil && n > recordedLatest && bld.IsSuccess() {
+			return record, false
+		}
+	}
+	return record, true
+}
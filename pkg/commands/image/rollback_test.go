@@ -0,0 +1,155 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/image/fakes"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestImageRollbackCommand(t *testing.T) {
+	spec.Run(t, "TestImageRollbackCommand", testImageRollbackCommand)
+}
+
+func testImageRollbackCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		imageName        = "test-image"
+		imageTag         = "test-registry.io/test-image"
+	)
+
+	var (
+		clientSet *fake.Clientset
+		fetcher   *fakes.Fetcher
+		relocator *fakes.Relocator
+		img       *v1alpha1.Image
+	)
+
+	it.Before(func() {
+		img = &v1alpha1.Image{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      imageName,
+				Namespace: defaultNamespace,
+			},
+			Spec: v1alpha1.ImageSpec{
+				Tag: imageTag,
+			},
+		}
+
+		fetcher = &fakes.Fetcher{}
+		relocator = &fakes.Relocator{}
+		for _, digest := range []string{"sha256:111", "sha256:333"} {
+			randomImage, err := random.Image(0, 0)
+			require.NoError(t, err)
+			fetcher.AddImage(imageTag+"@"+digest, randomImage)
+		}
+	})
+
+	newClientSet := func(img *v1alpha1.Image) *fake.Clientset {
+		return fake.NewSimpleClientset(
+			img,
+			makeHistoryBuild(imageName, defaultNamespace, "1", "sha256:111", "CONFIG", corev1.ConditionTrue),
+			makeHistoryBuild(imageName, defaultNamespace, "2", "", "COMMIT", corev1.ConditionFalse),
+			makeHistoryBuild(imageName, defaultNamespace, "3", "sha256:333", "COMMIT", corev1.ConditionTrue),
+		)
+	}
+
+	execute := func(args ...string) (string, error) {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		cmd := image.NewRollbackCommand(clientSetProvider, fetcher, relocator)
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	updatedImages := func() []*v1alpha1.Image {
+		actions, err := testhelpers.ActionRecorderList{clientSet}.ActionsByVerb()
+		require.NoError(t, err)
+
+		var images []*v1alpha1.Image
+		for _, update := range actions.Updates {
+			images = append(images, update.GetObject().(*v1alpha1.Image))
+		}
+		return images
+	}
+
+	it("re-tags the image with a previous build and records the rollback", func() {
+		clientSet = newClientSet(img)
+
+		out, err := execute(imageName, "--to-build", "1")
+		require.NoError(t, err)
+		require.Equal(t, `Rolling back Image "test-image" to build "1"
+Image "test-image" tag "test-registry.io/test-image" now points to build "1"
+`, out)
+		require.Equal(t, []string{imageTag}, relocator.Tags)
+
+		updates := updatedImages()
+		require.Len(t, updates, 1)
+
+		var record map[string]string
+		require.NoError(t, json.Unmarshal([]byte(updates[0].Annotations[image.RollbackAnnotation]), &record))
+		require.Equal(t, "1", record["build"])
+		require.Equal(t, imageTag+"@sha256:111", record["image"])
+		require.Equal(t, "3", record["latestBuild"])
+		require.NotEmpty(t, record["time"])
+	})
+
+	it("clears the rollback when rolling back to the latest build", func() {
+		img.Annotations = map[string]string{
+			image.RollbackAnnotation: `{"build":"1","image":"test-registry.io/test-image@sha256:111","latestBuild":"3","time":"2020-01-02T00:00:00Z"}`,
+		}
+		clientSet = newClientSet(img)
+
+		_, err := execute(imageName, "--to-build", "3")
+		require.NoError(t, err)
+		require.Equal(t, []string{imageTag}, relocator.Tags)
+
+		updates := updatedImages()
+		require.Len(t, updates, 1)
+		require.NotContains(t, updates[0].Annotations, image.RollbackAnnotation)
+	})
+
+	it("does not tag or update the image with dry run", func() {
+		clientSet = newClientSet(img)
+
+		out, err := execute(imageName, "--to-build", "1", "--dry-run")
+		require.NoError(t, err)
+		require.Equal(t, `Rolling back Image "test-image" to build "1"
+Image "test-image" tag "test-registry.io/test-image" now points to build "1" (dry run)
+`, out)
+		require.Len(t, relocator.Tags, 0)
+		require.Len(t, updatedImages(), 0)
+	})
+
+	it("returns an error when the build did not succeed", func() {
+		clientSet = newClientSet(img)
+
+		_, err := execute(imageName, "--to-build", "2")
+		require.EqualError(t, err, "successful build \"2\" of image \"test-image\" not found")
+		require.Len(t, relocator.Tags, 0)
+	})
+
+	it("returns an error when no build is provided", func() {
+		clientSet = newClientSet(img)
+
+		_, err := execute(imageName)
+		require.EqualError(t, err, "--to-build is required")
+	})
+}
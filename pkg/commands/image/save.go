@@ -14,13 +14,18 @@ import (
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 )
 
-func NewSaveCommand(clientSetProvider k8s.ClientSetProvider, factory *image.Factory, newImageWaiter func(k8s.ClientSet) ImageWaiter) *cobra.Command {
+func NewSaveCommand(clientSetProvider k8s.ClientSetProvider, factory *image.Factory, newImageWaiter func(k8s.ClientSet) ImageWaiter, newCredentialChecker func(k8s.ClientSet) CredentialChecker, credentialHandOff CredentialHandOff) *cobra.Command {
 	var (
 		tag       string
 		namespace string
 		subPath   string
 	)
 
+	check := credentialCheck{
+		newCredentialChecker: newCredentialChecker,
+		handOff:              credentialHandOff,
+	}
+
 	cmd := &cobra.Command{
 		Use:   "save <name> --tag <tag>",
 		Short: "Create or patch an image configuration",
@@ -41,6 +46,16 @@ The flags for this command determine how the build will retrieve source code:
 Local source code will be pushed to the same registry provided for the image tag.
 Therefore, you must have credentials to access the registry on your machine.
 
+The service account of the image is checked for credentials for the registry of the image tag
+and for the git repository, unless the repository can be read without credentials.
+Whether an https git repository can be read without credentials is checked with an anonymous request
+that times out after 10 seconds. Repositories that cannot be reached are assumed to be public.
+The git repository of an existing image is only checked when "--git" changes it.
+The git repository is not checked with "--dry-run".
+Use "--skip-credential-check" to skip the check, for example when working offline.
+Missing credentials are reported as warnings, or as an error with "--require-credentials".
+When stdin is a terminal, creating the missing secrets is offered.
+
 Environment variables may be provided by using the "--env" flag.
 For each environment variable, supply the "--env" flag followed by the key value pair.
 For example, "--env key1=value1 --env key2=value2 ...".`,
@@ -73,7 +88,7 @@ kp image save my-image --tag my-registry.com/my-repo --blob https://my-blob-host
 				}
 
 				factory.SubPath = &subPath
-				img, err = create(name, tag, factory, ch, cs)
+				img, err = create(cmd, name, tag, factory, check, ch, cs)
 			} else if err != nil {
 				return err
 			} else {
@@ -82,7 +97,7 @@ kp image save my-image --tag my-registry.com/my-repo --blob https://my-blob-host
 				}
 
 				var patched bool
-				patched, img, err = patch(img, factory, ch, cs)
+				patched, img, err = patch(cmd, img, factory, check, ch, cs)
 				if !patched {
 					shouldWait = false
 				}
@@ -112,6 +127,7 @@ kp image save my-image --tag my-registry.com/my-repo --blob https://my-blob-host
 	cmd.Flags().StringVarP(&factory.ClusterBuilder, "cluster-builder", "c", "", "cluster builder name")
 	cmd.Flags().StringArrayVar(&factory.Env, "env", []string{}, "build time environment variables")
 	cmd.Flags().BoolP("wait", "w", false, "wait for image create to be reconciled and tail resulting build logs")
+	setCredentialCheckFlags(cmd, &check)
 	commands.SetDryRunOutputFlags(cmd)
 	commands.SetTLSFlags(cmd, &factory.TLSConfig)
 	return cmd
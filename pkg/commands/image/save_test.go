@@ -40,12 +40,16 @@ func testImageSaveCommand(t *testing.T, when spec.G, it spec.S) {
 			SourceUploader: sourceUploader,
 		}
 		fakeImageWaiter := &fakes.FakeImageWaiter{}
+		fakeCredentialChecker := &fakes.FakeCredentialChecker{}
+		fakeCredentialHandOff := &fakes.FakeCredentialHandOff{}
 
 		cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
 			clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 			return imgcmds.NewSaveCommand(clientSetProvider, imageFactory, func(set k8s.ClientSet) imgcmds.ImageWaiter {
 				return fakeImageWaiter
-			})
+			}, func(set k8s.ClientSet) imgcmds.CredentialChecker {
+				return fakeCredentialChecker
+			}, fakeCredentialHandOff)
 		}
 
 		when("a namespace is provided", func() {
@@ -661,12 +665,16 @@ status: {}
 			SourceUploader: sourceUploader,
 		}
 		fakeImageWaiter := &fakes.FakeImageWaiter{}
+		fakeCredentialChecker := &fakes.FakeCredentialChecker{}
+		fakeCredentialHandOff := &fakes.FakeCredentialHandOff{}
 
 		cmdFunc := func(clientSet *fake.Clientset) *cobra.Command {
 			clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
 			return imgcmds.NewPatchCommand(clientSetProvider, patchFactory, func(set k8s.ClientSet) imgcmds.ImageWaiter {
 				return fakeImageWaiter
-			})
+			}, func(set k8s.ClientSet) imgcmds.CredentialChecker {
+				return fakeCredentialChecker
+			}, fakeCredentialHandOff)
 		}
 
 		img := &v1alpha1.Image{
@@ -76,6 +76,18 @@ func displayImageStatus(cmd *cobra.Command, image *v1alpha1.Image, builds []v1al
 		return err
 	}
 
+	if record, ok := getRollbackRecord(image, builds); ok {
+		err = statusWriter.AddBlock(
+			"Rollback",
+			"Pinned To Build", record.Build,
+			"Image", record.Image,
+			"Rolled Back At", record.Time,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = statusWriter.AddBlock(
 		"Last Successful Build",
 		"Id", getId(successfulBuild),
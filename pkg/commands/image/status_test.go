@@ -135,6 +135,56 @@ Build Reason:    COMMIT,BUILDPACK
 				}.TestKpack(t, cmdFunc)
 			})
 
+			when("the image was rolled back", func() {
+				it("shows the build the tag is pinned to", func() {
+					image := &v1alpha1.Image{
+						ObjectMeta: v1.ObjectMeta{
+							Name:      imageName,
+							Namespace: defaultNamespace,
+							Annotations: map[string]string{
+								"kpack.io/rollback": `{"build":"1","image":"repo.com/image-1:tag","latestBuild":"3","time":"2020-01-02T00:00:00Z"}`,
+							},
+						},
+						Status: v1alpha1.ImageStatus{
+							Status: corev1alpha1.Status{
+								Conditions: []corev1alpha1.Condition{
+									{
+										Type:   corev1alpha1.ConditionReady,
+										Status: corev1.ConditionTrue,
+									},
+								},
+							},
+							LatestImage: "test-registry.io/test-image-1@sha256:abcdef123",
+						},
+					}
+
+					const expectedOutput = `Status:         Ready
+Message:        --
+LatestImage:    test-registry.io/test-image-1@sha256:abcdef123
+
+Rollback
+Pinned To Build:    1
+Image:              repo.com/image-1:tag
+Rolled Back At:     2020-01-02T00:00:00Z
+
+Last Successful Build
+Id:              1
+Build Reason:    CONFIG
+
+Last Failed Build
+Id:              2
+Build Reason:    COMMIT,BUILDPACK
+
+`
+
+					testhelpers.CommandTest{
+						Objects:        append([]runtime.Object{image}, testBuilds...),
+						Args:           []string{imageName},
+						ExpectedOutput: expectedOutput,
+					}.TestKpack(t, cmdFunc)
+				})
+			})
+
 			when("the namespace has no images", func() {
 				it("returns a message that the namespace has no images", func() {
 					testhelpers.CommandTest{
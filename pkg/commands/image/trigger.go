@@ -4,68 +4,319 @@
 package image
 
 import (
+	"context"
 	"fmt"
 	"sort"
-	"time"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/pivotal/build-service-cli/pkg/build"
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 )
 
-const BuildNeededAnnotation = "image.kpack.io/additionalBuildNeeded"
+const BuildNeededAnnotation = build.BuildNeededAnnotation
 
-func NewTriggerCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+type ConfirmationProvider interface {
+	Confirm(message string, okayResponses ...string) (bool, error)
+}
+
+func NewTriggerCommand(clientSetProvider k8s.ClientSetProvider, confirmationProvider ConfirmationProvider, newImageWaiter func(k8s.ClientSet) ImageWaiter) *cobra.Command {
 	var (
-		namespace string
+		namespace      string
+		selector       string
+		builder        string
+		clusterBuilder string
+		all            bool
+		force          bool
+		wait           bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "trigger <name>",
-		Short: "Trigger an image build",
-		Long: `Trigger a build using current inputs for a specific image in the provided namespace.
+		Use:   "trigger [<name>...]",
+		Short: "Trigger image builds",
+		Long: `Trigger a build using current inputs for specific images in the provided namespace.
+
+Images may also be selected in bulk with a label selector, by the builder or cluster builder they use, or with "--all".
+Bulk triggers must be confirmed unless "--force" is used.
+
+Images that have not been built yet are skipped, kpack schedules their first build once their builder and source are ready.
+Use "--wait" to tail the logs of the triggered builds and report the outcome for each image.
 
 The namespace defaults to the kubernetes current-context namespace.`,
-		Example: "kp image trigger my-image",
-		Args:    commands.ExactArgsWithUsage(1),
+		Example: `kp image trigger my-image
+kp image trigger my-image my-other-image --wait
+kp image trigger -l team=my-team
+kp image trigger --cluster-builder default -n my-namespace
+kp image trigger --all --force`,
+		Args:         cobra.ArbitraryArgs,
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			bulk := selector != "" || builder != "" || clusterBuilder != "" || all
+
+			switch {
+			case len(args) == 0 && !bulk:
+				return errors.New("an image name, selector, builder, cluster builder or --all is required")
+			case len(args) > 0 && bulk:
+				return errors.New("image names cannot be used with a selector, builder, cluster builder or --all")
+			case all && (selector != "" || builder != "" || clusterBuilder != ""):
+				return errors.New("--all cannot be used with a selector, builder or cluster builder")
+			case builder != "" && clusterBuilder != "":
+				return errors.New("a builder and cluster builder cannot be used together")
+			}
+
 			cs, err := clientSetProvider.GetClientSet(namespace)
 			if err != nil {
 				return err
 			}
 
-			buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
-				LabelSelector: v1alpha1.ImageLabel + "=" + args[0],
-			})
+			images, err := selectImages(cs, args, selector, builder, clusterBuilder)
 			if err != nil {
 				return err
 			}
 
-			if len(buildList.Items) == 0 {
-				return errors.New("no builds found")
-			} else {
-				sort.Slice(buildList.Items, build.Sort(buildList.Items))
+			if len(images) == 0 {
+				_, err := fmt.Fprintln(cmd.OutOrStderr(), "No images found")
+				return err
+			}
 
-				build := buildList.Items[len(buildList.Items)-1].DeepCopy()
-				build.Annotations[BuildNeededAnnotation] = time.Now().String()
-				_, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).Update(build)
+			if bulk && !force {
+				message := fmt.Sprintf("Please confirm triggering builds of %d image(s) by typing 'y': ", len(images))
+				confirmed, err := confirmationProvider.Confirm(message)
 				if err != nil {
 					return err
 				}
 
-				_, err = fmt.Fprintf(cmd.OutOrStderr(), "Triggered build for Image %q\n", args[0])
-				return err
+				if !confirmed {
+					_, err = fmt.Fprintln(cmd.OutOrStderr(), "Skipping image trigger")
+					return err
+				}
+			}
+
+			var triggered, skipped []v1alpha1.Image
+			for _, img := range images {
+				ok, err := triggerImage(cs, img)
+				if err != nil {
+					return err
+				}
+
+				if !ok {
+					skipped = append(skipped, img)
+					_, err = fmt.Fprintln(cmd.OutOrStderr(), notBuiltMessage(img))
+				} else {
+					triggered = append(triggered, img)
+					_, err = fmt.Fprintf(cmd.OutOrStderr(), "Triggered build for Image %q\n", img.Name)
+				}
+				if err != nil {
+					return err
+				}
 			}
+
+			if !wait {
+				return nil
+			}
+
+			return waitForTriggeredBuilds(cmd, cs, newImageWaiter(cs), triggered, skipped)
 		},
-		SilenceUsage: true,
 	}
 
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "label selector of the images to trigger")
+	cmd.Flags().StringVar(&builder, "builder", "", "trigger images that use this builder")
+	cmd.Flags().StringVar(&clusterBuilder, "cluster-builder", "", "trigger images that use this cluster builder")
+	cmd.Flags().BoolVar(&all, "all", false, "trigger all images in the namespace")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "trigger without confirmation")
+	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "wait for the triggered builds to finish and tail their logs")
 
 	return cmd
 }
+
+// selectImages returns the named images, or the images matching the selector and builder sorted by name.
+func selectImages(cs k8s.ClientSet, names []string, selector, builder, clusterBuilder string) ([]v1alpha1.Image, error) {
+	var images []v1alpha1.Image
+
+	if len(names) > 0 {
+		for _, name := range names {
+			img, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, *img)
+		}
+		return images, nil
+	}
+
+	imageList, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).List(metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, img := range imageList.Items {
+		if builder != "" && (img.Spec.Builder.Kind != v1alpha1.BuilderKind || img.Spec.Builder.Name != builder) {
+			continue
+		}
+
+		if clusterBuilder != "" && (img.Spec.Builder.Kind != v1alpha1.ClusterBuilderKind || img.Spec.Builder.Name != clusterBuilder) {
+			continue
+		}
+
+		images = append(images, img)
+	}
+
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Name < images[j].Name
+	})
+	return images, nil
+}
+
+// triggerImage annotates the latest build of the image so that kpack schedules a new build.
+// It returns false when the image has no builds to trigger.
+func triggerImage(cs k8s.ClientSet, img v1alpha1.Image) (bool, error) {
+	buildList, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).List(metav1.ListOptions{
+		LabelSelector: v1alpha1.ImageLabel + "=" + img.Name,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if len(buildList.Items) == 0 {
+		return false, nil
+	}
+
+	sort.Slice(buildList.Items, build.Sort(buildList.Items))
+
+	bld := build.RequestBuild(buildList.Items[len(buildList.Items)-1])
+
+	if _, err := cs.KpackClient.KpackV1alpha1().Builds(cs.Namespace).Update(bld); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func notBuiltMessage(img v1alpha1.Image) string {
+	if reason := notBuiltReason(img); reason != "" {
+		return fmt.Sprintf("Skipping Image %q, it has not been built yet: %s", img.Name, reason)
+	}
+	return fmt.Sprintf("Skipping Image %q, it has not been built yet", img.Name)
+}
+
+func notBuiltReason(img v1alpha1.Image) string {
+	cond := img.Status.GetCondition(corev1alpha1.ConditionReady)
+	if cond == nil {
+		return ""
+	}
+	return cond.Message
+}
+
+// waitForTriggeredBuilds waits for the build scheduled for each triggered image, tailing its logs,
+// and reports the outcome for every image including the skipped ones.
+func waitForTriggeredBuilds(cmd *cobra.Command, cs k8s.ClientSet, imageWaiter ImageWaiter, images, skipped []v1alpha1.Image) error {
+	type outcome struct {
+		image  string
+		status string
+		detail string
+	}
+
+	var (
+		outcomes []outcome
+		failed   int
+	)
+
+	for _, img := range images {
+		latestImage, err := waitForTriggeredBuild(cmd, cs, imageWaiter, img)
+		if err != nil {
+			failed++
+			outcomes = append(outcomes, outcome{image: img.Name, status: "FAILURE", detail: err.Error()})
+			continue
+		}
+		outcomes = append(outcomes, outcome{image: img.Name, status: "SUCCESS", detail: latestImage})
+	}
+
+	for _, img := range skipped {
+		detail := "not built yet"
+		if reason := notBuiltReason(img); reason != "" {
+			detail = "not built yet: " + reason
+		}
+		outcomes = append(outcomes, outcome{image: img.Name, status: "SKIPPED", detail: detail})
+	}
+
+	writer, err := commands.NewTableWriter(cmd.OutOrStdout(), "Image", "Status", "Details")
+	if err != nil {
+		return err
+	}
+
+	for _, o := range outcomes {
+		if err := writer.AddRow(o.image, o.status, o.detail); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Write(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d of %d triggered builds did not succeed", failed, len(images))
+	}
+	return nil
+}
+
+func waitForTriggeredBuild(cmd *cobra.Command, cs k8s.ClientSet, imageWaiter ImageWaiter, img v1alpha1.Image) (string, error) {
+	scheduled, err := waitForNewBuild(cmd.Context(), cs, img)
+	if err != nil {
+		return "", err
+	}
+
+	return imageWaiter.Wait(cmd.Context(), cmd.OutOrStdout(), scheduled)
+}
+
+// waitForNewBuild waits until kpack has scheduled a build for the image other than its latest build at trigger time.
+func waitForNewBuild(ctx context.Context, cs k8s.ClientSet, img v1alpha1.Image) (*v1alpha1.Image, error) {
+	scheduled := func(current *v1alpha1.Image) bool {
+		return current.Status.LatestBuildRef != "" && current.Status.LatestBuildRef != img.Status.LatestBuildRef
+	}
+
+	watcher, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Watch(metav1.ListOptions{
+		FieldSelector: "metadata.name=" + img.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer watcher.Stop()
+
+	current, err := cs.KpackClient.KpackV1alpha1().Images(cs.Namespace).Get(img.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if scheduled(current) {
+		return current, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil, errors.Errorf("watch of image %q closed unexpectedly", img.Name)
+			}
+
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+
+			current, ok := event.Object.(*v1alpha1.Image)
+			if ok && current.Name == img.Name && scheduled(current) {
+				return current, nil
+			}
+		}
+	}
+}
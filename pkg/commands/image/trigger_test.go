@@ -5,14 +5,22 @@ package image_test
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1alpha1 "github.com/pivotal/kpack/pkg/apis/core/v1alpha1"
 	"github.com/pivotal/kpack/pkg/client/clientset/versioned/fake"
 	"github.com/sclevine/spec"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
 
 	"github.com/pivotal/build-service-cli/pkg/commands/image"
+	"github.com/pivotal/build-service-cli/pkg/image/fakes"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
 )
 
@@ -26,21 +34,61 @@ func testImageTrigger(t *testing.T, when spec.G, it spec.S) {
 		namespace        = "some-namespace"
 	)
 
-	testBuilds := testhelpers.MakeTestBuilds("some-image", defaultNamespace)
-	testNamespacedBuilds := testhelpers.MakeTestBuilds("some-image", namespace)
+	makeImage := func(name, namespace, builderKind, builderName string) *v1alpha1.Image {
+		return &v1alpha1.Image{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{"team": "some-team"},
+			},
+			Spec: v1alpha1.ImageSpec{
+				Builder: corev1.ObjectReference{
+					Kind: builderKind,
+					Name: builderName,
+				},
+			},
+			Status: v1alpha1.ImageStatus{
+				LatestBuildRef: "build-three",
+			},
+		}
+	}
+
+	testBuilds := append(testhelpers.MakeTestBuilds("some-image", defaultNamespace),
+		makeImage("some-image", defaultNamespace, v1alpha1.ClusterBuilderKind, "default"))
+	testNamespacedBuilds := append(testhelpers.MakeTestBuilds("some-image", namespace),
+		makeImage("some-image", namespace, v1alpha1.ClusterBuilderKind, "default"))
+
+	var (
+		confirmationProvider *FakeConfirmationProvider
+		imageWaiter          *fakes.FakeImageWaiter
+	)
+
+	it.Before(func() {
+		confirmationProvider = &FakeConfirmationProvider{confirm: true}
+		imageWaiter = &fakes.FakeImageWaiter{}
+	})
+
+	newTriggerCommand := func(clientSet *fake.Clientset) (*bytes.Buffer, func(args ...string) error) {
+		clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
+		cmd := image.NewTriggerCommand(clientSetProvider, confirmationProvider, func(k8s.ClientSet) image.ImageWaiter {
+			return imageWaiter
+		})
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		return out, func(args ...string) error {
+			cmd.SetArgs(args)
+			return cmd.Execute()
+		}
+	}
 
 	when("a namespace is provided", func() {
 		when("an image build is available", func() {
 			it("triggers the latest build", func() {
 				clientSet := fake.NewSimpleClientset(testNamespacedBuilds...)
-				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-				cmd := image.NewTriggerCommand(clientSetProvider)
-
-				out := &bytes.Buffer{}
-				cmd.SetOut(out)
-				cmd.SetArgs([]string{"some-image", "-n", namespace})
+				out, execute := newTriggerCommand(clientSet)
 
-				err := cmd.Execute()
+				err := execute("some-image", "-n", namespace)
 				require.NoError(t, err)
 				require.Equal(t, "Triggered build for Image \"some-image\"\n", out.String())
 
@@ -51,21 +99,41 @@ func testImageTrigger(t *testing.T, when spec.G, it spec.S) {
 				build := actions.Updates[0].GetObject().(*v1alpha1.Build)
 				require.Equal(t, build.Name, "build-three")
 				require.NotEmpty(t, build.Annotations[image.BuildNeededAnnotation])
+				require.False(t, confirmationProvider.requested)
 			})
 		})
 
 		when("an image build is not available", func() {
+			it("skips the image", func() {
+				img := makeImage("some-image", namespace, v1alpha1.ClusterBuilderKind, "default")
+				img.Status.Conditions = corev1alpha1.Conditions{
+					{
+						Type:    corev1alpha1.ConditionReady,
+						Status:  corev1.ConditionUnknown,
+						Message: "Builder default is not ready",
+					},
+				}
+
+				clientSet := fake.NewSimpleClientset(img)
+				out, execute := newTriggerCommand(clientSet)
+
+				err := execute("some-image", "-n", namespace)
+				require.NoError(t, err)
+				require.Equal(t, "Skipping Image \"some-image\", it has not been built yet: Builder default is not ready\n", out.String())
+
+				actions, err := testhelpers.ActionRecorderList{clientSet}.ActionsByVerb()
+				require.NoError(t, err)
+				require.Len(t, actions.Updates, 0)
+			})
+		})
+
+		when("the image does not exist", func() {
 			it("returns an error", func() {
 				clientSet := fake.NewSimpleClientset()
-				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-				cmd := image.NewTriggerCommand(clientSetProvider)
+				_, execute := newTriggerCommand(clientSet)
 
-				out := &bytes.Buffer{}
-				cmd.SetOut(out)
-				cmd.SetArgs([]string{"some-image", "-n", namespace})
-
-				err := cmd.Execute()
-				require.EqualError(t, err, "no builds found")
+				err := execute("some-image", "-n", namespace)
+				require.EqualError(t, err, "images.kpack.io \"some-image\" not found")
 			})
 		})
 	})
@@ -74,14 +142,9 @@ func testImageTrigger(t *testing.T, when spec.G, it spec.S) {
 		when("an image build is available", func() {
 			it("triggers the latest build", func() {
 				clientSet := fake.NewSimpleClientset(testBuilds...)
-				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-				cmd := image.NewTriggerCommand(clientSetProvider)
-
-				out := &bytes.Buffer{}
-				cmd.SetOut(out)
-				cmd.SetArgs([]string{"some-image", "-n", defaultNamespace})
+				out, execute := newTriggerCommand(clientSet)
 
-				err := cmd.Execute()
+				err := execute("some-image")
 				require.NoError(t, err)
 				require.Equal(t, "Triggered build for Image \"some-image\"\n", out.String())
 
@@ -96,18 +159,172 @@ func testImageTrigger(t *testing.T, when spec.G, it spec.S) {
 		})
 
 		when("an image build is not available", func() {
-			it("returns an error", func() {
-				clientSet := fake.NewSimpleClientset()
-				clientSetProvider := testhelpers.GetFakeKpackProvider(clientSet, defaultNamespace)
-				cmd := image.NewTriggerCommand(clientSetProvider)
+			it("skips the image", func() {
+				clientSet := fake.NewSimpleClientset(makeImage("some-image", defaultNamespace, v1alpha1.ClusterBuilderKind, "default"))
+				out, execute := newTriggerCommand(clientSet)
+
+				err := execute("some-image")
+				require.NoError(t, err)
+				require.Equal(t, "Skipping Image \"some-image\", it has not been built yet\n", out.String())
+			})
+		})
+	})
+
+	when("triggering images in bulk", func() {
+		var objects []runtime.Object
+
+		it.Before(func() {
+			objects = append(testhelpers.MakeTestBuilds("some-image", defaultNamespace),
+				makeImage("some-image", defaultNamespace, v1alpha1.ClusterBuilderKind, "default"),
+				makeImage("other-image", defaultNamespace, v1alpha1.BuilderKind, "some-builder"),
+			)
+		})
+
+		it("triggers images matching the selector after confirmation", func() {
+			clientSet := fake.NewSimpleClientset(objects...)
+			out, execute := newTriggerCommand(clientSet)
+
+			err := execute("-l", "team=some-team")
+			require.NoError(t, err)
+			require.Equal(t, `Skipping Image "other-image", it has not been built yet
+Triggered build for Image "some-image"
+`, out.String())
+			require.True(t, confirmationProvider.requested)
+		})
+
+		it("triggers images using the cluster builder", func() {
+			clientSet := fake.NewSimpleClientset(objects...)
+			out, execute := newTriggerCommand(clientSet)
+
+			err := execute("--cluster-builder", "default")
+			require.NoError(t, err)
+			require.Equal(t, "Triggered build for Image \"some-image\"\n", out.String())
+		})
 
-				out := &bytes.Buffer{}
-				cmd.SetOut(out)
-				cmd.SetArgs([]string{"some-image", "-n", defaultNamespace})
+		it("triggers images using the builder", func() {
+			clientSet := fake.NewSimpleClientset(objects...)
+			out, execute := newTriggerCommand(clientSet)
 
-				err := cmd.Execute()
-				require.EqualError(t, err, "no builds found")
+			err := execute("--builder", "some-builder", "--force")
+			require.NoError(t, err)
+			require.Equal(t, "Skipping Image \"other-image\", it has not been built yet\n", out.String())
+			require.False(t, confirmationProvider.requested)
+		})
+
+		it("does not trigger builds when confirmation is declined", func() {
+			confirmationProvider.confirm = false
+			clientSet := fake.NewSimpleClientset(objects...)
+			out, execute := newTriggerCommand(clientSet)
+
+			err := execute("--all")
+			require.NoError(t, err)
+			require.Equal(t, "Skipping image trigger\n", out.String())
+
+			actions, err := testhelpers.ActionRecorderList{clientSet}.ActionsByVerb()
+			require.NoError(t, err)
+			require.Len(t, actions.Updates, 0)
+		})
+
+		it("prints a message when no images match", func() {
+			clientSet := fake.NewSimpleClientset(objects...)
+			out, execute := newTriggerCommand(clientSet)
+
+			err := execute("--cluster-builder", "other")
+			require.NoError(t, err)
+			require.Equal(t, "No images found\n", out.String())
+			require.False(t, confirmationProvider.requested)
+		})
+
+		it("errors when image names and --all are used together", func() {
+			clientSet := fake.NewSimpleClientset(objects...)
+			_, execute := newTriggerCommand(clientSet)
+
+			err := execute("some-image", "--all")
+			require.EqualError(t, err, "image names cannot be used with a selector, builder, cluster builder or --all")
+		})
+
+		it("errors when no images are selected", func() {
+			clientSet := fake.NewSimpleClientset(objects...)
+			_, execute := newTriggerCommand(clientSet)
+
+			err := execute()
+			require.EqualError(t, err, "an image name, selector, builder, cluster builder or --all is required")
+		})
+	})
+
+	when("waiting for the triggered builds", func() {
+		var clientSet *fake.Clientset
+
+		it.Before(func() {
+			clientSet = fake.NewSimpleClientset(append(testhelpers.MakeTestBuilds("some-image", defaultNamespace),
+				makeImage("some-image", defaultNamespace, v1alpha1.ClusterBuilderKind, "default"))...)
+
+			// simulate kpack scheduling a new build when the latest build is annotated
+			clientSet.PrependReactor("update", "builds", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				img := makeImage("some-image", defaultNamespace, v1alpha1.ClusterBuilderKind, "default")
+				img.Status.LatestBuildRef = "build-four"
+				img.Status.LatestImage = "some-registry.io/some-image@sha256:123"
+				return false, nil, clientSet.Tracker().Update(v1alpha1.SchemeGroupVersion.WithResource("images"), img, defaultNamespace)
 			})
 		})
+
+		it("waits for the new build and reports the outcome", func() {
+			out, execute := newTriggerCommand(clientSet)
+
+			err := execute("some-image", "--wait")
+			require.NoError(t, err)
+			require.Equal(t, `Triggered build for Image "some-image"
+IMAGE         STATUS     DETAILS
+some-image    SUCCESS    some-registry.io/some-image@sha256:123
+
+`, out.String())
+
+			require.Len(t, imageWaiter.Calls, 1)
+			require.Equal(t, "build-four", imageWaiter.Calls[0].Status.LatestBuildRef)
+		})
+
+		it("reports images that were skipped", func() {
+			require.NoError(t, clientSet.Tracker().Add(makeImage("other-image", defaultNamespace, v1alpha1.ClusterBuilderKind, "default")))
+			out, execute := newTriggerCommand(clientSet)
+
+			err := execute("some-image", "other-image", "--wait")
+			require.NoError(t, err)
+			require.Equal(t, `Triggered build for Image "some-image"
+Skipping Image "other-image", it has not been built yet
+IMAGE          STATUS     DETAILS
+some-image     SUCCESS    some-registry.io/some-image@sha256:123
+other-image    SKIPPED    not built yet
+
+`, out.String())
+
+			require.Len(t, imageWaiter.Calls, 1)
+		})
+
+		it("returns an error when a build does not succeed", func() {
+			imageWaiter.Errors = map[string]error{"some-image": errors.New("update to image failed")}
+			out, execute := newTriggerCommand(clientSet)
+
+			err := execute("some-image", "--wait")
+			require.EqualError(t, err, "1 of 1 triggered builds did not succeed")
+			require.Equal(t, `Triggered build for Image "some-image"
+IMAGE         STATUS     DETAILS
+some-image    FAILURE    update to image failed
+
+Error: 1 of 1 triggered builds did not succeed
+`, out.String())
+		})
 	})
 }
+
+type FakeConfirmationProvider struct {
+	// return values for confirm request
+	confirm bool
+	err     error
+	// tracks if confirmation was requested
+	requested bool
+}
+
+func (f *FakeConfirmationProvider) Confirm(_ string, _ ...string) (bool, error) {
+	f.requested = true
+	return f.confirm, f.err
+}
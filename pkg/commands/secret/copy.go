@@ -0,0 +1,272 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+const (
+	CopiedFromAnnotationKey = "kpack.io/copiedFrom"
+
+	lastAppliedConfigAnnotationKey = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+func NewCopyCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	var (
+		fromNamespace   string
+		toNamespaces    []string
+		selector        string
+		serviceAccounts []string
+		sync            bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "copy <name>",
+		Short: "Copy a secret to other namespaces",
+		Long: `Copy a secret to other namespaces and link it to their service accounts.
+
+The source namespace defaults to the kubernetes current-context namespace.
+The target namespaces are provided with "--to-namespace", or selected by label with "--selector".
+The copies are linked to the "default" service account of each target namespace unless "--service-account" is provided.
+
+Copies record their source in the "kpack.io/copiedFrom" annotation.
+Existing copies are left untouched unless "--sync" is used, in which case they are updated to match the source.
+Syncing keeps the labels and annotations that were added to a copy and are not on the source.
+Copies cannot be synced when the type of the source has changed, they must be deleted and copied again.
+Secrets in the target namespaces that were not copied from the source are never modified.
+A namespace the secret cannot be copied to is reported and the remaining namespaces are still processed.`,
+		Example: `kp secret copy my-registry-cred --to-namespace team-a
+kp secret copy my-registry-cred --from-namespace shared --to-namespace team-a,team-b
+kp secret copy my-git-cred --selector team=build --service-account build-sa
+kp secret copy my-registry-cred --selector team=build --sync`,
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(toNamespaces) == 0 && selector == "" {
+				return errors.New("a target namespace or selector is required")
+			}
+
+			cs, err := clientSetProvider.GetClientSet(fromNamespace)
+			if err != nil {
+				return err
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			source, err := cs.K8sClient.CoreV1().Secrets(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			namespaces, err := targetNamespaces(cs, toNamespaces, selector)
+			if err != nil {
+				return err
+			}
+
+			if len(namespaces) == 0 {
+				return errors.Errorf("no namespaces found matching selector %q", selector)
+			}
+
+			failed := 0
+			for _, namespace := range namespaces {
+				targetCs := cs
+				targetCs.Namespace = namespace
+
+				if err := copySecret(ch, targetCs, source, serviceAccounts, sync); err != nil {
+					failed++
+					if err := ch.Printlnf("Error copying secret %q to namespace %q: %s", source.Name, namespace, err); err != nil {
+						return err
+					}
+				}
+			}
+
+			if failed > 0 {
+				return errors.Errorf("secret %q could not be copied to %d of %d namespaces", source.Name, failed, len(namespaces))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&fromNamespace, "from-namespace", "", "kubernetes namespace of the secret to copy")
+	cmd.Flags().StringSliceVar(&toNamespaces, "to-namespace", nil, "kubernetes namespaces to copy the secret to")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "label selector of the namespaces to copy the secret to")
+	setServiceAccountFlag(cmd, &serviceAccounts, "service account of the target namespaces to link the copies to")
+	cmd.Flags().BoolVar(&sync, "sync", false, "update existing copies to match the source secret")
+	commands.SetDryRunOutputFlags(cmd)
+	return cmd
+}
+
+// targetNamespaces returns the provided namespaces followed by the namespaces matching the selector,
+// excluding the namespace of the source secret.
+func targetNamespaces(cs k8s.ClientSet, names []string, selector string) ([]string, error) {
+	seen := map[string]bool{cs.Namespace: true}
+	var namespaces []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			namespaces = append(namespaces, name)
+		}
+	}
+
+	if selector == "" {
+		return namespaces, nil
+	}
+
+	list, err := cs.K8sClient.CoreV1().Namespaces().List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []string
+	for _, namespace := range list.Items {
+		if !seen[namespace.Name] {
+			seen[namespace.Name] = true
+			selected = append(selected, namespace.Name)
+		}
+	}
+	sort.Strings(selected)
+
+	return append(namespaces, selected...), nil
+}
+
+func copySecret(ch *commands.CommandHelper, cs k8s.ClientSet, source *corev1.Secret, serviceAccounts []string, sync bool) error {
+	serviceAccountList, err := getServiceAccounts(cs, serviceAccounts)
+	if err != nil {
+		return err
+	}
+
+	copied := makeSecretCopy(source, cs.Namespace)
+
+	existing, err := cs.K8sClient.CoreV1().Secrets(cs.Namespace).Get(source.Name, metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		if !ch.IsDryRun() {
+			copied, err = cs.K8sClient.CoreV1().Secrets(cs.Namespace).Create(copied)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := ch.PrintObj(copied); err != nil {
+			return err
+		}
+
+		if err := ch.PrintStatus("Secret %q copied to namespace %q", copied.Name, cs.Namespace); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case existing.Annotations[CopiedFromAnnotationKey] != copied.Annotations[CopiedFromAnnotationKey]:
+		return errors.Errorf("secret %q already exists in namespace %q and was not copied from %q", existing.Name, cs.Namespace, copied.Annotations[CopiedFromAnnotationKey])
+	case !sync:
+		copied = existing
+		if err := ch.Printlnf("Secret %q already exists in namespace %q, use --sync to update it", existing.Name, cs.Namespace); err != nil {
+			return err
+		}
+	case existing.Type != copied.Type:
+		return errors.Errorf("cannot sync secret %q to namespace %q because its type changed from %q to %q, delete the copy with \"kp secret delete %s -n %s\" and copy it again",
+			existing.Name, cs.Namespace, existing.Type, copied.Type, existing.Name, cs.Namespace)
+	default:
+		updated := existing.DeepCopy()
+		updated.Labels = mergeMaps(updated.Labels, copied.Labels)
+		updated.Annotations = mergeMaps(updated.Annotations, copied.Annotations)
+		updated.Data = copied.Data
+		updated.StringData = nil
+
+		changed := !reflect.DeepEqual(existing, updated)
+		if changed && !ch.IsDryRun() {
+			updated, err = cs.K8sClient.CoreV1().Secrets(cs.Namespace).Update(updated)
+			if err != nil {
+				return err
+			}
+		}
+		copied = updated
+
+		if err := ch.PrintObj(copied); err != nil {
+			return err
+		}
+
+		if err := ch.PrintChangeResult(changed, "Secret %q synced to namespace %q", copied.Name, cs.Namespace); err != nil {
+			return err
+		}
+	}
+
+	target := secret.Target(copied)
+	for _, serviceAccount := range serviceAccountList {
+		original := serviceAccount.DeepCopy()
+		if err := linkSecret(serviceAccount, copied, target); err != nil {
+			return err
+		}
+
+		if reflect.DeepEqual(original, serviceAccount) {
+			continue
+		}
+
+		if !ch.IsDryRun() {
+			serviceAccount, err = cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Update(serviceAccount)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := ch.PrintObj(serviceAccount); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// makeSecretCopy returns a copy of the source secret in the target namespace, annotated with its source.
+func makeSecretCopy(source *corev1.Secret, namespace string) *corev1.Secret {
+	annotations := map[string]string{}
+	for key, value := range source.Annotations {
+		if key != lastAppliedConfigAnnotationKey {
+			annotations[key] = value
+		}
+	}
+	annotations[CopiedFromAnnotationKey] = source.Namespace + "/" + source.Name
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        source.Name,
+			Namespace:   namespace,
+			Labels:      source.Labels,
+			Annotations: annotations,
+		},
+		Data: source.Data,
+		Type: source.Type,
+	}
+}
+
+// mergeMaps returns the entries of existing overridden by the entries of source.
+func mergeMaps(existing, source map[string]string) map[string]string {
+	if len(existing) == 0 && len(source) == 0 {
+		return existing
+	}
+
+	merged := map[string]string{}
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range source {
+		merged[key] = value
+	}
+	return merged
+}
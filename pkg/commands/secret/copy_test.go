@@ -0,0 +1,269 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	secretcmds "github.com/pivotal/build-service-cli/pkg/commands/secret"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestSecretCopyCommand(t *testing.T) {
+	spec.Run(t, "TestSecretCopyCommand", testSecretCopyCommand)
+}
+
+func testSecretCopyCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		secretName       = "some-secret"
+		dockerConfigJson = `{"auths":{"some-registry.io":{"username":"some-user","password":"some-password"}}}`
+
+		staleDockerConfigJson = `{"auths":{"some-registry.io":{"username":"old-user","password":"old-password"}}}`
+	)
+
+	cmdFunc := func(k8sClient *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeK8sProvider(k8sClient, defaultNamespace)
+		return secretcmds.NewCopyCommand(clientSetProvider)
+	}
+
+	makeSecret := func(namespace, config string, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        secretName,
+				Namespace:   namespace,
+				Annotations: annotations,
+			},
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(config),
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+		}
+	}
+
+	copyOf := func(namespace, config string) *corev1.Secret {
+		return makeSecret(namespace, config, map[string]string{
+			secretcmds.CopiedFromAnnotationKey: defaultNamespace + "/" + secretName,
+		})
+	}
+
+	serviceAccount := func(namespace string) *corev1.ServiceAccount {
+		return &corev1.ServiceAccount{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "default",
+				Namespace: namespace,
+			},
+		}
+	}
+
+	linkedServiceAccount := func(namespace string) *corev1.ServiceAccount {
+		sa := serviceAccount(namespace)
+		sa.Annotations = map[string]string{
+			secretcmds.ManagedSecretAnnotationKey: `{"some-secret":"some-registry.io"}`,
+		}
+		sa.Secrets = []corev1.ObjectReference{{Name: secretName}}
+		sa.ImagePullSecrets = []corev1.LocalObjectReference{{Name: secretName}}
+		return sa
+	}
+
+	source := makeSecret(defaultNamespace, dockerConfigJson, nil)
+
+	it("copies the secret to the target namespaces and links it to their service accounts", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				source,
+				serviceAccount("team-a"),
+				serviceAccount("team-b"),
+			},
+			Args: []string{secretName, "--to-namespace", "team-a,team-b"},
+			ExpectCreates: []runtime.Object{
+				copyOf("team-a", dockerConfigJson),
+				copyOf("team-b", dockerConfigJson),
+			},
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: linkedServiceAccount("team-a")},
+				{Object: linkedServiceAccount("team-b")},
+			},
+			ExpectedOutput: `Secret "some-secret" copied to namespace "team-a"
+Secret "some-secret" copied to namespace "team-b"
+`,
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("copies the secret from the provided namespace", func() {
+		otherSource := makeSecret("shared", dockerConfigJson, nil)
+		expectedCopy := copyOf("team-a", dockerConfigJson)
+		expectedCopy.Annotations[secretcmds.CopiedFromAnnotationKey] = "shared/" + secretName
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				otherSource,
+				serviceAccount("team-a"),
+			},
+			Args:          []string{secretName, "--from-namespace", "shared", "--to-namespace", "team-a"},
+			ExpectCreates: []runtime.Object{expectedCopy},
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: linkedServiceAccount("team-a")},
+			},
+			ExpectedOutput: "Secret \"some-secret\" copied to namespace \"team-a\"\n",
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("copies the secret to the namespaces matching the selector", func() {
+		namespace := func(name string, labels map[string]string) *corev1.Namespace {
+			return &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: name, Labels: labels}}
+		}
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				source,
+				namespace("team-a", map[string]string{"team": "build"}),
+				namespace("team-b", map[string]string{"team": "other"}),
+				namespace(defaultNamespace, map[string]string{"team": "build"}),
+				serviceAccount("team-a"),
+			},
+			Args:          []string{secretName, "--selector", "team=build"},
+			ExpectCreates: []runtime.Object{copyOf("team-a", dockerConfigJson)},
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: linkedServiceAccount("team-a")},
+			},
+			ExpectedOutput: "Secret \"some-secret\" copied to namespace \"team-a\"\n",
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("leaves existing copies untouched without --sync", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				source,
+				copyOf("team-a", staleDockerConfigJson),
+				linkedServiceAccount("team-a"),
+			},
+			Args:           []string{secretName, "--to-namespace", "team-a"},
+			ExpectedOutput: "Secret \"some-secret\" already exists in namespace \"team-a\", use --sync to update it\n",
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("updates existing copies with --sync", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				source,
+				copyOf("team-a", staleDockerConfigJson),
+				copyOf("team-b", dockerConfigJson),
+				linkedServiceAccount("team-a"),
+				linkedServiceAccount("team-b"),
+			},
+			Args: []string{secretName, "--to-namespace", "team-a,team-b", "--sync"},
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: copyOf("team-a", dockerConfigJson)},
+			},
+			ExpectedOutput: `Secret "some-secret" synced to namespace "team-a"
+Secret "some-secret" synced to namespace "team-b" (no change)
+`,
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("keeps the labels and annotations added to copies with --sync", func() {
+		staleCopy := copyOf("team-a", staleDockerConfigJson)
+		staleCopy.Labels = map[string]string{"some-controller/label": "value"}
+		staleCopy.Annotations["some-controller/annotation"] = "value"
+
+		expectedCopy := copyOf("team-a", dockerConfigJson)
+		expectedCopy.Labels = map[string]string{"some-controller/label": "value"}
+		expectedCopy.Annotations["some-controller/annotation"] = "value"
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				source,
+				staleCopy,
+				linkedServiceAccount("team-a"),
+			},
+			Args: []string{secretName, "--to-namespace", "team-a", "--sync"},
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: expectedCopy},
+			},
+			ExpectedOutput: "Secret \"some-secret\" synced to namespace \"team-a\"\n",
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("errors when the type of the source changed since it was copied", func() {
+		basicAuthCopy := copyOf("team-a", staleDockerConfigJson)
+		basicAuthCopy.Type = corev1.SecretTypeBasicAuth
+		basicAuthCopy.Data = map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("some-user"),
+			corev1.BasicAuthPasswordKey: []byte("some-password"),
+		}
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				source,
+				basicAuthCopy,
+				serviceAccount("team-a"),
+				serviceAccount("team-b"),
+			},
+			Args:      []string{secretName, "--to-namespace", "team-a,team-b", "--sync"},
+			ExpectErr: true,
+			ExpectCreates: []runtime.Object{
+				copyOf("team-b", dockerConfigJson),
+			},
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{Object: linkedServiceAccount("team-b")},
+			},
+			ExpectedOutput: "Error copying secret \"some-secret\" to namespace \"team-a\": cannot sync secret \"some-secret\" to namespace \"team-a\" because its type changed from \"kubernetes.io/basic-auth\" to \"kubernetes.io/dockerconfigjson\", " +
+				"delete the copy with \"kp secret delete some-secret -n team-a\" and copy it again\n" +
+				"Secret \"some-secret\" copied to namespace \"team-b\"\n" +
+				"Error: secret \"some-secret\" could not be copied to 1 of 2 namespaces\n",
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("does not modify secrets that were not copied from the source", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				source,
+				makeSecret("team-a", `{"auths":{}}`, nil),
+				serviceAccount("team-a"),
+			},
+			Args:      []string{secretName, "--to-namespace", "team-a", "--sync"},
+			ExpectErr: true,
+			ExpectedOutput: "Error copying secret \"some-secret\" to namespace \"team-a\": secret \"some-secret\" already exists in namespace \"team-a\" and was not copied from \"some-default-namespace/some-secret\"\n" +
+				"Error: secret \"some-secret\" could not be copied to 1 of 1 namespaces\n",
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("does not create secrets with --dry-run", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				source,
+				serviceAccount("team-a"),
+			},
+			Args:           []string{secretName, "--to-namespace", "team-a", "--dry-run"},
+			ExpectedOutput: "Secret \"some-secret\" copied to namespace \"team-a\" (dry run)\n",
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("errors without a target namespace or selector", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{source},
+			Args:           []string{secretName},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: a target namespace or selector is required\n",
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("errors when no namespaces match the selector", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{source},
+			Args:           []string{secretName, "--selector", "team=build"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: no namespaces found matching selector \"team=build\"\n",
+		}.TestK8s(t, cmdFunc)
+	})
+}
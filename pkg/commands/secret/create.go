@@ -4,20 +4,17 @@
 package secret
 
 import (
-	"os"
-
 	"github.com/spf13/cobra"
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
 	"github.com/pivotal/build-service-cli/pkg/secret"
 )
 
-func NewCreateCommand(clientSetProvider k8s.ClientSetProvider, secretFactory *secret.Factory) *cobra.Command {
+func NewCreateCommand(clientSetProvider k8s.ClientSetProvider, secretFactory *secret.Factory, newCredentialFetcher func(*cobra.Command) (secret.CredentialFetcher, error)) *cobra.Command {
 	var (
-		namespace string
+		namespace       string
+		serviceAccounts []string
 	)
 
 	cmd := &cobra.Command{
@@ -26,6 +23,11 @@ func NewCreateCommand(clientSetProvider k8s.ClientSetProvider, secretFactory *se
 		Long: `Create a secret configuration using registry or git credentials in the provided namespace.
 
 The namespace defaults to the kubernetes current-context namespace.
+The secret is linked to the "default" service account unless "--service-account" is provided.
+Use "--service-account" multiple times to link the secret to several service accounts.
+
+Passwords are prompted for unless provided with "--password-stdin", "--password-file", or the env var of the secret type.
+Prompting requires stdin to be a terminal.
 
 The flags for this command determine the type of secret that will be created:
 
@@ -41,15 +43,39 @@ The flags for this command determine the type of secret that will be created:
   "--git-url" and "--git-ssh-key" to create SSH based git credentials.
   "--git-url" should not contain the repository path (eg. git@github.com not git@github.com:my/repo)
   Alternatively, provided the credentials in the "GIT_SSH_KEY_PATH" env var instead of the "--git-ssh-key" flag.
+  The SSH key must not be passphrase protected.
+  Use "--git-known-hosts" to provide the host keys of the git host, or "--git-ssh-scan" to fetch them and confirm their fingerprints.
 
   "--git-url" and "--git-user" to create Basic Auth based git credentials.
   "--git-url" should not contain the repository path (eg. https://github.com not https://github.com/my/repo) 
-  Use the "GIT_PASSWORD" env var to bypass the password prompt.`,
+  Use the "GIT_PASSWORD" env var to bypass the password prompt.
+
+  "--from-docker-config" to import registry credentials from a docker config, including credentials from credential helpers.
+  The path defaults to "~/.docker/config.json" and must be provided as "--from-docker-config=<path>".
+  Use "--registry-filter" to only import the credentials of some registries.
+
+  "--git-url" and "--git-token" to create git credentials from a GitHub or GitLab access token.
+  The username expected by the git host is used.
+  Use the "GIT_TOKEN" env var to bypass the token prompt.
+
+  "--acr" and "--acr-client-id" to create Azure Container Registry credentials for a service principal.
+  Use the "ACR_CLIENT_SECRET" env var to bypass the client secret prompt.
+
+  "--ecr" to create Amazon Elastic Container Registry credentials from the token printed by "aws ecr get-login-password".
+  The token expires after 12 hours, use "kp secret update" to replace it.
+  Use the "ECR_PASSWORD" env var to bypass the password prompt.`,
 		Example: `kp secret create my-docker-hub-creds --dockerhub dockerhub-id
 kp secret create my-gcr-creds --gcr /path/to/gcr/service-account.json
 kp secret create my-registry-cred --registry example-registry.io/my-repo --registry-user my-registry-user
 kp secret create my-git-ssh-cred --git-url git@github.com --git-ssh-key /path/to/git/ssh-private-key.pem
-kp secret create my-git-cred --git-url https://github.com --git-user my-git-user`,
+kp secret create my-git-ssh-cred --git-url git@github.com --git-ssh-key /path/to/git/ssh-private-key.pem --git-ssh-scan
+kp secret create my-git-cred --git-url https://github.com --git-user my-git-user
+kp secret create my-git-token-cred --git-url https://github.com --git-token
+kp secret create my-acr-cred --acr myregistry.azurecr.io --acr-client-id 00000000-0000-0000-0000-000000000000
+aws ecr get-login-password | kp secret create my-ecr-cred --ecr 123456789012.dkr.ecr.us-east-1.amazonaws.com --password-stdin
+echo $REGISTRY_PASSWORD | kp secret create my-registry-cred --registry example-registry.io/my-repo --registry-user my-registry-user --password-stdin
+kp secret create my-docker-config-creds --from-docker-config --registry-filter gcr.io --registry-filter example-registry.io
+kp secret create my-registry-cred --registry example-registry.io/my-repo --registry-user my-registry-user --service-account build-sa --service-account other-build-sa`,
 		Args:         commands.ExactArgsWithUsage(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -63,19 +89,23 @@ kp secret create my-git-cred --git-url https://github.com --git-user my-git-user
 				return err
 			}
 
-			if val, ok := os.LookupEnv("GCR_SERVICE_ACCOUNT_PATH"); ok {
-				secretFactory.GcrServiceAccountFile = val
+			secretFactory.CredentialFetcher, err = newCredentialFetcher(cmd)
+			if err != nil {
+				return err
 			}
 
-			if val, ok := os.LookupEnv("GIT_SSH_KEY_PATH"); ok {
-				secretFactory.GitSshKeyFile = val
-			}
+			readCredentialFileEnvVars(secretFactory)
 
 			secret, target, err := secretFactory.MakeSecret(args[0], cs.Namespace)
 			if err != nil {
 				return err
 			}
 
+			serviceAccountList, err := getServiceAccounts(cs, serviceAccounts)
+			if err != nil {
+				return err
+			}
+
 			if !ch.IsDryRun() {
 				secret, err = cs.K8sClient.CoreV1().Secrets(cs.Namespace).Create(secret)
 				if err != nil {
@@ -87,55 +117,31 @@ kp secret create my-git-cred --git-url https://github.com --git-user my-git-user
 				return err
 			}
 
-			serviceAccount, err := cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Get("default", metav1.GetOptions{})
-			if err != nil {
-				return err
-			}
-
-			serviceAccount.Secrets = append(serviceAccount.Secrets, corev1.ObjectReference{Name: args[0]})
-
-			if secret.Type == corev1.SecretTypeDockerConfigJson {
-				serviceAccount.ImagePullSecrets = append(serviceAccount.ImagePullSecrets, corev1.LocalObjectReference{Name: args[0]})
-			}
+			for _, serviceAccount := range serviceAccountList {
+				if err = linkSecret(serviceAccount, secret, target); err != nil {
+					return err
+				}
 
-			if err = updateManagedSecretsAnnotation(err, serviceAccount, args[0], target); err != nil {
-				return err
-			}
+				if !ch.IsDryRun() {
+					serviceAccount, err = cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Update(serviceAccount)
+					if err != nil {
+						return err
+					}
+				}
 
-			if !ch.IsDryRun() {
-				serviceAccount, err = cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Update(serviceAccount)
-				if err != nil {
+				if err = ch.PrintObj(serviceAccount); err != nil {
 					return err
 				}
 			}
 
-			if err = ch.PrintObj(serviceAccount); err != nil {
-				return err
-			}
-
 			return ch.PrintResult("Secret %q created", secret.Name)
 		},
 	}
 
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
-	cmd.Flags().StringVarP(&secretFactory.DockerhubId, "dockerhub", "", "", "dockerhub id")
-	cmd.Flags().StringVarP(&secretFactory.Registry, "registry", "", "", "registry")
-	cmd.Flags().StringVarP(&secretFactory.RegistryUser, "registry-user", "", "", "registry user")
-	cmd.Flags().StringVarP(&secretFactory.GcrServiceAccountFile, "gcr", "", "", "path to a file containing the GCR service account")
-	cmd.Flags().StringVarP(&secretFactory.GitUrl, "git-url", "", "", "git url")
-	cmd.Flags().StringVarP(&secretFactory.GitSshKeyFile, "git-ssh-key", "", "", "path to a file containing the GitUrl SSH private key")
-	cmd.Flags().StringVarP(&secretFactory.GitUser, "git-user", "", "", "git user")
+	setCredentialFlags(cmd, secretFactory)
+	commands.SetPasswordFlags(cmd)
+	setServiceAccountFlag(cmd, &serviceAccounts, "service account to link the secret to")
 	commands.SetDryRunOutputFlags(cmd)
 	return cmd
 }
-
-func updateManagedSecretsAnnotation(err error, sa *corev1.ServiceAccount, name, target string) error {
-	managedSecrets, err := readManagedSecrets(sa)
-	if err != nil {
-		return err
-	}
-
-	managedSecrets[name] = target
-
-	return writeManagedSecrets(managedSecrets, sa)
-}
@@ -5,11 +5,13 @@ package secret_test
 
 import (
 	"fmt"
+	"io/ioutil"
 	"testing"
 
 	"github.com/pkg/errors"
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -35,13 +37,13 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 		passwords: map[string]string{},
 	}
 
-	factory := &secret.Factory{
-		CredentialFetcher: fetcher,
-	}
+	factory := &secret.Factory{}
 
 	cmdFunc := func(k8sClient *fake.Clientset) *cobra.Command {
 		clientSetProvider := testhelpers.GetFakeK8sProvider(k8sClient, defaultNamespace)
-		return secretcmds.NewCreateCommand(clientSetProvider, factory)
+		return secretcmds.NewCreateCommand(clientSetProvider, factory, func(*cobra.Command) (secret.CredentialFetcher, error) {
+			return fetcher, nil
+		})
 	}
 
 	defaultServiceAccount := &corev1.ServiceAccount{
@@ -237,9 +239,10 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 				secretName = "my-git-ssh-cred"
 			)
 
-			fetcher.passwords[gitSshFile] = "some git ssh key"
-
 			it("creates a secret with the correct annotations for git ssh in the provided namespace and updates the service account", func() {
+				gitSshKey, err := ioutil.ReadFile(gitSshFile)
+				require.NoError(t, err)
+
 				expectedGitSecret := &corev1.Secret{
 					ObjectMeta: v1.ObjectMeta{
 						Name:      secretName,
@@ -249,7 +252,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						},
 					},
 					Data: map[string][]byte{
-						corev1.SSHAuthPrivateKey: []byte("some git ssh key"),
+						corev1.SSHAuthPrivateKey: gitSshKey,
 					},
 					Type: corev1.SecretTypeSSHAuth,
 				}
@@ -343,6 +346,119 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 				}.TestK8s(t, cmdFunc)
 			})
 		})
+
+		when("creating a git token secret", func() {
+			var (
+				gitRepo    = "https://gitlab.com"
+				gitToken   = "my-git-token"
+				secretName = "my-git-token-cred"
+			)
+
+			fetcher.passwords["GIT_TOKEN"] = gitToken
+
+			it("creates a basic auth secret with the username expected by the git host and updates the service account", func() {
+				expectedGitSecret := &corev1.Secret{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      secretName,
+						Namespace: namespace,
+						Annotations: map[string]string{
+							secret.GitAnnotation: gitRepo,
+						},
+					},
+					Data: map[string][]byte{
+						corev1.BasicAuthUsernameKey: []byte("oauth2"),
+						corev1.BasicAuthPasswordKey: []byte(gitToken),
+					},
+					Type: corev1.SecretTypeBasicAuth,
+				}
+
+				expectedServiceAccount := &corev1.ServiceAccount{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "default",
+						Namespace: namespace,
+						Annotations: map[string]string{
+							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, gitRepo),
+						},
+					},
+					Secrets: []corev1.ObjectReference{
+						{Name: secretName},
+					},
+				}
+
+				testhelpers.CommandTest{
+					Objects: []runtime.Object{
+						defaultNamespacedServiceAccount,
+					},
+					Args: []string{secretName, "--git-url", gitRepo, "--git-token", "-n", namespace},
+					ExpectedOutput: `Secret "my-git-token-cred" created
+`,
+					ExpectCreates: []runtime.Object{
+						expectedGitSecret,
+					},
+					ExpectUpdates: []clientgotesting.UpdateActionImpl{
+						{
+							Object: expectedServiceAccount,
+						},
+					},
+				}.TestK8s(t, cmdFunc)
+			})
+		})
+
+		when("creating an ecr secret", func() {
+			var (
+				registry    = "123456789012.dkr.ecr.us-east-1.amazonaws.com"
+				ecrPassword = "my-ecr-token"
+				secretName  = "my-ecr-cred"
+			)
+
+			fetcher.passwords["ECR_PASSWORD"] = ecrPassword
+
+			it("creates a registry secret with the AWS username and updates the service account", func() {
+				expectedEcrSecret := &corev1.Secret{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      secretName,
+						Namespace: namespace,
+					},
+					Data: map[string][]byte{
+						corev1.DockerConfigJsonKey: []byte(fmt.Sprintf(`{"auths":{"%s":{"username":"AWS","password":"%s"}}}`, registry, ecrPassword)),
+					},
+					Type: corev1.SecretTypeDockerConfigJson,
+				}
+
+				expectedServiceAccount := &corev1.ServiceAccount{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "default",
+						Namespace: namespace,
+						Annotations: map[string]string{
+							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"%s"}`, secretName, registry),
+						},
+					},
+					Secrets: []corev1.ObjectReference{
+						{Name: secretName},
+					},
+					ImagePullSecrets: []corev1.LocalObjectReference{
+						{Name: secretName},
+					},
+				}
+
+				testhelpers.CommandTest{
+					Objects: []runtime.Object{
+						defaultNamespacedServiceAccount,
+					},
+					Args: []string{secretName, "--ecr", registry, "-n", namespace},
+					ExpectedOutput: `Secret "my-ecr-cred" created
+`,
+					ExpectCreates: []runtime.Object{
+						expectedEcrSecret,
+					},
+					ExpectUpdates: []clientgotesting.UpdateActionImpl{
+						{
+							Object: expectedServiceAccount,
+						},
+					},
+				}.TestK8s(t, cmdFunc)
+			})
+		})
 	})
 
 	when("namespace is not provided", func() {
@@ -524,9 +640,10 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 				secretName = "my-git-ssh-cred"
 			)
 
-			fetcher.passwords[gitSshFile] = "some git ssh key"
-
 			it("creates a secret with the correct annotations for git ssh in the default namespace and updates the service account", func() {
+				gitSshKey, err := ioutil.ReadFile(gitSshFile)
+				require.NoError(t, err)
+
 				expectedGitSecret := &corev1.Secret{
 					ObjectMeta: v1.ObjectMeta{
 						Name:      secretName,
@@ -536,7 +653,7 @@ func testSecretCreateCommand(t *testing.T, when spec.G, it spec.S) {
 						},
 					},
 					Data: map[string][]byte{
-						corev1.SSHAuthPrivateKey: []byte("some git ssh key"),
+						corev1.SSHAuthPrivateKey: gitSshKey,
 					},
 					Type: corev1.SecretTypeSSHAuth,
 				}
@@ -775,6 +892,100 @@ secrets:
 		})
 	})
 
+	when("service accounts are provided", func() {
+		fetcher.passwords["DOCKER_PASSWORD"] = "dummy-password"
+
+		buildServiceAccount := &corev1.ServiceAccount{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "build-sa",
+				Namespace: defaultNamespace,
+			},
+		}
+
+		otherBuildServiceAccount := &corev1.ServiceAccount{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "other-build-sa",
+				Namespace: defaultNamespace,
+			},
+			Secrets: []corev1.ObjectReference{
+				{Name: "existing-secret"},
+			},
+		}
+
+		it("links the secret to each service account", func() {
+			expectedServiceAccount := func(name string, secrets ...string) *corev1.ServiceAccount {
+				sa := &corev1.ServiceAccount{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      name,
+						Namespace: defaultNamespace,
+						Annotations: map[string]string{
+							secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"my-docker-cred":"%s"}`, secret.DockerhubUrl),
+						},
+					},
+					ImagePullSecrets: []corev1.LocalObjectReference{
+						{Name: "my-docker-cred"},
+					},
+				}
+				for _, s := range secrets {
+					sa.Secrets = append(sa.Secrets, corev1.ObjectReference{Name: s})
+				}
+				return sa
+			}
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					buildServiceAccount,
+					otherBuildServiceAccount,
+				},
+				Args: []string{
+					"my-docker-cred",
+					"--dockerhub", "my-dockerhub-id",
+					"--service-account", "build-sa",
+					"--service-account", "other-build-sa",
+				},
+				ExpectedOutput: `Secret "my-docker-cred" created
+`,
+				ExpectCreates: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: v1.ObjectMeta{
+							Name:      "my-docker-cred",
+							Namespace: defaultNamespace,
+						},
+						Data: map[string][]byte{
+							corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"username":"my-dockerhub-id","password":"dummy-password"}}}`),
+						},
+						Type: corev1.SecretTypeDockerConfigJson,
+					},
+				},
+				ExpectUpdates: []clientgotesting.UpdateActionImpl{
+					{
+						Object: expectedServiceAccount("build-sa", "my-docker-cred"),
+					},
+					{
+						Object: expectedServiceAccount("other-build-sa", "existing-secret", "my-docker-cred"),
+					},
+				},
+			}.TestK8s(t, cmdFunc)
+		})
+
+		it("does not create the secret when a service account does not exist", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					buildServiceAccount,
+				},
+				Args: []string{
+					"my-docker-cred",
+					"--dockerhub", "my-dockerhub-id",
+					"--service-account", "build-sa",
+					"--service-account", "missing-sa",
+				},
+				ExpectErr: true,
+				ExpectedOutput: `Error: serviceaccounts "missing-sa" not found
+`,
+			}.TestK8s(t, cmdFunc)
+		})
+	})
+
 	when("dry-run flag is used", func() {
 		fetcher.passwords["DOCKER_PASSWORD"] = "dummy-password"
 
@@ -0,0 +1,41 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func setCredentialFlags(cmd *cobra.Command, secretFactory *secret.Factory) {
+	cmd.Flags().StringVarP(&secretFactory.DockerhubId, "dockerhub", "", "", "dockerhub id")
+	cmd.Flags().StringVarP(&secretFactory.Registry, "registry", "", "", "registry")
+	cmd.Flags().StringVarP(&secretFactory.RegistryUser, "registry-user", "", "", "registry user")
+	cmd.Flags().StringVarP(&secretFactory.GcrServiceAccountFile, "gcr", "", "", "path to a file containing the GCR service account")
+	cmd.Flags().StringVarP(&secretFactory.GitUrl, "git-url", "", "", "git url")
+	cmd.Flags().StringVarP(&secretFactory.GitSshKeyFile, "git-ssh-key", "", "", "path to a file containing the GitUrl SSH private key")
+	cmd.Flags().StringVarP(&secretFactory.GitUser, "git-user", "", "", "git user")
+	cmd.Flags().StringVarP(&secretFactory.GitKnownHostsFile, "git-known-hosts", "", "", "path to a known_hosts file containing the host keys of the git host")
+	cmd.Flags().BoolVarP(&secretFactory.GitSshScan, "git-ssh-scan", "", false, "fetch the host keys of the git host and confirm their fingerprints")
+	cmd.Flags().BoolVarP(&secretFactory.GitToken, "git-token", "", false, "use a github or gitlab access token for the git url")
+	cmd.Flags().StringVarP(&secretFactory.DockerConfigPath, "from-docker-config", "", "", "path to a docker config.json to import registry credentials from")
+	cmd.Flags().Lookup("from-docker-config").NoOptDefVal = secret.DefaultDockerConfigPath
+	cmd.Flags().StringSliceVarP(&secretFactory.RegistryFilter, "registry-filter", "", nil, "registry host to import from the docker config (can be specified multiple times)")
+	cmd.Flags().StringVarP(&secretFactory.AcrRegistry, "acr", "", "", "azure container registry")
+	cmd.Flags().StringVarP(&secretFactory.AcrClientId, "acr-client-id", "", "", "application id of the azure service principal")
+	cmd.Flags().StringVarP(&secretFactory.EcrRegistry, "ecr", "", "", "amazon elastic container registry")
+}
+
+func readCredentialFileEnvVars(secretFactory *secret.Factory) {
+	if val, ok := os.LookupEnv("GCR_SERVICE_ACCOUNT_PATH"); ok {
+		secretFactory.GcrServiceAccountFile = val
+	}
+
+	if val, ok := os.LookupEnv("GIT_SSH_KEY_PATH"); ok {
+		secretFactory.GitSshKeyFile = val
+	}
+}
@@ -24,8 +24,9 @@ func NewDeleteCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Short: "Delete secret",
 		Long: `Deletes a specific secret in the provided namespace.
 
-The namespace defaults to the kubernetes current-context namespace.`,
-		Example:      "kp secret delete my-secret",
+The namespace defaults to the kubernetes current-context namespace.
+The secret is unlinked from every service account in the namespace that references it before it is deleted.`,
+		Example:      "kp secret delete my-secret\nkp secret delete my-secret -n my-namespace",
 		Args:         commands.ExactArgsWithUsage(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,18 +35,21 @@ The namespace defaults to the kubernetes current-context namespace.`,
 				return err
 			}
 
-			serviceAccount, err := cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Get("default", metav1.GetOptions{})
+			serviceAccountList, err := cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).List(metav1.ListOptions{})
 			if err != nil {
 				return err
 			}
 
-			wasModified, err := deleteSecretsFromServiceAccount(serviceAccount, args[0])
-			if err != nil {
-				return err
-			} else if wasModified {
-				_, err = cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Update(serviceAccount)
+			for i := range serviceAccountList.Items {
+				serviceAccount := &serviceAccountList.Items[i]
+				wasModified, err := deleteSecretsFromServiceAccount(serviceAccount, args[0])
 				if err != nil {
 					return err
+				} else if wasModified {
+					_, err = cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Update(serviceAccount)
+					if err != nil {
+						return err
+					}
 				}
 			}
 
@@ -221,5 +221,84 @@ func testSecretDeleteCommand(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 		})
+
+		when("multiple service accounts reference the secret", func() {
+			const secretName = "some-secret"
+
+			it("removes the secret from every service account that references it", func() {
+				secretOne := &corev1.Secret{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      secretName,
+						Namespace: defaultNamespace,
+					},
+				}
+
+				serviceAccount := func(name string) *corev1.ServiceAccount {
+					return &corev1.ServiceAccount{
+						ObjectMeta: v1.ObjectMeta{
+							Name:      name,
+							Namespace: defaultNamespace,
+							Annotations: map[string]string{
+								secretcmds.ManagedSecretAnnotationKey: fmt.Sprintf(`{"%s":"some-git-url", "foo":"bar"}`, secretName),
+							},
+						},
+						Secrets: []corev1.ObjectReference{
+							{Name: secretName},
+						},
+					}
+				}
+
+				expectedServiceAccount := func(name string) *corev1.ServiceAccount {
+					return &corev1.ServiceAccount{
+						ObjectMeta: v1.ObjectMeta{
+							Name:      name,
+							Namespace: defaultNamespace,
+							Annotations: map[string]string{
+								secretcmds.ManagedSecretAnnotationKey: `{"foo":"bar"}`,
+							},
+						},
+						Secrets: []corev1.ObjectReference{},
+					}
+				}
+
+				unrelatedServiceAccount := &corev1.ServiceAccount{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "unrelated-sa",
+						Namespace: defaultNamespace,
+					},
+					Secrets: []corev1.ObjectReference{
+						{Name: "other-secret"},
+					},
+				}
+
+				testhelpers.CommandTest{
+					Objects: []runtime.Object{
+						secretOne,
+						serviceAccount("build-sa"),
+						serviceAccount("other-build-sa"),
+						unrelatedServiceAccount,
+					},
+					Args: []string{secretName},
+					ExpectedOutput: `Secret "some-secret" deleted
+`,
+					ExpectUpdates: []clientgotesting.UpdateActionImpl{
+						{
+							Object: expectedServiceAccount("build-sa"),
+						},
+						{
+							Object: expectedServiceAccount("other-build-sa"),
+						},
+					},
+					ExpectDeletes: []clientgotesting.DeleteActionImpl{
+						{
+							ActionImpl: clientgotesting.ActionImpl{
+								Namespace: defaultNamespace,
+							},
+							Name: secretName,
+						},
+					},
+				}.TestK8s(t, cmdFunc)
+			})
+		})
 	})
 }
@@ -4,20 +4,31 @@
 package secret
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
 
 	"github.com/pivotal/build-service-cli/pkg/commands"
 	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/secret"
 )
 
 func NewListCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 	var (
-		namespace string
+		namespace          string
+		serviceAccounts    []string
+		allServiceAccounts bool
+		output             string
+		fix                bool
 	)
 
 	command := cobra.Command{
@@ -25,60 +36,231 @@ func NewListCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
 		Short: "List secrets",
 		Long: `Prints a table of the most important information about secrets in the provided namespace.
 
-The namespace defaults to the kubernetes current-context namespace.`,
-		Example:      "kp secret list\nkp secret list -n my-namespace",
+The namespace defaults to the kubernetes current-context namespace.
+Secrets linked to the "default" service account are listed unless "--service-account" is provided.
+When several service accounts are selected, or "--all-service-accounts" is used, the table shows which service accounts each secret is linked to.
+
+Service account references to secrets that no longer exist are reported as "missing secret", and managed secret
+annotation entries for secrets that are not linked to the service account are reported as "stale annotation".
+Use "--fix" to remove them from the service accounts.`,
+		Example:      "kp secret list\nkp secret list -n my-namespace\nkp secret list --service-account build-sa --service-account other-build-sa\nkp secret list --all-service-accounts\nkp secret list -o json\nkp secret list --fix",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "json" {
+				return errors.Errorf("invalid output format %q, must be one of table or json", output)
+			}
+
 			cs, err := clientSetProvider.GetClientSet(namespace)
 			if err != nil {
 				return err
 			}
 
-			serviceAccount, err := cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Get("default", metav1.GetOptions{})
+			var serviceAccountList []*corev1.ServiceAccount
+			if allServiceAccounts {
+				serviceAccountList, err = listServiceAccounts(cs)
+			} else {
+				serviceAccountList, err = getServiceAccounts(cs, serviceAccounts)
+			}
+			if err != nil {
+				return err
+			}
+
+			secrets, err := listSecrets(cs)
 			if err != nil {
 				return err
 			}
 
-			if len(serviceAccount.Secrets) == 0 && len(serviceAccount.ImagePullSecrets) == 0 {
+			entries, err := secretEntries(serviceAccountList, secrets)
+			if err != nil {
+				return err
+			}
+
+			if fix {
+				messages := cmd.OutOrStdout()
+				if output == "json" {
+					messages = cmd.ErrOrStderr()
+				}
+
+				if err := fixServiceAccounts(cs, messages, serviceAccountList, entries); err != nil {
+					return err
+				}
+
+				entries, err = secretEntries(serviceAccountList, secrets)
+				if err != nil {
+					return err
+				}
+			}
+
+			if output == "json" {
+				return writeJSON(cmd.OutOrStdout(), entries)
+			}
+
+			if len(entries) == 0 {
 				return errors.Errorf("no secrets found in %q namespace", cs.Namespace)
+			} else if len(serviceAccountList) == 1 {
+				return displaySecretsTable(cmd, entries)
 			} else {
-				return displaySecretsTable(cmd, serviceAccount)
+				return displayServiceAccountMatrix(cmd, serviceAccountList, entries)
 			}
 		},
 	}
 
 	command.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	setServiceAccountFlag(&command, &serviceAccounts, "service account to list the secrets of")
+	command.Flags().BoolVar(&allServiceAccounts, "all-service-accounts", false, "list the secrets of all service accounts in the namespace")
+	command.Flags().StringVarP(&output, "output", "o", "table", "output format. supported formats are: table, json")
+	command.Flags().BoolVar(&fix, "fix", false, "remove references to missing secrets and stale managed secret annotation entries from the service accounts")
 
 	return &command
 }
 
-func displaySecretsTable(cmd *cobra.Command, sa *corev1.ServiceAccount) error {
-	managedSecrets, err := readManagedSecrets(sa)
+func listServiceAccounts(cs k8s.ClientSet) ([]*corev1.ServiceAccount, error) {
+	list, err := cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).List(metav1.ListOptions{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	secretNameSet := map[string]interface{}{}
-	for _, item := range append(sa.Secrets) {
-		secretNameSet[item.Name] = nil
+	var serviceAccounts []*corev1.ServiceAccount
+	for i := range list.Items {
+		serviceAccounts = append(serviceAccounts, &list.Items[i])
+	}
+	sort.Slice(serviceAccounts, func(i, j int) bool {
+		return serviceAccounts[i].Name < serviceAccounts[j].Name
+	})
+	return serviceAccounts, nil
+}
+
+// secretEntry describes a secret as seen from one service account.
+type secretEntry struct {
+	ServiceAccount string       `json:"serviceAccount"`
+	Name           string       `json:"name"`
+	Type           string       `json:"type"`
+	Target         string       `json:"target"`
+	Created        *metav1.Time `json:"created,omitempty"`
+	LinkedAs       []string     `json:"linkedAs"`
+	Problems       []string     `json:"problems,omitempty"`
+}
+
+const (
+	missingSecretProblem   = "missing secret"
+	staleAnnotationProblem = "stale annotation"
+)
+
+func listSecrets(cs k8s.ClientSet) (map[string]*corev1.Secret, error) {
+	list, err := cs.K8sClient.CoreV1().Secrets(cs.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
 	}
-	for _, item := range append(sa.ImagePullSecrets) {
-		secretNameSet[item.Name] = nil
+
+	secrets := map[string]*corev1.Secret{}
+	for i := range list.Items {
+		secrets[list.Items[i].Name] = &list.Items[i]
 	}
+	return secrets, nil
+}
 
-	var secretNames []string
-	for name := range secretNameSet {
-		secretNames = append(secretNames, name)
+// secretEntries returns the secrets referenced by each service account, or recorded in its managed secrets
+// annotation, flagging references to missing secrets and annotation entries for secrets that are not linked.
+func secretEntries(serviceAccounts []*corev1.ServiceAccount, secrets map[string]*corev1.Secret) ([]secretEntry, error) {
+	entries := []secretEntry{}
+	for _, sa := range serviceAccounts {
+		managedSecrets, err := readManagedSecrets(sa)
+		if err != nil {
+			return nil, err
+		}
+
+		names := secretNames(sa)
+		for name := range managedSecrets {
+			if !hasSecret(sa, name) && !hasImagePullSecret(sa, name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			entry := secretEntry{
+				ServiceAccount: sa.Name,
+				Name:           name,
+				Target:         managedSecrets[name],
+				LinkedAs:       []string{},
+			}
+
+			if hasSecret(sa, name) {
+				entry.LinkedAs = append(entry.LinkedAs, "secret")
+			}
+			if hasImagePullSecret(sa, name) {
+				entry.LinkedAs = append(entry.LinkedAs, "imagePullSecret")
+			}
+
+			s, exists := secrets[name]
+			if exists {
+				entry.Type = secret.Type(s)
+				entry.Created = &s.CreationTimestamp
+			}
+
+			if len(entry.LinkedAs) == 0 {
+				entry.Problems = append(entry.Problems, staleAnnotationProblem)
+			} else if !exists {
+				entry.Problems = append(entry.Problems, missingSecretProblem)
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// fixServiceAccounts removes references to missing secrets and stale managed secret annotation entries
+// from the service accounts.
+func fixServiceAccounts(cs k8s.ClientSet, w io.Writer, serviceAccounts []*corev1.ServiceAccount, entries []secretEntry) error {
+	for _, sa := range serviceAccounts {
+		modified := false
+		for _, entry := range entries {
+			if entry.ServiceAccount != sa.Name || len(entry.Problems) == 0 {
+				continue
+			}
+
+			if entry.Problems[0] == missingSecretProblem {
+				if _, err := deleteSecretsFromServiceAccount(sa, entry.Name); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(w, "Removed reference to missing secret %q from service account %q\n", entry.Name, sa.Name)
+			} else {
+				managedSecrets, err := readManagedSecrets(sa)
+				if err != nil {
+					return err
+				}
+
+				delete(managedSecrets, entry.Name)
+				if err := writeManagedSecrets(managedSecrets, sa); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(w, "Removed stale annotation entry for %q from service account %q\n", entry.Name, sa.Name)
+			}
+			modified = true
+		}
+
+		if !modified {
+			continue
+		}
+
+		updated, err := cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Update(sa)
+		if err != nil {
+			return err
+		}
+		*sa = *updated
 	}
-	sort.Strings(secretNames)
+	return nil
+}
 
-	writer, err := commands.NewTableWriter(cmd.OutOrStdout(), "NAME", "TARGET")
+func displaySecretsTable(cmd *cobra.Command, entries []secretEntry) error {
+	writer, err := commands.NewTableWriter(cmd.OutOrStdout(), "NAME", "TYPE", "TARGET", "AGE", "LINKED-AS", "STATUS")
 	if err != nil {
 		return err
 	}
 
-	for _, name := range secretNames {
-		err := writer.AddRow(name, managedSecrets[name])
+	for _, entry := range entries {
+		err := writer.AddRow(entry.Name, valueOrDash(entry.Type), entry.Target, age(entry.Created), valueOrDash(strings.Join(entry.LinkedAs, ",")), status(entry.Problems))
 		if err != nil {
 			return err
 		}
@@ -86,3 +268,125 @@ func displaySecretsTable(cmd *cobra.Command, sa *corev1.ServiceAccount) error {
 
 	return writer.Write()
 }
+
+func displayServiceAccountMatrix(cmd *cobra.Command, serviceAccounts []*corev1.ServiceAccount, entries []secretEntry) error {
+	headers := []string{"NAME", "TYPE", "TARGET"}
+	for _, sa := range serviceAccounts {
+		headers = append(headers, sa.Name)
+	}
+	headers = append(headers, "STATUS")
+
+	var names []string
+	bySecret := map[string]map[string]secretEntry{}
+	for _, entry := range entries {
+		if _, ok := bySecret[entry.Name]; !ok {
+			names = append(names, entry.Name)
+			bySecret[entry.Name] = map[string]secretEntry{}
+		}
+		bySecret[entry.Name][entry.ServiceAccount] = entry
+	}
+	sort.Strings(names)
+
+	writer, err := commands.NewTableWriter(cmd.OutOrStdout(), headers...)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		var (
+			secretType, target string
+			membership         []string
+			problems           []string
+		)
+
+		for _, sa := range serviceAccounts {
+			entry, ok := bySecret[name][sa.Name]
+			switch {
+			case !ok:
+				membership = append(membership, "no")
+				continue
+			case len(entry.LinkedAs) == 0:
+				membership = append(membership, "stale")
+			default:
+				membership = append(membership, "yes")
+			}
+
+			if secretType == "" {
+				secretType = entry.Type
+			}
+			if target == "" {
+				target = entry.Target
+			}
+			for _, problem := range entry.Problems {
+				if !contains(problems, problem) {
+					problems = append(problems, problem)
+				}
+			}
+		}
+
+		row := append([]string{name, valueOrDash(secretType), target}, membership...)
+		if err := writer.AddRow(append(row, status(problems))...); err != nil {
+			return err
+		}
+	}
+
+	return writer.Write()
+}
+
+// secretNames returns the sorted names of the secrets and image pull secrets of the service accounts.
+func secretNames(serviceAccounts ...*corev1.ServiceAccount) []string {
+	secretNameSet := map[string]interface{}{}
+	for _, sa := range serviceAccounts {
+		for _, item := range sa.Secrets {
+			secretNameSet[item.Name] = nil
+		}
+		for _, item := range sa.ImagePullSecrets {
+			secretNameSet[item.Name] = nil
+		}
+	}
+
+	var names []string
+	for name := range secretNameSet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func age(created *metav1.Time) string {
+	if created == nil {
+		return "-"
+	} else if created.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(created.Time))
+}
+
+func status(problems []string) string {
+	if len(problems) == 0 {
+		return "ok"
+	}
+	return strings.Join(problems, ",")
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -5,13 +5,16 @@ package secret_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/sclevine/spec"
 	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
 
 	secretcmds "github.com/pivotal/build-service-cli/pkg/commands/secret"
 	"github.com/pivotal/build-service-cli/pkg/testhelpers"
@@ -31,6 +34,30 @@ func testSecretListCommand(t *testing.T, when spec.G, it spec.S) {
 		return secretcmds.NewListCommand(clientSetProvider)
 	}
 
+	created := v1.NewTime(time.Now().Add(-26 * time.Hour))
+
+	makeSecret := func(name, namespace string, secretType corev1.SecretType, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: created,
+			},
+			Data: data,
+			Type: secretType,
+		}
+	}
+
+	secrets := func(namespace string) []runtime.Object {
+		return []runtime.Object{
+			makeSecret("secret-one", namespace, corev1.SecretTypeDockerConfigJson, map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"username":"some-user","password":"some-password"}}}`),
+			}),
+			makeSecret("secret-two", namespace, corev1.SecretTypeBasicAuth, nil),
+			makeSecret("secret-three", namespace, corev1.SecretTypeOpaque, nil),
+		}
+	}
+
 	when("listing secrets", func() {
 		when("listing secrets in the default namespace", func() {
 			when("there are secrets", func() {
@@ -61,17 +88,15 @@ func testSecretListCommand(t *testing.T, when spec.G, it spec.S) {
 						},
 					}
 
-					const expectedOutput = `NAME            TARGET
-secret-one      https://index.docker.io/v1/
-secret-three    
-secret-two      some-git-url
+					const expectedOutput = `NAME            TYPE         TARGET                         AGE    LINKED-AS                 STATUS
+secret-one      dockerhub    https://index.docker.io/v1/    26h    secret,imagePullSecret    ok
+secret-three    Opaque                                      26h    secret                    ok
+secret-two      git-basic    some-git-url                   26h    secret                    ok
 
 `
 
 					testhelpers.CommandTest{
-						Objects: []runtime.Object{
-							serviceAccount,
-						},
+						Objects:        append(secrets(defaultNamespace), serviceAccount),
 						ExpectedOutput: expectedOutput,
 					}.TestK8s(t, cmdFunc)
 				})
@@ -128,17 +153,15 @@ secret-two      some-git-url
 						},
 					}
 
-					const expectedOutput = `NAME            TARGET
-secret-one      https://index.docker.io/v1/
-secret-three    
-secret-two      some-git-url
+					const expectedOutput = `NAME            TYPE         TARGET                         AGE    LINKED-AS                 STATUS
+secret-one      dockerhub    https://index.docker.io/v1/    26h    secret,imagePullSecret    ok
+secret-three    Opaque                                      26h    secret                    ok
+secret-two      git-basic    some-git-url                   26h    secret                    ok
 
 `
 
 					testhelpers.CommandTest{
-						Objects: []runtime.Object{
-							serviceAccount,
-						},
+						Objects:        append(secrets(namespace), serviceAccount),
 						Args:           []string{"-n", namespace},
 						ExpectedOutput: expectedOutput,
 					}.TestK8s(t, cmdFunc)
@@ -165,5 +188,217 @@ secret-two      some-git-url
 				})
 			})
 		})
+
+		when("listing secrets of several service accounts", func() {
+			serviceAccounts := []runtime.Object{
+				&corev1.ServiceAccount{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "default",
+						Namespace: defaultNamespace,
+						Annotations: map[string]string{
+							secretcmds.ManagedSecretAnnotationKey: `{"secret-one":"https://index.docker.io/v1/"}`,
+						},
+					},
+					Secrets: []corev1.ObjectReference{
+						{Name: "secret-one"},
+					},
+					ImagePullSecrets: []corev1.LocalObjectReference{
+						{Name: "secret-one"},
+					},
+				},
+				&corev1.ServiceAccount{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "build-sa",
+						Namespace: defaultNamespace,
+						Annotations: map[string]string{
+							secretcmds.ManagedSecretAnnotationKey: `{"secret-one":"https://index.docker.io/v1/", "secret-two":"some-git-url"}`,
+						},
+					},
+					Secrets: []corev1.ObjectReference{
+						{Name: "secret-one"},
+						{Name: "secret-two"},
+					},
+				},
+				&corev1.ServiceAccount{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "empty-sa",
+						Namespace: defaultNamespace,
+					},
+				},
+			}
+
+			it("lists the secrets of the provided service accounts", func() {
+				testhelpers.CommandTest{
+					Objects: append(secrets(defaultNamespace), serviceAccounts...),
+					Args:    []string{"--service-account", "build-sa"},
+					ExpectedOutput: `NAME          TYPE         TARGET                         AGE    LINKED-AS    STATUS
+secret-one    dockerhub    https://index.docker.io/v1/    26h    secret       ok
+secret-two    git-basic    some-git-url                   26h    secret       ok
+
+`,
+				}.TestK8s(t, cmdFunc)
+			})
+
+			it("shows the service account membership of each secret", func() {
+				testhelpers.CommandTest{
+					Objects: append(secrets(defaultNamespace), serviceAccounts...),
+					Args:    []string{"--service-account", "default", "--service-account", "build-sa"},
+					ExpectedOutput: `NAME          TYPE         TARGET                         DEFAULT    BUILD-SA    STATUS
+secret-one    dockerhub    https://index.docker.io/v1/    yes        yes         ok
+secret-two    git-basic    some-git-url                   no         yes         ok
+
+`,
+				}.TestK8s(t, cmdFunc)
+			})
+
+			it("shows the membership for all service accounts in the namespace", func() {
+				testhelpers.CommandTest{
+					Objects: append(secrets(defaultNamespace), serviceAccounts...),
+					Args:    []string{"--all-service-accounts"},
+					ExpectedOutput: `NAME          TYPE         TARGET                         BUILD-SA    DEFAULT    EMPTY-SA    STATUS
+secret-one    dockerhub    https://index.docker.io/v1/    yes         yes        no          ok
+secret-two    git-basic    some-git-url                   yes         no         no          ok
+
+`,
+				}.TestK8s(t, cmdFunc)
+			})
+		})
+
+		when("service accounts reference secrets that do not exist", func() {
+			serviceAccount := func() *corev1.ServiceAccount {
+				return &corev1.ServiceAccount{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "default",
+						Namespace: defaultNamespace,
+						Annotations: map[string]string{
+							secretcmds.ManagedSecretAnnotationKey: `{"secret-one":"https://index.docker.io/v1/","deleted-secret":"some-registry.io","unlinked-secret":"some-git-url"}`,
+						},
+					},
+					Secrets: []corev1.ObjectReference{
+						{Name: "secret-one"},
+						{Name: "deleted-secret"},
+					},
+					ImagePullSecrets: []corev1.LocalObjectReference{
+						{Name: "secret-one"},
+						{Name: "deleted-secret"},
+					},
+				}
+			}
+
+			it("flags missing secrets and stale annotation entries", func() {
+				testhelpers.CommandTest{
+					Objects: append(secrets(defaultNamespace), serviceAccount()),
+					ExpectedOutput: `NAME               TYPE         TARGET                         AGE    LINKED-AS                 STATUS
+deleted-secret     -            some-registry.io               -      secret,imagePullSecret    missing secret
+secret-one         dockerhub    https://index.docker.io/v1/    26h    secret,imagePullSecret    ok
+unlinked-secret    -            some-git-url                   -      -                         stale annotation
+
+`,
+				}.TestK8s(t, cmdFunc)
+			})
+
+			it("removes missing secrets and stale annotation entries with --fix", func() {
+				fixedServiceAccount := serviceAccount()
+				fixedServiceAccount.Annotations[secretcmds.ManagedSecretAnnotationKey] = `{"secret-one":"https://index.docker.io/v1/"}`
+				fixedServiceAccount.Secrets = []corev1.ObjectReference{{Name: "secret-one"}}
+				fixedServiceAccount.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "secret-one"}}
+
+				testhelpers.CommandTest{
+					Objects: append(secrets(defaultNamespace), serviceAccount()),
+					Args:    []string{"--fix"},
+					ExpectUpdates: []clientgotesting.UpdateActionImpl{
+						{
+							Object: fixedServiceAccount,
+						},
+					},
+					ExpectedOutput: `Removed reference to missing secret "deleted-secret" from service account "default"
+Removed stale annotation entry for "unlinked-secret" from service account "default"
+NAME          TYPE         TARGET                         AGE    LINKED-AS                 STATUS
+secret-one    dockerhub    https://index.docker.io/v1/    26h    secret,imagePullSecret    ok
+
+`,
+				}.TestK8s(t, cmdFunc)
+			})
+
+			it("marks stale annotation entries in the service account matrix", func() {
+				otherServiceAccount := &corev1.ServiceAccount{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "build-sa",
+						Namespace: defaultNamespace,
+					},
+					Secrets: []corev1.ObjectReference{
+						{Name: "unlinked-secret"},
+					},
+				}
+
+				testhelpers.CommandTest{
+					Objects: append(secrets(defaultNamespace), serviceAccount(), otherServiceAccount),
+					Args:    []string{"--service-account", "default", "--service-account", "build-sa"},
+					ExpectedOutput: `NAME               TYPE         TARGET                         DEFAULT    BUILD-SA    STATUS
+deleted-secret     -            some-registry.io               yes        no          missing secret
+secret-one         dockerhub    https://index.docker.io/v1/    yes        no          ok
+unlinked-secret    -            some-git-url                   stale      yes         stale annotation,missing secret
+
+`,
+				}.TestK8s(t, cmdFunc)
+			})
+		})
+
+		it("prints the secrets as json", func() {
+			serviceAccount := &corev1.ServiceAccount{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "default",
+					Namespace: defaultNamespace,
+					Annotations: map[string]string{
+						secretcmds.ManagedSecretAnnotationKey: `{"secret-two":"some-git-url"}`,
+					},
+				},
+				Secrets: []corev1.ObjectReference{
+					{Name: "secret-two"},
+					{Name: "deleted-secret"},
+				},
+			}
+
+			createdJson, err := created.MarshalJSON()
+			require.NoError(t, err)
+
+			testhelpers.CommandTest{
+				Objects: append(secrets(defaultNamespace), serviceAccount),
+				Args:    []string{"-o", "json"},
+				ExpectedOutput: `[
+  {
+    "serviceAccount": "default",
+    "name": "deleted-secret",
+    "type": "",
+    "target": "",
+    "linkedAs": [
+      "secret"
+    ],
+    "problems": [
+      "missing secret"
+    ]
+  },
+  {
+    "serviceAccount": "default",
+    "name": "secret-two",
+    "type": "git-basic",
+    "target": "some-git-url",
+    "created": ` + string(createdJson) + `,
+    "linkedAs": [
+      "secret"
+    ]
+  }
+]
+`,
+			}.TestK8s(t, cmdFunc)
+		})
+
+		it("errors with an invalid output format", func() {
+			testhelpers.CommandTest{
+				Args:           []string{"-o", "yaml"},
+				ExpectErr:      true,
+				ExpectedOutput: "Error: invalid output format \"yaml\", must be one of table or json\n",
+			}.TestK8s(t, cmdFunc)
+		})
 	})
 }
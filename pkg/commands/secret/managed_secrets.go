@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 
 	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/secret"
 )
 
-const ManagedSecretAnnotationKey = "kpack.io/managedSecret"
+const ManagedSecretAnnotationKey = secret.ManagedSecretAnnotation
 
 func readManagedSecrets(sa *corev1.ServiceAccount) (map[string]string, error) {
 	if sa.Annotations == nil {
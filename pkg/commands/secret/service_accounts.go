@@ -0,0 +1,70 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+)
+
+const defaultServiceAccount = "default"
+
+func setServiceAccountFlag(cmd *cobra.Command, serviceAccounts *[]string, usage string) {
+	cmd.Flags().StringArrayVar(serviceAccounts, "service-account", []string{defaultServiceAccount}, usage)
+}
+
+// getServiceAccounts returns the named service accounts, failing if any of them does not exist.
+func getServiceAccounts(cs k8s.ClientSet, names []string) ([]*corev1.ServiceAccount, error) {
+	var serviceAccounts []*corev1.ServiceAccount
+	for _, name := range names {
+		sa, err := cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		serviceAccounts = append(serviceAccounts, sa)
+	}
+	return serviceAccounts, nil
+}
+
+// linkSecret adds a secret to the secrets of a service account, and to its image pull secrets
+// for registry secrets, and records the target of the secret in the managed secrets annotation.
+func linkSecret(sa *corev1.ServiceAccount, secret *corev1.Secret, target string) error {
+	if !hasSecret(sa, secret.Name) {
+		sa.Secrets = append(sa.Secrets, corev1.ObjectReference{Name: secret.Name})
+	}
+
+	if secret.Type == corev1.SecretTypeDockerConfigJson && !hasImagePullSecret(sa, secret.Name) {
+		sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: secret.Name})
+	}
+
+	managedSecrets, err := readManagedSecrets(sa)
+	if err != nil {
+		return err
+	}
+
+	managedSecrets[secret.Name] = target
+
+	return writeManagedSecrets(managedSecrets, sa)
+}
+
+func hasSecret(sa *corev1.ServiceAccount, name string) bool {
+	for _, s := range sa.Secrets {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasImagePullSecret(sa *corev1.ServiceAccount, name string) bool {
+	for _, s := range sa.ImagePullSecrets {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
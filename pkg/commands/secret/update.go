@@ -0,0 +1,129 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func NewUpdateCommand(clientSetProvider k8s.ClientSetProvider, secretFactory *secret.Factory, newCredentialFetcher func(*cobra.Command) (secret.CredentialFetcher, error)) *cobra.Command {
+	var (
+		namespace string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update <name>",
+		Short: "Update the credentials of a secret",
+		Long: `Update the credentials of an existing secret in the provided namespace.
+
+The namespace defaults to the kubernetes current-context namespace.
+
+The secret is updated in place and stays linked to its service accounts.
+The flags for this command are the same as for "kp secret create" and must produce a secret of the same kind and for the same
+registries or git url as the existing secret.
+The password prompts and env vars are the same as for "kp secret create".`,
+		Example: `kp secret update my-docker-hub-creds --dockerhub dockerhub-id
+kp secret update my-registry-cred --registry example-registry.io/my-repo --registry-user my-registry-user
+kp secret update my-git-ssh-cred --git-url git@github.com --git-ssh-key /path/to/git/ssh-private-key.pem
+aws ecr get-login-password | kp secret update my-ecr-cred --ecr 123456789012.dkr.ecr.us-east-1.amazonaws.com --password-stdin`,
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			ch, err := commands.NewCommandHelper(cmd)
+			if err != nil {
+				return err
+			}
+
+			secretFactory.CredentialFetcher, err = newCredentialFetcher(cmd)
+			if err != nil {
+				return err
+			}
+
+			readCredentialFileEnvVars(secretFactory)
+
+			existing, err := cs.K8sClient.CoreV1().Secrets(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			updated, target, err := secretFactory.MakeUpdate(existing)
+			if err != nil {
+				return err
+			}
+
+			serviceAccountList, err := cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).List(metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+
+			if !ch.IsDryRun() {
+				updated, err = cs.K8sClient.CoreV1().Secrets(cs.Namespace).Update(updated)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err = ch.PrintObj(updated); err != nil {
+				return err
+			}
+
+			for i := range serviceAccountList.Items {
+				serviceAccount := &serviceAccountList.Items[i]
+
+				modified, err := updateManagedTarget(serviceAccount, updated.Name, target)
+				if err != nil {
+					return err
+				} else if !modified {
+					continue
+				}
+
+				if !ch.IsDryRun() {
+					serviceAccount, err = cs.K8sClient.CoreV1().ServiceAccounts(cs.Namespace).Update(serviceAccount)
+					if err != nil {
+						return err
+					}
+				}
+
+				if err = ch.PrintObj(serviceAccount); err != nil {
+					return err
+				}
+			}
+
+			return ch.PrintResult("Secret %q updated", updated.Name)
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	setCredentialFlags(cmd, secretFactory)
+	commands.SetPasswordFlags(cmd)
+	commands.SetDryRunOutputFlags(cmd)
+	return cmd
+}
+
+// updateManagedTarget records a new target for a secret that the service account already manages.
+func updateManagedTarget(sa *corev1.ServiceAccount, name, target string) (bool, error) {
+	managedSecrets, err := readManagedSecrets(sa)
+	if err != nil {
+		return false, err
+	}
+
+	current, ok := managedSecrets[name]
+	if !ok || current == target {
+		return false, nil
+	}
+
+	managedSecrets[name] = target
+	return true, writeManagedSecrets(managedSecrets, sa)
+}
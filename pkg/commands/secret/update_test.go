@@ -0,0 +1,219 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	secretcmds "github.com/pivotal/build-service-cli/pkg/commands/secret"
+	"github.com/pivotal/build-service-cli/pkg/secret"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestSecretUpdateCommand(t *testing.T) {
+	spec.Run(t, "TestSecretUpdateCommand", testSecretUpdateCommand)
+}
+
+func testSecretUpdateCommand(t *testing.T, when spec.G, it spec.S) {
+	const (
+		defaultNamespace = "some-default-namespace"
+		secretName       = "my-registry-cred"
+	)
+
+	fetcher := &fakeCredentialFetcher{
+		passwords: map[string]string{
+			"REGISTRY_PASSWORD": "new-password",
+		},
+	}
+
+	factory := &secret.Factory{}
+
+	cmdFunc := func(k8sClient *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeK8sProvider(k8sClient, defaultNamespace)
+		return secretcmds.NewUpdateCommand(clientSetProvider, factory, func(*cobra.Command) (secret.CredentialFetcher, error) {
+			return fetcher, nil
+		})
+	}
+
+	existingSecret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      secretName,
+			Namespace: defaultNamespace,
+			Labels:    map[string]string{"some": "label"},
+		},
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"my-registry.io":{"username":"my-user","password":"old-password"}}}`),
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "build-sa",
+			Namespace: defaultNamespace,
+			Annotations: map[string]string{
+				secretcmds.ManagedSecretAnnotationKey: `{"my-registry-cred":"my-registry.io","other-secret":"some-git-url"}`,
+			},
+		},
+		Secrets: []corev1.ObjectReference{
+			{Name: secretName},
+			{Name: "other-secret"},
+		},
+		ImagePullSecrets: []corev1.LocalObjectReference{
+			{Name: secretName},
+		},
+	}
+
+	updatedSecret := func(registry string) *corev1.Secret {
+		s := existingSecret.DeepCopy()
+		s.Data = map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"` + registry + `":{"username":"my-user","password":"new-password"}}}`),
+		}
+		return s
+	}
+
+	it("updates the credentials of the secret in place", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				existingSecret,
+				serviceAccount,
+			},
+			Args: []string{secretName, "--registry", "my-registry.io", "--registry-user", "my-user"},
+			ExpectedOutput: `Secret "my-registry-cred" updated
+`,
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{
+					Object: updatedSecret("my-registry.io"),
+				},
+			},
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("corrects a stale managed secret target of the service accounts", func() {
+		staleServiceAccount := serviceAccount.DeepCopy()
+		staleServiceAccount.Annotations[secretcmds.ManagedSecretAnnotationKey] = `{"my-registry-cred":"old-registry.io","other-secret":"some-git-url"}`
+
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				existingSecret,
+				staleServiceAccount,
+			},
+			Args: []string{secretName, "--registry", "my-registry.io", "--registry-user", "my-user"},
+			ExpectedOutput: `Secret "my-registry-cred" updated
+`,
+			ExpectUpdates: []clientgotesting.UpdateActionImpl{
+				{
+					Object: updatedSecret("my-registry.io"),
+				},
+				{
+					Object: serviceAccount,
+				},
+			},
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("errors when the target of the secret would change", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				existingSecret,
+				serviceAccount,
+			},
+			Args:      []string{secretName, "--registry", "new-registry.io", "--registry-user", "my-user"},
+			ExpectErr: true,
+			ExpectedOutput: `Error: cannot change the target of secret "my-registry-cred" from "my-registry.io" to "new-registry.io"
+`,
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("errors when the kind of the secret would change", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				existingSecret,
+				serviceAccount,
+			},
+			Args:      []string{secretName, "--dockerhub", "my-dockerhub-id"},
+			ExpectErr: true,
+			ExpectedOutput: `Error: cannot change the kind of secret "my-registry-cred" from "registry" to "dockerhub"
+`,
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("errors when the secret type would change", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				existingSecret,
+				serviceAccount,
+			},
+			Args:      []string{secretName, "--git-url", "https://github.com", "--git-user", "my-user"},
+			ExpectErr: true,
+			ExpectedOutput: `Error: cannot change the type of secret "my-registry-cred" from "kubernetes.io/dockerconfigjson" to "kubernetes.io/basic-auth"
+`,
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("errors when the secret does not exist", func() {
+		testhelpers.CommandTest{
+			Objects: []runtime.Object{
+				serviceAccount,
+			},
+			Args:      []string{secretName, "--registry", "my-registry.io", "--registry-user", "my-user"},
+			ExpectErr: true,
+			ExpectedOutput: `Error: secrets "my-registry-cred" not found
+`,
+		}.TestK8s(t, cmdFunc)
+	})
+
+	when("dry-run flag is used", func() {
+		it("does not update the secret and prints result with dry run indicated", func() {
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					existingSecret,
+					serviceAccount,
+				},
+				Args: []string{secretName, "--registry", "my-registry.io", "--registry-user", "my-user", "--dry-run"},
+				ExpectedOutput: `Secret "my-registry-cred" updated (dry run)
+`,
+			}.TestK8s(t, cmdFunc)
+		})
+	})
+
+	when("output flag is used", func() {
+		it("updates the secret and prints resource output", func() {
+			const resourceYAML = `apiVersion: v1
+data:
+  .dockerconfigjson: eyJhdXRocyI6eyJteS1yZWdpc3RyeS5pbyI6eyJ1c2VybmFtZSI6Im15LXVzZXIiLCJwYXNzd29yZCI6Im5ldy1wYXNzd29yZCJ9fX0=
+kind: Secret
+metadata:
+  creationTimestamp: null
+  labels:
+    some: label
+  name: my-registry-cred
+  namespace: some-default-namespace
+type: kubernetes.io/dockerconfigjson
+`
+
+			testhelpers.CommandTest{
+				Objects: []runtime.Object{
+					existingSecret,
+					serviceAccount,
+				},
+				Args:           []string{secretName, "--registry", "my-registry.io", "--registry-user", "my-user", "--output", "yaml"},
+				ExpectedOutput: resourceYAML,
+				ExpectUpdates: []clientgotesting.UpdateActionImpl{
+					{
+						Object: updatedSecret("my-registry.io"),
+					},
+				},
+			}.TestK8s(t, cmdFunc)
+		})
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/commands"
+	"github.com/pivotal/build-service-cli/pkg/k8s"
+	"github.com/pivotal/build-service-cli/pkg/registry"
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func NewVerifyCommand(clientSetProvider k8s.ClientSetProvider) *cobra.Command {
+	var (
+		namespace string
+		gitRepo   string
+		tlsCfg    registry.TLSConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify <name>",
+		Short: "Verify the credentials of a secret",
+		Long: `Verify that the credentials of a secret in the provided namespace are accepted by their targets.
+
+The namespace defaults to the kubernetes current-context namespace.
+
+Registry credentials are authenticated against the /v2/ endpoint of each registry in the secret.
+Git basic auth credentials are used to fetch the refs of the repository provided with "--git-repo", which is required for them.
+The repository must have the same scheme and host as the git url of the secret.
+Git SSH credentials are used in an SSH handshake with the git host, checking the host key when the secret contains known hosts.`,
+		Example: `kp secret verify my-registry-cred
+kp secret verify my-git-cred --git-repo https://github.com/my-org/my-repo`,
+		Args:         commands.ExactArgsWithUsage(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := clientSetProvider.GetClientSet(namespace)
+			if err != nil {
+				return err
+			}
+
+			s, err := cs.K8sClient.CoreV1().Secrets(cs.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			if s.Type == corev1.SecretTypeBasicAuth && gitRepo == "" {
+				return errors.Errorf("--git-repo is required to verify the git basic auth credentials of secret %q", s.Name)
+			}
+
+			transport, err := tlsCfg.Transport()
+			if err != nil {
+				return err
+			}
+
+			verifier := secret.Verifier{
+				Transport:  transport,
+				SshTimeout: 30 * time.Second,
+				GitRepo:    gitRepo,
+			}
+
+			results, err := verifier.Verify(s)
+			if err != nil {
+				return err
+			}
+
+			writer, err := commands.NewTableWriter(cmd.OutOrStdout(), "Target", "Result")
+			if err != nil {
+				return err
+			}
+
+			failed := false
+			for _, result := range results {
+				status := "valid"
+				if result.Err != nil {
+					status = result.Err.Error()
+					failed = true
+				}
+
+				if err := writer.AddRow(result.Target, status); err != nil {
+					return err
+				}
+			}
+
+			if err := writer.Write(); err != nil {
+				return err
+			}
+
+			if failed {
+				return errors.Errorf("credentials of secret %q could not be verified", s.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVar(&gitRepo, "git-repo", "", "git repository on the git url of the secret to fetch the refs of, required to verify git basic auth credentials")
+	commands.SetTLSFlags(cmd, &tlsCfg)
+	return cmd
+}
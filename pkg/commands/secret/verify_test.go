@@ -0,0 +1,118 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	secretcmds "github.com/pivotal/build-service-cli/pkg/commands/secret"
+	"github.com/pivotal/build-service-cli/pkg/testhelpers"
+)
+
+func TestSecretVerifyCommand(t *testing.T) {
+	spec.Run(t, "TestSecretVerifyCommand", testSecretVerifyCommand)
+}
+
+func testSecretVerifyCommand(t *testing.T, when spec.G, it spec.S) {
+	const defaultNamespace = "some-default-namespace"
+
+	var registry *httptest.Server
+
+	it.Before(func() {
+		registry = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, password, ok := r.BasicAuth(); !ok || user != "some-user" || password != "some-password" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="some-registry"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	it.After(func() {
+		registry.Close()
+	})
+
+	cmdFunc := func(k8sClient *fake.Clientset) *cobra.Command {
+		clientSetProvider := testhelpers.GetFakeK8sProvider(k8sClient, defaultNamespace)
+		return secretcmds.NewVerifyCommand(clientSetProvider)
+	}
+
+	registrySecret := func(password string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "some-secret",
+				Namespace: defaultNamespace,
+			},
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(fmt.Sprintf(`{"auths":{"%s":{"username":"some-user","password":"%s"}}}`, registry.Listener.Addr().String(), password)),
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+		}
+	}
+
+	table := func(target, result string) string {
+		padding := strings.Repeat(" ", len(target)-len("TARGET"))
+		return fmt.Sprintf("TARGET%s    RESULT\n%s    %s\n\n", padding, target, result)
+	}
+
+	it("reports the credentials that are valid", func() {
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{registrySecret("some-password")},
+			Args:           []string{"some-secret"},
+			ExpectedOutput: table(registry.Listener.Addr().String(), "valid"),
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("reports the credentials that are rejected and fails", func() {
+		testhelpers.CommandTest{
+			Objects:   []runtime.Object{registrySecret("wrong-password")},
+			Args:      []string{"some-secret"},
+			ExpectErr: true,
+			ExpectedOutput: table(registry.Listener.Addr().String(), "invalid credentials (401 Unauthorized)") +
+				"Error: credentials of secret \"some-secret\" could not be verified\n",
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("requires a git repository to verify git basic auth credentials", func() {
+		gitSecret := &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "some-git-secret",
+				Namespace:   defaultNamespace,
+				Annotations: map[string]string{"kpack.io/git": "https://github.com"},
+			},
+			Data: map[string][]byte{
+				corev1.BasicAuthUsernameKey: []byte("some-user"),
+				corev1.BasicAuthPasswordKey: []byte("some-password"),
+			},
+			Type: corev1.SecretTypeBasicAuth,
+		}
+
+		testhelpers.CommandTest{
+			Objects:        []runtime.Object{gitSecret},
+			Args:           []string{"some-git-secret"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: --git-repo is required to verify the git basic auth credentials of secret \"some-git-secret\"\n",
+		}.TestK8s(t, cmdFunc)
+	})
+
+	it("errors when the secret does not exist", func() {
+		testhelpers.CommandTest{
+			Args:           []string{"some-secret"},
+			ExpectErr:      true,
+			ExpectedOutput: "Error: secrets \"some-secret\" not found\n",
+		}.TestK8s(t, cmdFunc)
+	})
+}
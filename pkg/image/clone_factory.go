@@ -0,0 +1,71 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MakeClone copies the spec and labels of an image to a new image with the provided name, namespace and tag.
+// Status, annotations and server populated metadata are not copied.
+// Source, builder, env and cache size flags override the copied configuration.
+func (f *Factory) MakeClone(src *v1alpha1.Image, name, namespace, tag string) (*v1alpha1.Image, error) {
+	if len(f.DeleteEnv) > 0 {
+		return nil, errors.New("delete-env is not supported when cloning an image")
+	}
+
+	clone := &v1alpha1.Image{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Image",
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: *src.Spec.DeepCopy(),
+	}
+
+	if len(src.Labels) > 0 {
+		clone.Labels = map[string]string{}
+		for k, v := range src.Labels {
+			clone.Labels[k] = v
+		}
+	}
+
+	clone.Spec.Tag = tag
+	if clone.Spec.Build == nil {
+		clone.Spec.Build = &v1alpha1.ImageBuild{}
+	}
+
+	if clone.Spec.Builder.Kind == v1alpha1.BuilderKind {
+		clone.Spec.Builder.Namespace = namespace
+	}
+
+	if err := f.validatePatch(clone); err != nil {
+		return nil, err
+	}
+
+	if err := f.setSource(clone); err != nil {
+		return nil, err
+	}
+
+	if f.CacheSize != "" {
+		cacheSize, err := f.getCacheSize()
+		if err != nil {
+			return nil, err
+		}
+		clone.Spec.CacheSize = cacheSize
+	}
+
+	if err := f.setBuild(clone); err != nil {
+		return nil, err
+	}
+
+	f.setBuilder(clone)
+
+	return clone, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/image"
+	srcfakes "github.com/pivotal/build-service-cli/pkg/registry/fakes"
+)
+
+func TestCloneFactory(t *testing.T) {
+	spec.Run(t, "TestCloneFactory", testCloneFactory)
+}
+
+func testCloneFactory(t *testing.T, when spec.G, it spec.S) {
+	factory := image.Factory{
+		SourceUploader: &srcfakes.SourceUploader{
+			ImageRef: "",
+		},
+	}
+
+	src := &v1alpha1.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "some-image",
+			Namespace:       "some-namespace",
+			ResourceVersion: "3",
+			Annotations:     map[string]string{"some-annotation": "some-value"},
+		},
+		Spec: v1alpha1.ImageSpec{
+			Tag: "some-registry.io/some-repo",
+			Builder: corev1.ObjectReference{
+				Kind:      v1alpha1.BuilderKind,
+				Namespace: "some-namespace",
+				Name:      "some-builder",
+			},
+			Source: v1alpha1.SourceConfig{
+				Git: &v1alpha1.Git{
+					URL:      "some-git-url",
+					Revision: "some-revision",
+				},
+			},
+		},
+		Status: v1alpha1.ImageStatus{
+			LatestImage: "some-registry.io/some-repo@sha256:123",
+		},
+	}
+
+	it("copies the spec without status and server metadata", func() {
+		clone, err := factory.MakeClone(src, "some-new-image", "some-other-namespace", "some-registry.io/some-new-repo")
+		require.NoError(t, err)
+
+		require.Equal(t, "Image", clone.Kind)
+		require.Equal(t, "some-new-image", clone.Name)
+		require.Equal(t, "some-other-namespace", clone.Namespace)
+		require.Empty(t, clone.ResourceVersion)
+		require.Empty(t, clone.Annotations)
+		require.Equal(t, v1alpha1.ImageStatus{}, clone.Status)
+		require.Equal(t, "some-registry.io/some-new-repo", clone.Spec.Tag)
+		require.Equal(t, "some-other-namespace", clone.Spec.Builder.Namespace)
+		require.Equal(t, src.Spec.Source, clone.Spec.Source)
+	})
+
+	it("does not modify the source image", func() {
+		factory.GitRevision = "some-other-revision"
+		_, err := factory.MakeClone(src, "some-new-image", "some-namespace", "some-registry.io/some-new-repo")
+		require.NoError(t, err)
+
+		require.Equal(t, "some-revision", src.Spec.Source.Git.Revision)
+	})
+
+	when("delete-env is provided", func() {
+		it("returns an error message", func() {
+			factory.DeleteEnv = []string{"foo"}
+			_, err := factory.MakeClone(src, "some-new-image", "some-namespace", "some-registry.io/some-new-repo")
+			require.EqualError(t, err, "delete-env is not supported when cloning an image")
+		})
+	})
+}
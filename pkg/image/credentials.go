@@ -0,0 +1,141 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+const (
+	RegistryCredential = "registry"
+	GitCredential      = "git"
+)
+
+var invalidSecretNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// MissingCredential is a registry host or git url an image needs credentials for
+// that its service account does not have.
+type MissingCredential struct {
+	Type   string
+	Target string
+}
+
+// SecretCreateFlags returns the "kp secret create" flags that would create the missing credential.
+func (m MissingCredential) SecretCreateFlags() []string {
+	if m.Type == GitCredential {
+		return secret.GitCreateFlags(m.Target)
+	}
+	return secret.RegistryCreateFlags(m.Target)
+}
+
+// SecretName returns a name for a secret with the missing credential, such as "gcr-io-registry".
+func (m MissingCredential) SecretName() string {
+	host := m.Target
+	if m.Type == GitCredential {
+		host = secret.GitHost(m.Target)
+	}
+	return strings.Trim(invalidSecretNameChars.ReplaceAllString(strings.ToLower(host), "-"), "-") + "-" + m.Type
+}
+
+// CredentialChecker compares the registry of the tag and the git repository of an image with
+// the secrets linked to its service account and the targets of its managed secrets.
+type CredentialChecker struct {
+	K8sClient kubernetes.Interface
+	// Transport is used to check whether an https git repository can be read without credentials.
+	Transport http.RoundTripper
+	Timeout   time.Duration
+}
+
+func (c CredentialChecker) MissingCredentials(img *v1alpha1.Image) ([]MissingCredential, error) {
+	credentials, err := c.serviceAccountCredentials(img.Namespace, img.Spec.ServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []MissingCredential
+	if ref, err := name.ParseReference(img.Spec.Tag, name.WeakValidation); err == nil {
+		if host := ref.Context().RegistryStr(); !credentials.HasRegistry(host) {
+			missing = append(missing, MissingCredential{Type: RegistryCredential, Target: host})
+		}
+	}
+
+	if git := img.Spec.Source.Git; git != nil && !credentials.HasGit(git.URL) && c.requiresGitCredentials(git.URL) {
+		missing = append(missing, MissingCredential{Type: GitCredential, Target: git.URL})
+	}
+
+	return missing, nil
+}
+
+func (c CredentialChecker) serviceAccountCredentials(namespace, serviceAccount string) (secret.Credentials, error) {
+	var credentials secret.Credentials
+
+	sa, err := c.K8sClient.CoreV1().ServiceAccounts(namespace).Get(serviceAccount, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return credentials, nil
+	} else if err != nil {
+		return credentials, err
+	}
+
+	managedSecrets := map[string]string{}
+	if annotation := sa.Annotations[secret.ManagedSecretAnnotation]; annotation != "" {
+		if err := json.Unmarshal([]byte(annotation), &managedSecrets); err != nil {
+			return credentials, err
+		}
+	}
+
+	for _, name := range linkedSecretNames(sa) {
+		credentials.AddTarget(managedSecrets[name])
+
+		s, err := c.K8sClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return credentials, err
+		}
+		credentials.Add(s)
+	}
+
+	return credentials, nil
+}
+
+func linkedSecretNames(sa *corev1.ServiceAccount) []string {
+	var names []string
+	for _, s := range sa.Secrets {
+		names = append(names, s.Name)
+	}
+	for _, s := range sa.ImagePullSecrets {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// requiresGitCredentials reports whether a git repository cannot be read anonymously.
+// Ssh repositories always require credentials. Https repositories that cannot be reached are assumed to be public.
+func (c CredentialChecker) requiresGitCredentials(gitUrl string) bool {
+	if secret.IsSshGitUrl(gitUrl) {
+		return true
+	}
+
+	client := &http.Client{Transport: c.Transport, Timeout: c.Timeout}
+	resp, err := client.Get(strings.TrimSuffix(gitUrl, "/") + "/info/refs?service=git-upload-pack")
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden
+}
@@ -0,0 +1,160 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfakes "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/pivotal/build-service-cli/pkg/image"
+)
+
+func TestCredentialChecker(t *testing.T) {
+	spec.Run(t, "TestCredentialChecker", testCredentialChecker)
+}
+
+func testCredentialChecker(t *testing.T, when spec.G, it spec.S) {
+	const namespace = "some-namespace"
+
+	var (
+		gitServer *httptest.Server
+		gitStatus int
+	)
+
+	it.Before(func() {
+		gitStatus = http.StatusOK
+		gitServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/some-org/some-repo/info/refs", r.URL.Path)
+			w.WriteHeader(gitStatus)
+		}))
+	})
+
+	it.After(func() {
+		gitServer.Close()
+	})
+
+	newImage := func(tag, gitUrl string) *v1alpha1.Image {
+		img := &v1alpha1.Image{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-image",
+				Namespace: namespace,
+			},
+			Spec: v1alpha1.ImageSpec{
+				Tag:            tag,
+				ServiceAccount: "some-sa",
+			},
+		}
+		if gitUrl != "" {
+			img.Spec.Source.Git = &v1alpha1.Git{URL: gitUrl, Revision: "master"}
+		}
+		return img
+	}
+
+	missingCredentials := func(img *v1alpha1.Image, objects ...runtime.Object) []image.MissingCredential {
+		checker := image.CredentialChecker{K8sClient: k8sfakes.NewSimpleClientset(objects...)}
+		missing, err := checker.MissingCredentials(img)
+		require.NoError(t, err)
+		return missing
+	}
+
+	serviceAccount := func(annotations map[string]string, secrets ...string) *corev1.ServiceAccount {
+		sa := &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "some-sa",
+				Namespace:   namespace,
+				Annotations: annotations,
+			},
+		}
+		for _, s := range secrets {
+			sa.Secrets = append(sa.Secrets, corev1.ObjectReference{Name: s})
+		}
+		return sa
+	}
+
+	registrySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "registry-secret",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://some-registry.io/v1/":{"username":"some-user","password":"some-password"}}}`),
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}
+
+	gitSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "git-secret",
+			Namespace:   namespace,
+			Annotations: map[string]string{"kpack.io/git": "git@github.com"},
+		},
+		Type: corev1.SecretTypeSSHAuth,
+	}
+
+	it("reports the registry of the tag when the service account does not exist", func() {
+		missing := missingCredentials(newImage("some-registry.io/some-repo", ""))
+		require.Equal(t, []image.MissingCredential{{Type: image.RegistryCredential, Target: "some-registry.io"}}, missing)
+	})
+
+	it("uses the registries of the linked secrets", func() {
+		missing := missingCredentials(newImage("some-registry.io/some-repo:some-tag", ""), serviceAccount(nil, "registry-secret"), registrySecret)
+		require.Empty(t, missing)
+	})
+
+	it("uses the targets of managed secrets", func() {
+		sa := serviceAccount(map[string]string{"kpack.io/managedSecret": `{"managed-secret":"index.docker.io,some-registry.io"}`})
+		sa.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "managed-secret"}}
+
+		missing := missingCredentials(newImage("some-user/some-repo", ""), sa)
+		require.Empty(t, missing)
+	})
+
+	it("ignores secrets that are linked but do not exist", func() {
+		missing := missingCredentials(newImage("some-registry.io/some-repo", ""), serviceAccount(nil, "registry-secret"))
+		require.Equal(t, []image.MissingCredential{{Type: image.RegistryCredential, Target: "some-registry.io"}}, missing)
+	})
+
+	it("reports ssh git repositories without credentials for the host", func() {
+		missing := missingCredentials(newImage("some-registry.io/some-repo", "git@gitlab.com:some-org/some-repo.git"), serviceAccount(nil, "registry-secret", "git-secret"), registrySecret, gitSecret)
+		require.Equal(t, []image.MissingCredential{{Type: image.GitCredential, Target: "git@gitlab.com:some-org/some-repo.git"}}, missing)
+	})
+
+	it("uses git credentials for the host of the repository", func() {
+		missing := missingCredentials(newImage("some-registry.io/some-repo", "git@github.com:some-org/some-repo.git"), serviceAccount(nil, "registry-secret", "git-secret"), registrySecret, gitSecret)
+		require.Empty(t, missing)
+	})
+
+	it("does not report https git repositories that can be read without credentials", func() {
+		missing := missingCredentials(newImage("some-registry.io/some-repo", gitServer.URL+"/some-org/some-repo"), serviceAccount(nil, "registry-secret"), registrySecret)
+		require.Empty(t, missing)
+	})
+
+	it("reports https git repositories that require credentials", func() {
+		gitStatus = http.StatusUnauthorized
+
+		missing := missingCredentials(newImage("some-registry.io/some-repo", gitServer.URL+"/some-org/some-repo"), serviceAccount(nil, "registry-secret"), registrySecret)
+		require.Equal(t, []image.MissingCredential{{Type: image.GitCredential, Target: gitServer.URL + "/some-org/some-repo"}}, missing)
+	})
+
+	when("suggesting secrets", func() {
+		it("names the secret after the host", func() {
+			require.Equal(t, "some-registry-io-5000-registry", image.MissingCredential{Type: image.RegistryCredential, Target: "some-registry.io:5000"}.SecretName())
+			require.Equal(t, "github-com-git", image.MissingCredential{Type: image.GitCredential, Target: "https://github.com/some-org/some-repo"}.SecretName())
+		})
+
+		it("suggests the secret create flags for the host", func() {
+			require.Equal(t, []string{"--gcr", "<gcr-service-account-path>"}, image.MissingCredential{Type: image.RegistryCredential, Target: "gcr.io"}.SecretCreateFlags())
+			require.Equal(t, []string{"--git-url", "https://git.example.com", "--git-user", "<git-user>"}, image.MissingCredential{Type: image.GitCredential, Target: "https://git.example.com/some-org/some-repo"}.SecretCreateFlags())
+		})
+	})
+}
@@ -0,0 +1,40 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package fakes
+
+import (
+	"io"
+
+	"github.com/pivotal/kpack/pkg/apis/build/v1alpha1"
+	"github.com/spf13/cobra"
+
+	"github.com/pivotal/build-service-cli/pkg/image"
+)
+
+type FakeCredentialChecker struct {
+	Missing []image.MissingCredential
+	Calls   []*v1alpha1.Image
+}
+
+func (f *FakeCredentialChecker) MissingCredentials(img *v1alpha1.Image) ([]image.MissingCredential, error) {
+	f.Calls = append(f.Calls, img)
+	return f.Missing, nil
+}
+
+type FakeCredentialHandOff struct {
+	Create bool
+	// Output is written to the output of the hand off for each created credential
+	Output string
+	Offers []image.MissingCredential
+}
+
+func (f *FakeCredentialHandOff) Offer(cmd *cobra.Command, out io.Writer, img *v1alpha1.Image, missing image.MissingCredential) (bool, error) {
+	f.Offers = append(f.Offers, missing)
+	if f.Create && f.Output != "" {
+		if _, err := io.WriteString(out, f.Output); err != nil {
+			return false, err
+		}
+	}
+	return f.Create, nil
+}
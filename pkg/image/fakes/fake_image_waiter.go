@@ -11,10 +11,14 @@ import (
 )
 
 type FakeImageWaiter struct {
-	Calls []*v1alpha1.Image
+	Calls  []*v1alpha1.Image
+	Errors map[string]error
 }
 
 func (f *FakeImageWaiter) Wait(ctx context.Context, writer io.Writer, image *v1alpha1.Image) (string, error) {
 	f.Calls = append(f.Calls, image)
-	return "", nil
+	if err, ok := f.Errors[image.Name]; ok {
+		return "", err
+	}
+	return image.Status.LatestImage, nil
 }
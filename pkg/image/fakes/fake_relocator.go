@@ -14,6 +14,7 @@ import (
 )
 
 type Relocator struct {
+	Tags []string
 }
 
 func (r *Relocator) Relocate(image v1.Image, dest string, _ io.Writer, _ registry.TLSConfig) (string, error) {
@@ -30,3 +31,8 @@ func (r *Relocator) Relocate(image v1.Image, dest string, _ io.Writer, _ registr
 
 	return fmt.Sprintf("%s/%s@%s", destRef.Context().RegistryStr(), destRef.Context().RepositoryStr(), sha), nil
 }
+
+func (r *Relocator) Tag(image v1.Image, tag string, _ io.Writer, _ registry.TLSConfig) (string, error) {
+	r.Tags = append(r.Tags, tag)
+	return r.Relocate(image, tag, nil, registry.TLSConfig{})
+}
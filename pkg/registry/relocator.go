@@ -128,3 +128,30 @@ func timestampTag() string {
 	now := time.Now()
 	return fmt.Sprintf("%s%02d%02d%02d", now.Format("20060102"), now.Hour(), now.Minute(), now.Second())
 }
+
+// Tag writes an image to the provided tag using the default keychain and returns its digest reference.
+func (r RelocatorImpl) Tag(srcImage v1.Image, tag string, writer io.Writer, tlsCfg TLSConfig) (string, error) {
+	tagRef, err := name.NewTag(tag, name.WeakValidation)
+	if err != nil {
+		return "", err
+	}
+
+	digest, err := srcImage.Digest()
+	if err != nil {
+		return "", err
+	}
+
+	transport, err := tlsCfg.Transport()
+	if err != nil {
+		return "", err
+	}
+
+	refDigestStr := fmt.Sprintf("%s@%s", tagRef.Context(), digest)
+	writer.Write([]byte(fmt.Sprintf("\tTagging '%s' as '%s'\n", refDigestStr, tagRef)))
+
+	err = remote.Write(tagRef, srcImage, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithTransport(transport))
+	if err != nil {
+		return refDigestStr, newImageAccessError(tagRef.Context().RegistryStr(), err)
+	}
+	return refDigestStr, nil
+}
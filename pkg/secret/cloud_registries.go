@@ -0,0 +1,103 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EcrUser is the username of the authorization tokens of amazon elastic container registries.
+const EcrUser = "AWS"
+
+var (
+	acrRegistryPattern = regexp.MustCompile(`^[a-z0-9]+\.azurecr\.(io|cn|us)$`)
+	acrClientIdPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	ecrRegistryPattern = regexp.MustCompile(`^[0-9]{12}\.dkr\.ecr(-fips)?\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+)
+
+func (f *Factory) validateAcr() error {
+	if !acrRegistryPattern.MatchString(f.AcrRegistry) {
+		return errors.Errorf("must provide a valid azure container registry (ex. myregistry.azurecr.io)")
+	}
+
+	if !acrClientIdPattern.MatchString(f.AcrClientId) {
+		return errors.Errorf("must provide the application id of the service principal as the acr client id")
+	}
+	return nil
+}
+
+// makeAcrSecret creates credentials for an azure container registry using a service principal,
+// whose application id and client secret are the username and password of the registry.
+func (f *Factory) makeAcrSecret(name string, namespace string) (*corev1.Secret, string, error) {
+	password, err := f.CredentialFetcher.FetchPassword("ACR_CLIENT_SECRET", "acr service principal client secret: ")
+	if err != nil {
+		return nil, "", err
+	}
+
+	configJson := DockerConfigJson{Auths: DockerCredentials{
+		f.AcrRegistry: authn.AuthConfig{
+			Username: f.AcrClientId,
+			Password: password,
+		},
+	}}
+	dockerCfgJson, err := json.Marshal(configJson)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerCfgJson,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}, f.AcrRegistry, nil
+}
+
+func (f *Factory) validateEcr() error {
+	if !ecrRegistryPattern.MatchString(f.EcrRegistry) {
+		return errors.Errorf("must provide a valid ecr registry (ex. 123456789012.dkr.ecr.us-east-1.amazonaws.com)")
+	}
+	return nil
+}
+
+// makeEcrSecret creates credentials for an amazon elastic container registry from an authorization token,
+// as printed by "aws ecr get-login-password". The tokens expire after 12 hours.
+func (f *Factory) makeEcrSecret(name string, namespace string) (*corev1.Secret, string, error) {
+	password, err := f.CredentialFetcher.FetchPassword("ECR_PASSWORD", "ecr password: ")
+	if err != nil {
+		return nil, "", err
+	}
+
+	configJson := DockerConfigJson{Auths: DockerCredentials{
+		f.EcrRegistry: authn.AuthConfig{
+			Username: EcrUser,
+			Password: password,
+		},
+	}}
+	dockerCfgJson, err := json.Marshal(configJson)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerCfgJson,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}, f.EcrRegistry, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DockerAnnotation marks basic auth secrets that kpack uses as registry credentials.
+	DockerAnnotation = "kpack.io/docker"
+	// ManagedSecretAnnotation records the targets of the secrets kp links to a service account.
+	ManagedSecretAnnotation = "kpack.io/managedSecret"
+)
+
+// Credentials are the registries and git urls a set of secrets has credentials for.
+type Credentials struct {
+	registries map[string]bool
+	gitUrls    []string
+}
+
+// Add records the registries or git url of a secret.
+func (c *Credentials) Add(s *corev1.Secret) {
+	switch s.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		var config DockerConfigJson
+		if err := json.Unmarshal(s.Data[corev1.DockerConfigJsonKey], &config); err == nil {
+			for registry := range config.Auths {
+				c.addRegistry(registry)
+			}
+		}
+	case corev1.SecretTypeDockercfg:
+		var auths DockerCredentials
+		if err := json.Unmarshal(s.Data[corev1.DockerConfigKey], &auths); err == nil {
+			for registry := range auths {
+				c.addRegistry(registry)
+			}
+		}
+	case corev1.SecretTypeBasicAuth, corev1.SecretTypeSSHAuth:
+		if registry, ok := s.Annotations[DockerAnnotation]; ok && s.Type == corev1.SecretTypeBasicAuth {
+			c.addRegistry(registry)
+		}
+		if gitUrl, ok := s.Annotations[GitAnnotation]; ok {
+			c.gitUrls = append(c.gitUrls, gitUrl)
+		}
+	}
+}
+
+// AddTarget records a managed secret target, the git url or comma separated registries of a secret.
+func (c *Credentials) AddTarget(target string) {
+	for _, t := range strings.Split(target, ",") {
+		switch {
+		case t == "":
+		case isGitUrl(t):
+			c.gitUrls = append(c.gitUrls, t)
+		default:
+			c.addRegistry(t)
+		}
+	}
+}
+
+func (c *Credentials) addRegistry(registry string) {
+	if c.registries == nil {
+		c.registries = map[string]bool{}
+	}
+	c.registries[RegistryHost(registry)] = true
+}
+
+// HasRegistry reports whether there are credentials for a registry host.
+func (c Credentials) HasRegistry(host string) bool {
+	return c.registries[RegistryHost(host)]
+}
+
+// HasGit reports whether there are credentials for the host of a git url, using the same protocol.
+func (c Credentials) HasGit(gitUrl string) bool {
+	for _, u := range c.gitUrls {
+		if IsSshGitUrl(u) == IsSshGitUrl(gitUrl) && GitHost(u) == GitHost(gitUrl) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSshGitUrl reports whether a git url uses ssh, such as git@github.com:my-org/my-repo.
+func IsSshGitUrl(gitUrl string) bool {
+	return strings.HasPrefix(gitUrl, "ssh://") || !strings.Contains(gitUrl, "://") && strings.Contains(gitUrl, "@")
+}
+
+// GitHost returns the host of a git url.
+func GitHost(gitUrl string) string {
+	if strings.Contains(gitUrl, "://") {
+		u, err := url.Parse(gitUrl)
+		if err != nil {
+			return ""
+		}
+		return u.Hostname()
+	}
+
+	host := gitUrl[strings.Index(gitUrl, "@")+1:]
+	if i := strings.IndexAny(host, ":/"); i >= 0 {
+		host = host[:i]
+	}
+	return host
+}
+
+func isGitUrl(target string) bool {
+	return isHttpUrl(target) || IsSshGitUrl(target)
+}
+
+// RegistryCreateFlags returns the "kp secret create" flags for credentials of a registry host.
+// Values that must be provided by the user are placeholders such as "<registry-user>".
+func RegistryCreateFlags(host string) []string {
+	host = RegistryHost(host)
+	switch {
+	case host == RegistryHost(DockerhubUrl):
+		return []string{"--dockerhub", "<dockerhub-id>"}
+	case host == GcrUrl:
+		return []string{"--gcr", "<gcr-service-account-path>"}
+	case acrRegistryPattern.MatchString(host):
+		return []string{"--acr", host, "--acr-client-id", "<acr-client-id>"}
+	case ecrRegistryPattern.MatchString(host):
+		return []string{"--ecr", host}
+	default:
+		return []string{"--registry", host, "--registry-user", "<registry-user>"}
+	}
+}
+
+// GitCreateFlags returns the "kp secret create" flags for credentials of the host of a git url.
+// Values that must be provided by the user are placeholders such as "<git-user>".
+func GitCreateFlags(gitUrl string) []string {
+	host := GitHost(gitUrl)
+	if IsSshGitUrl(gitUrl) {
+		return []string{"--git-url", "git@" + host, "--git-ssh-key", "<git-ssh-key-path>"}
+	}
+
+	scheme := "https://"
+	if strings.HasPrefix(gitUrl, "http://") {
+		scheme = "http://"
+	}
+
+	if _, ok := gitTokenUsers[host]; ok {
+		return []string{"--git-url", scheme + host, "--git-token"}
+	}
+	return []string{"--git-url", scheme + host, "--git-user", "<git-user>"}
+}
+
+// IsPlaceholder reports whether a flag value is a placeholder for a value the user must provide.
+func IsPlaceholder(value string) bool {
+	return strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">")
+}
@@ -0,0 +1,68 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func TestCredentials(t *testing.T) {
+	spec.Run(t, "TestCredentials", testCredentials)
+}
+
+func testCredentials(t *testing.T, when spec.G, it spec.S) {
+	var credentials secret.Credentials
+
+	it.Before(func() {
+		credentials = secret.Credentials{}
+	})
+
+	it("records the registries of docker config secrets", func() {
+		credentials.Add(&corev1.Secret{
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{},"some-registry.io":{}}}`),
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+		})
+
+		require.True(t, credentials.HasRegistry("index.docker.io"))
+		require.True(t, credentials.HasRegistry("some-registry.io"))
+		require.False(t, credentials.HasRegistry("gcr.io"))
+	})
+
+	it("records the registry of basic auth secrets with the docker annotation", func() {
+		credentials.Add(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{secret.DockerAnnotation: "some-registry.io"}},
+			Type:       corev1.SecretTypeBasicAuth,
+		})
+
+		require.True(t, credentials.HasRegistry("some-registry.io"))
+	})
+
+	it("matches git credentials by host and protocol", func() {
+		credentials.Add(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{secret.GitAnnotation: "git@github.com"}},
+			Type:       corev1.SecretTypeSSHAuth,
+		})
+		credentials.AddTarget("https://gitlab.com")
+
+		require.True(t, credentials.HasGit("git@github.com:some-org/some-repo.git"))
+		require.True(t, credentials.HasGit("ssh://git@github.com/some-org/some-repo.git"))
+		require.False(t, credentials.HasGit("https://github.com/some-org/some-repo"))
+		require.True(t, credentials.HasGit("https://gitlab.com/some-org/some-repo"))
+		require.False(t, credentials.HasGit("git@gitlab.com:some-org/some-repo.git"))
+	})
+
+	it("suggests git token secrets for hosts with a known token username", func() {
+		require.Equal(t, []string{"--git-url", "https://github.com", "--git-token"}, secret.GitCreateFlags("https://github.com/some-org/some-repo"))
+		require.Equal(t, []string{"--git-url", "git@github.com", "--git-ssh-key", "<git-ssh-key-path>"}, secret.GitCreateFlags("git@github.com:some-org/some-repo.git"))
+	})
+}
@@ -0,0 +1,221 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	DefaultDockerConfigPath = "~/.docker/config.json"
+
+	dockerConfigFileName = "config.json"
+	dockerConfigEnvVar   = "DOCKER_CONFIG"
+)
+
+type dockerConfigFile struct {
+	Auths       map[string]json.RawMessage `json:"auths"`
+	CredHelpers map[string]string          `json:"credHelpers"`
+}
+
+func (f *Factory) makeDockerConfigSecret(name, namespace string) (*corev1.Secret, string, error) {
+	configDir, err := dockerConfigDir(f.DockerConfigPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	registries, err := readDockerConfigRegistries(configDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	registries, err = filterRegistries(registries, f.RegistryFilter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	credentials, err := resolveDockerConfigCredentials(configDir, registries)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dockerCfgJson, err := json.Marshal(DockerConfigJson{Auths: credentials})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var targets []string
+	for target := range credentials {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerCfgJson,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}, strings.Join(targets, ","), nil
+}
+
+// dockerConfigDir returns the directory of a docker config file.
+// The default path honours the DOCKER_CONFIG env var like the docker cli does.
+func dockerConfigDir(path string) (string, error) {
+	if path == DefaultDockerConfigPath {
+		if dir := os.Getenv(dockerConfigEnvVar); dir != "" {
+			return dir, nil
+		}
+	}
+
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if info.IsDir() {
+		return path, nil
+	} else if filepath.Base(path) != dockerConfigFileName {
+		return "", errors.Errorf("docker config file must be named %s: %s", dockerConfigFileName, path)
+	}
+	return filepath.Dir(path), nil
+}
+
+// readDockerConfigRegistries returns the registry hosts that have credentials or a credential helper in a docker config.
+func readDockerConfigRegistries(configDir string) ([]string, error) {
+	buf, err := ioutil.ReadFile(filepath.Join(configDir, dockerConfigFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var config dockerConfigFile
+	if err := json.Unmarshal(buf, &config); err != nil {
+		return nil, errors.Wrapf(err, "invalid docker config %s", filepath.Join(configDir, dockerConfigFileName))
+	}
+
+	set := map[string]interface{}{}
+	for key := range config.Auths {
+		set[RegistryHost(key)] = nil
+	}
+	for key := range config.CredHelpers {
+		set[RegistryHost(key)] = nil
+	}
+
+	var registries []string
+	for registry := range set {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	return registries, nil
+}
+
+func filterRegistries(registries, filter []string) ([]string, error) {
+	if len(filter) == 0 {
+		if len(registries) == 0 {
+			return nil, errors.New("no registries found in docker config")
+		}
+		return registries, nil
+	}
+
+	var filtered []string
+	for _, f := range filter {
+		host := RegistryHost(f)
+		found := false
+		for _, registry := range registries {
+			if registry == host {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, errors.Errorf("registry %q not found in docker config", f)
+		}
+		filtered = append(filtered, host)
+	}
+	return filtered, nil
+}
+
+// resolveDockerConfigCredentials resolves the credentials of each registry from the docker config in the config dir,
+// running any credential helpers configured for the registry.
+// It resolves credentials the same way as authn.DefaultKeychain, which cannot be used as it only loads the docker config
+// from the DOCKER_CONFIG env var.
+func resolveDockerConfigCredentials(configDir string, registries []string) (DockerCredentials, error) {
+	cf, err := config.Load(configDir)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := DockerCredentials{}
+	for _, registry := range registries {
+		reg, err := name.NewRegistry(registry, name.WeakValidation)
+		if err != nil {
+			return nil, err
+		}
+
+		key := reg.RegistryStr()
+		if key == name.DefaultRegistry {
+			key = authn.DefaultAuthKey
+		}
+
+		authConfig, err := cf.GetAuthConfig(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving credentials for %s", registry)
+		} else if authConfig == (types.AuthConfig{}) {
+			return nil, errors.Errorf("no credentials found for %s in docker config", registry)
+		}
+
+		target := registry
+		if registry == name.DefaultRegistry {
+			target = DockerhubUrl
+		}
+		credentials[target] = authn.AuthConfig{
+			Username:      authConfig.Username,
+			Password:      authConfig.Password,
+			Auth:          authConfig.Auth,
+			IdentityToken: authConfig.IdentityToken,
+			RegistryToken: authConfig.RegistryToken,
+		}
+	}
+	return credentials, nil
+}
+
+// RegistryHost normalizes a docker config key or registry name to the registry host.
+// Docker Hub keys such as "https://index.docker.io/v1/" and "docker.io" become "index.docker.io".
+func RegistryHost(key string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+
+	reg, err := name.NewRegistry(host, name.WeakValidation)
+	if err != nil {
+		return host
+	}
+	return reg.RegistryStr()
+}
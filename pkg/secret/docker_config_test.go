@@ -0,0 +1,156 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func TestDockerConfigSecret(t *testing.T) {
+	spec.Run(t, "TestDockerConfigSecret", testDockerConfigSecret)
+}
+
+func testDockerConfigSecret(t *testing.T, when spec.G, it spec.S) {
+	var (
+		dir        string
+		configPath string
+		factory    *secret.Factory
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "docker-config-test")
+		require.NoError(t, err)
+
+		configPath = filepath.Join(dir, "config.json")
+		require.NoError(t, ioutil.WriteFile(configPath, []byte(`{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "ZG9ja2VyLXVzZXI6ZG9ja2VyLXBhc3N3b3Jk"},
+    "gcr.io": {"username": "_json_key", "password": "some-key"},
+    "my-registry.io:5000": {"username": "my-user", "password": "my-password"}
+  }
+}`), 0600))
+
+		factory = &secret.Factory{DockerConfigPath: configPath}
+	})
+
+	it.After(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	it("creates a registry secret with the credentials of every registry", func() {
+		s, target, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.NoError(t, err)
+
+		require.Equal(t, "gcr.io,https://index.docker.io/v1/,my-registry.io:5000", target)
+		require.Equal(t, corev1.SecretTypeDockerConfigJson, s.Type)
+		require.JSONEq(t, `{"auths":{
+  "https://index.docker.io/v1/": {"username": "docker-user", "password": "docker-password"},
+  "gcr.io": {"username": "_json_key", "password": "some-key"},
+  "my-registry.io:5000": {"username": "my-user", "password": "my-password"}
+}}`, string(s.Data[corev1.DockerConfigJsonKey]))
+	})
+
+	it("only imports the filtered registries", func() {
+		factory.RegistryFilter = []string{"docker.io", "my-registry.io:5000"}
+
+		s, target, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.NoError(t, err)
+
+		require.Equal(t, "https://index.docker.io/v1/,my-registry.io:5000", target)
+		require.JSONEq(t, `{"auths":{
+  "https://index.docker.io/v1/": {"username": "docker-user", "password": "docker-password"},
+  "my-registry.io:5000": {"username": "my-user", "password": "my-password"}
+}}`, string(s.Data[corev1.DockerConfigJsonKey]))
+	})
+
+	it("accepts the directory of the docker config", func() {
+		factory.DockerConfigPath = dir
+		factory.RegistryFilter = []string{"gcr.io"}
+
+		_, target, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.NoError(t, err)
+		require.Equal(t, "gcr.io", target)
+	})
+
+	it("does not change the DOCKER_CONFIG env var", func() {
+		previous, set := os.LookupEnv("DOCKER_CONFIG")
+		require.NoError(t, os.Setenv("DOCKER_CONFIG", "some-other-dir"))
+		defer func() {
+			if set {
+				require.NoError(t, os.Setenv("DOCKER_CONFIG", previous))
+			} else {
+				require.NoError(t, os.Unsetenv("DOCKER_CONFIG"))
+			}
+		}()
+
+		_, target, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.NoError(t, err)
+		require.Equal(t, "gcr.io,https://index.docker.io/v1/,my-registry.io:5000", target)
+		require.Equal(t, "some-other-dir", os.Getenv("DOCKER_CONFIG"))
+	})
+
+	it("resolves credentials with the credential helper of a registry", func() {
+		helper := filepath.Join(dir, "docker-credential-kp-test")
+		require.NoError(t, ioutil.WriteFile(helper, []byte(`#!/bin/sh
+read server
+echo "{\"ServerURL\": \"$server\", \"Username\": \"helper-user\", \"Secret\": \"helper-password\"}"
+`), 0700))
+
+		path := os.Getenv("PATH")
+		require.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+path))
+		defer func() {
+			require.NoError(t, os.Setenv("PATH", path))
+		}()
+
+		require.NoError(t, ioutil.WriteFile(configPath, []byte(`{
+  "auths": {"gcr.io": {"username": "_json_key", "password": "some-key"}},
+  "credHelpers": {"helper-registry.io": "kp-test"}
+}`), 0600))
+
+		s, target, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.NoError(t, err)
+
+		require.Equal(t, "gcr.io,helper-registry.io", target)
+		require.JSONEq(t, `{"auths":{
+  "gcr.io": {"username": "_json_key", "password": "some-key"},
+  "helper-registry.io": {"username": "helper-user", "password": "helper-password"}
+}}`, string(s.Data[corev1.DockerConfigJsonKey]))
+	})
+
+	it("errors when a filtered registry is not in the docker config", func() {
+		factory.RegistryFilter = []string{"other-registry.io"}
+
+		_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.EqualError(t, err, `registry "other-registry.io" not found in docker config`)
+	})
+
+	it("errors when the docker config is not named config.json", func() {
+		otherPath := filepath.Join(dir, "other.json")
+		require.NoError(t, ioutil.WriteFile(otherPath, []byte(`{}`), 0600))
+		factory.DockerConfigPath = otherPath
+
+		_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.EqualError(t, err, fmt.Sprintf("docker config file must be named config.json: %s", otherPath))
+	})
+
+	it("errors when the registry filter is used without a docker config", func() {
+		factory.DockerConfigPath = ""
+		factory.DockerhubId = "some-dockerhub-id"
+		factory.RegistryFilter = []string{"gcr.io"}
+
+		_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.EqualError(t, err, "extraneous parameters: registry-filter")
+	})
+}
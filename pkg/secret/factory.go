@@ -35,99 +35,67 @@ type Factory struct {
 	GitUrl                string
 	GitSshKeyFile         string
 	GitUser               string
+	GitKnownHostsFile     string
+	GitSshScan            bool
+	HostKeyScanner        HostKeyScanner
+	ConfirmationProvider  ConfirmationProvider
+	DockerConfigPath      string
+	RegistryFilter        []string
+	GitToken              bool
+	AcrRegistry           string
+	AcrClientId           string
+	EcrRegistry           string
 }
 
 func (f *Factory) MakeSecret(name, namespace string) (*corev1.Secret, string, error) {
-	if err := f.validate(); err != nil {
-		return nil, "", err
-	}
-
 	kind, err := f.getSecretKind()
 	if err != nil {
 		return nil, "", err
 	}
 
-	switch kind {
-	case dockerHubKind:
-		return f.makeDockerhubSecret(name, namespace)
-	case gcrKind:
-		return f.makeGcrSecret(name, namespace)
-	case registryKind:
-		return f.makeRegistrySecret(name, namespace)
-	case gitSshKind:
-		return f.makeGitSshSecret(name, namespace)
-	case gitBasicAuthKind:
-		return f.makeGitBasicAuthSecret(name, namespace)
-	}
-
-	return nil, "", errors.Errorf("incorrect flags provided")
+	return kind.make(f, name, namespace)
 }
 
-func (f *Factory) validate() error {
+func (f *Factory) params() paramSet {
 	set := paramSet{}
 	set.add("dockerhub", f.DockerhubId)
 	set.add("registry", f.Registry)
+	set.add("registry-user", f.RegistryUser)
 	set.add("gcr", f.GcrServiceAccountFile)
 	set.add("git", f.GitUrl)
-
-	if len(set) != 1 {
-		return errors.Errorf("secret must be one of dockerhub, gcr, registry, or git")
-	}
-
-	set.add("registry-user", f.RegistryUser)
 	set.add("git-user", f.GitUser)
 	set.add("git-ssh-key", f.GitSshKeyFile)
+	set.add("git-known-hosts", f.GitKnownHostsFile)
+	set.addBool("git-ssh-scan", f.GitSshScan)
+	set.addBool("git-token", f.GitToken)
+	set.add("from-docker-config", f.DockerConfigPath)
+	set.add("registry-filter", strings.Join(f.RegistryFilter, ","))
+	set.add("acr", f.AcrRegistry)
+	set.add("acr-client-id", f.AcrClientId)
+	set.add("ecr", f.EcrRegistry)
+	return set
+}
 
-	if set.contains("dockerhub") && len(set) != 1 {
-		return set.getExtraParamsError("dockerhub")
-	}
-
-	if set.contains("gcr") && len(set) != 1 {
-		return set.getExtraParamsError("gcr")
-	}
-
-	if set.contains("registry") {
-		if !set.contains("registry-user") {
-			return errors.Errorf("missing parameter registry-user")
-		} else if len(set) != 2 {
-			return set.getExtraParamsError("registry", "registry-user")
-		}
-	}
-
-	if set.contains("git") {
-		if !set.contains("git-user") && !set.contains("git-ssh-key") {
-			return errors.Errorf("missing parameter git-user or git-ssh-key")
-		} else if set.contains("git-user") && set.contains("git-ssh-key") {
-			return errors.Errorf("must provide one of git-user or git-ssh-key")
-		} else if len(set) != 2 {
-			return set.getExtraParamsError("git", "git-user", "git-ssh-key")
-		}
-	}
-
-	if f.GitUser != "" && !(strings.HasPrefix(f.GitUrl, "http://") || strings.HasPrefix(f.GitUrl, "https://")) {
+func (f *Factory) validateGitBasicAuth() error {
+	if !isHttpUrl(f.GitUrl) {
 		return errors.Errorf("must provide a valid git url for basic auth (ex. https://github.com)")
 	}
+	return nil
+}
 
-	if f.GitSshKeyFile != "" && !strings.HasPrefix(f.GitUrl, "git@") {
-		return errors.Errorf("must provide a valid git url for SSH (ex. git@github.com)")
+func (f *Factory) validateGitSsh() error {
+	if f.GitKnownHostsFile != "" && f.GitSshScan {
+		return errors.Errorf("must provide one of git-known-hosts or git-ssh-scan")
 	}
 
+	if _, ok := parseSshUrl(f.GitUrl); !ok && !strings.HasPrefix(f.GitUrl, "git@") {
+		return errors.Errorf("must provide a valid git url for SSH (ex. git@github.com or ssh://git@github.com:22)")
+	}
 	return nil
 }
 
-func (f *Factory) getSecretKind() (secretKind, error) {
-	if f.DockerhubId != "" {
-		return dockerHubKind, nil
-	} else if f.Registry != "" && f.RegistryUser != "" {
-		return registryKind, nil
-	} else if f.GcrServiceAccountFile != "" {
-		return gcrKind, nil
-	} else if f.GitUrl != "" && f.GitSshKeyFile != "" {
-		return gitSshKind, nil
-	} else if f.GitUrl != "" && f.GitUser != "" {
-		return gitBasicAuthKind, nil
-	}
-	return "", errors.Errorf("received secret with unknown type")
+func isHttpUrl(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
 }
 
 func (f *Factory) makeDockerhubSecret(name, namespace string) (*corev1.Secret, string, error) {
@@ -223,6 +191,21 @@ func (f *Factory) makeGitSshSecret(name string, namespace string) (*corev1.Secre
 		return nil, "", err
 	}
 
+	if err := validateSshPrivateKey(password, f.GitSshKeyFile); err != nil {
+		return nil, "", err
+	}
+
+	data := map[string][]byte{
+		corev1.SSHAuthPrivateKey: password,
+	}
+
+	knownHosts, err := f.knownHosts()
+	if err != nil {
+		return nil, "", err
+	} else if knownHosts != nil {
+		data[KnownHostsKey] = knownHosts
+	}
+
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -231,9 +214,7 @@ func (f *Factory) makeGitSshSecret(name string, namespace string) (*corev1.Secre
 				GitAnnotation: f.GitUrl,
 			},
 		},
-		Data: map[string][]byte{
-			corev1.SSHAuthPrivateKey: []byte(password),
-		},
+		Data: data,
 		Type: corev1.SecretTypeSSHAuth,
 	}, f.GitUrl, nil
 }
@@ -260,16 +241,6 @@ func (f *Factory) makeGitBasicAuthSecret(name string, namespace string) (*corev1
 	}, f.GitUrl, nil
 }
 
-type secretKind string
-
-const (
-	dockerHubKind    secretKind = "dockerhub"
-	gcrKind                     = "gcr"
-	registryKind                = "registry"
-	gitSshKind                  = "git ssh"
-	gitBasicAuthKind            = "git basic auth"
-)
-
 type paramSet map[string]interface{}
 
 func (p paramSet) add(key string, value string) {
@@ -278,11 +249,35 @@ func (p paramSet) add(key string, value string) {
 	}
 }
 
+func (p paramSet) addBool(key string, value bool) {
+	if value {
+		p[key] = nil
+	}
+}
+
 func (p paramSet) contains(key string) bool {
 	_, ok := p[key]
 	return ok
 }
 
+func (p paramSet) containsAll(keys []string) bool {
+	for _, key := range keys {
+		if !p.contains(key) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p paramSet) containsOnly(keys []string) bool {
+	for key := range p {
+		if !contains(keys, key) {
+			return false
+		}
+	}
+	return true
+}
+
 func (p paramSet) getExtraParamsError(keys ...string) error {
 	for _, k := range keys {
 		delete(p, k)
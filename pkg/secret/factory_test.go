@@ -8,6 +8,7 @@ import (
 
 	"github.com/sclevine/spec"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/pivotal/build-service-cli/pkg/secret"
 )
@@ -22,7 +23,7 @@ func testSecretFactory(t *testing.T, when spec.G, it spec.S) {
 	when("no params are set", func() {
 		it("returns an error message", func() {
 			_, _, err := factory.MakeSecret("test-name", "test-namespace")
-			require.EqualError(t, err, "secret must be one of dockerhub, gcr, registry, or git")
+			require.EqualError(t, err, "secret must be one of dockerhub, gcr, registry, git, from-docker-config, acr, or ecr")
 		})
 	})
 
@@ -31,7 +32,7 @@ func testSecretFactory(t *testing.T, when spec.G, it spec.S) {
 			factory.DockerhubId = "some-dockerhub-id"
 			factory.GcrServiceAccountFile = "some-gcr-service-account"
 			_, _, err := factory.MakeSecret("test-name", "test-namespace")
-			require.EqualError(t, err, "secret must be one of dockerhub, gcr, registry, or git")
+			require.EqualError(t, err, "secret must be one of dockerhub, gcr, registry, git, from-docker-config, acr, or ecr")
 		})
 	})
 
@@ -90,7 +91,7 @@ func testSecretFactory(t *testing.T, when spec.G, it spec.S) {
 			it("returns an error message", func() {
 				factory.GitUrl = "some-git"
 				_, _, err := factory.MakeSecret("test-name", "test-namespace")
-				require.EqualError(t, err, "missing parameter git-user or git-ssh-key")
+				require.EqualError(t, err, "missing parameter git-user, git-ssh-key, or git-token")
 			})
 		})
 	})
@@ -115,11 +116,20 @@ func testSecretFactory(t *testing.T, when spec.G, it spec.S) {
 	})
 
 	when("using git ssh keys", func() {
-		it("validates that the git url begins with git@", func() {
+		it("validates that the git url begins with git@ or ssh://", func() {
 			factory.GitUrl = "some-git"
 			factory.GitSshKeyFile = "some-ssh-key"
 			_, _, err := factory.MakeSecret("test-name", "test-namespace")
-			require.EqualError(t, err, "must provide a valid git url for SSH (ex. git@github.com)")
+			require.EqualError(t, err, "must provide a valid git url for SSH (ex. git@github.com or ssh://git@github.com:22)")
+		})
+
+		it("accepts ssh:// git urls", func() {
+			factory.GitUrl = "ssh://git@some-git.io:2222/some-org/some-repo"
+			factory.GitSshKeyFile = "testdata/git-ssh.pem"
+			s, target, err := factory.MakeSecret("test-name", "test-namespace")
+			require.NoError(t, err)
+			require.Equal(t, "ssh://git@some-git.io:2222/some-org/some-repo", target)
+			require.Equal(t, corev1.SecretTypeSSHAuth, s.Type)
 		})
 	})
 }
@@ -0,0 +1,212 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	KnownHostsKey = "known_hosts"
+
+	defaultSshPort = "22"
+)
+
+type HostKeyScanner interface {
+	ScanHostKeys(address string) ([]ssh.PublicKey, error)
+}
+
+type ConfirmationProvider interface {
+	Confirm(message string, okayResponses ...string) (bool, error)
+}
+
+// validateSshPrivateKey checks that a private key can be used without a passphrase.
+func validateSshPrivateKey(key []byte, path string) error {
+	_, err := ssh.ParsePrivateKey(key)
+	if _, ok := err.(*ssh.PassphraseMissingError); ok {
+		return errors.Errorf("git ssh key %s is passphrase protected, which is not supported", path)
+	} else if err != nil {
+		return errors.Wrapf(err, "invalid git ssh key %s", path)
+	}
+	return nil
+}
+
+// knownHosts returns the known_hosts entries for the git host, read from a file or scanned from the host.
+// It returns nil when neither is requested.
+func (f *Factory) knownHosts() ([]byte, error) {
+	if f.GitKnownHostsFile != "" {
+		return readKnownHosts(f.GitKnownHostsFile)
+	}
+
+	if f.GitSshScan {
+		return f.scanKnownHosts()
+	}
+
+	return nil, nil
+}
+
+func readKnownHosts(path string) ([]byte, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := buf
+	found := false
+	for len(rest) > 0 {
+		var err error
+		_, _, _, _, rest, err = ssh.ParseKnownHosts(rest)
+		if err != nil {
+			break
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, errors.Errorf("no host keys found in known hosts file %s", path)
+	}
+	return buf, nil
+}
+
+func (f *Factory) scanKnownHosts() ([]byte, error) {
+	address := sshAddress(f.GitUrl)
+
+	keys, err := f.HostKeyScanner.ScanHostKeys(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "scanning host keys of %s", address)
+	} else if len(keys) == 0 {
+		return nil, errors.Errorf("no host keys found for %s", address)
+	}
+
+	message := &strings.Builder{}
+	_, _ = fmt.Fprintf(message, "Host keys of %s:\n", address)
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(message, "  %s %s\n", key.Type(), ssh.FingerprintSHA256(key))
+	}
+	_, _ = fmt.Fprint(message, "Trust these host keys? (y/n): ")
+
+	confirmed, err := f.ConfirmationProvider.Confirm(message.String())
+	if err != nil {
+		return nil, err
+	} else if !confirmed {
+		return nil, errors.Errorf("host keys of %s were not trusted", address)
+	}
+
+	buf := &bytes.Buffer{}
+	for _, key := range keys {
+		buf.WriteString(knownhosts.Line([]string{knownhosts.Normalize(address)}, key))
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// sshAddress returns the host and port of a git ssh url such as git@github.com, git@example.com:2222
+// or ssh://git@example.com:2222/some-org/some-repo.
+func sshAddress(gitUrl string) string {
+	if u, ok := parseSshUrl(gitUrl); ok {
+		port := u.Port()
+		if port == "" {
+			port = defaultSshPort
+		}
+		return net.JoinHostPort(u.Hostname(), port)
+	}
+
+	host := gitUrl[strings.Index(gitUrl, "@")+1:]
+	port := defaultSshPort
+	if i := strings.IndexAny(host, ":/"); i >= 0 {
+		if rest := host[i+1:]; host[i] == ':' && isPort(rest) {
+			port = rest
+		}
+		host = host[:i]
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// parseSshUrl parses git urls with the ssh:// scheme. Scp-like urls such as git@github.com:some-org/some-repo are not parsed.
+func parseSshUrl(gitUrl string) (*url.URL, bool) {
+	if !strings.HasPrefix(gitUrl, "ssh://") {
+		return nil, false
+	}
+
+	u, err := url.Parse(gitUrl)
+	if err != nil || u.Hostname() == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+func isPort(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// SshHostKeyScanner fetches the host keys of an ssh server by starting a handshake for each supported host key algorithm.
+type SshHostKeyScanner struct {
+	Timeout time.Duration
+}
+
+var scanHostKeyAlgorithms = []string{
+	ssh.KeyAlgoED25519,
+	ssh.KeyAlgoECDSA256,
+	ssh.KeyAlgoECDSA384,
+	ssh.KeyAlgoECDSA521,
+	ssh.KeyAlgoRSA,
+}
+
+var errHostKeyScanned = errors.New("host key scanned")
+
+func (s SshHostKeyScanner) ScanHostKeys(address string) ([]ssh.PublicKey, error) {
+	var (
+		keys    []ssh.PublicKey
+		lastErr error
+	)
+
+	for _, algorithm := range scanHostKeyAlgorithms {
+		var key ssh.PublicKey
+		config := &ssh.ClientConfig{
+			User:              "git",
+			HostKeyAlgorithms: []string{algorithm},
+			HostKeyCallback: func(_ string, _ net.Addr, k ssh.PublicKey) error {
+				key = k
+				return errHostKeyScanned
+			},
+			Timeout: s.Timeout,
+		}
+
+		conn, err := net.DialTimeout("tcp", address, s.Timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		_, _, _, err = ssh.NewClientConn(conn, address, config)
+		_ = conn.Close()
+		if key != nil {
+			keys = append(keys, key)
+		} else if err != nil {
+			lastErr = err
+		}
+	}
+
+	if len(keys) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return keys, nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func TestGitSshSecret(t *testing.T) {
+	spec.Run(t, "TestGitSshSecret", testGitSshSecret)
+}
+
+func testGitSshSecret(t *testing.T, when spec.G, it spec.S) {
+	var (
+		factory  *secret.Factory
+		scanner  *fakeHostKeyScanner
+		confirm  *fakeConfirmationProvider
+		hostKey  ssh.PublicKey
+		keyBytes []byte
+	)
+
+	it.Before(func() {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		signer, err := ssh.NewSignerFromKey(privateKey)
+		require.NoError(t, err)
+		hostKey = signer.PublicKey()
+
+		keyBytes, err = ioutil.ReadFile("testdata/git-ssh.pem")
+		require.NoError(t, err)
+
+		scanner = &fakeHostKeyScanner{keys: []ssh.PublicKey{hostKey}}
+		confirm = &fakeConfirmationProvider{confirm: true}
+		factory = &secret.Factory{
+			GitUrl:               "git@github.com",
+			GitSshKeyFile:        "testdata/git-ssh.pem",
+			HostKeyScanner:       scanner,
+			ConfirmationProvider: confirm,
+		}
+	})
+
+	it("validates that the ssh key can be parsed", func() {
+		factory.GitSshKeyFile = "testdata/known_hosts"
+
+		_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.EqualError(t, err, "invalid git ssh key testdata/known_hosts: ssh: no key found")
+	})
+
+	it("rejects passphrase protected ssh keys", func() {
+		factory.GitSshKeyFile = "testdata/git-ssh-passphrase.pem"
+
+		_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.EqualError(t, err, "git ssh key testdata/git-ssh-passphrase.pem is passphrase protected, which is not supported")
+	})
+
+	it("adds the known hosts file to the secret", func() {
+		factory.GitKnownHostsFile = "testdata/known_hosts"
+
+		s, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.NoError(t, err)
+
+		knownHosts, err := ioutil.ReadFile("testdata/known_hosts")
+		require.NoError(t, err)
+		require.Equal(t, map[string][]byte{
+			corev1.SSHAuthPrivateKey: keyBytes,
+			secret.KnownHostsKey:     knownHosts,
+		}, s.Data)
+	})
+
+	it("errors when the known hosts file has no host keys", func() {
+		factory.GitKnownHostsFile = "testdata/git-ssh.pem"
+
+		_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.EqualError(t, err, "no host keys found in known hosts file testdata/git-ssh.pem")
+	})
+
+	it("adds the scanned host keys after they are confirmed", func() {
+		factory.GitSshScan = true
+
+		s, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.NoError(t, err)
+
+		require.Equal(t, "github.com:22", scanner.address)
+		require.Equal(t, "Host keys of github.com:22:\n  ssh-ed25519 "+ssh.FingerprintSHA256(hostKey)+"\nTrust these host keys? (y/n): ", confirm.message)
+		require.Equal(t, "github.com "+string(ssh.MarshalAuthorizedKey(hostKey)), string(s.Data[secret.KnownHostsKey]))
+	})
+
+	it("errors when the scanned host keys are not confirmed", func() {
+		factory.GitSshScan = true
+		confirm.confirm = false
+
+		_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.EqualError(t, err, "host keys of github.com:22 were not trusted")
+	})
+
+	it("errors when both known hosts and scan are provided", func() {
+		factory.GitKnownHostsFile = "testdata/known_hosts"
+		factory.GitSshScan = true
+
+		_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.EqualError(t, err, "must provide one of git-known-hosts or git-ssh-scan")
+	})
+
+	it("errors when known hosts are provided for basic auth", func() {
+		factory.GitUrl = "https://github.com"
+		factory.GitSshKeyFile = ""
+		factory.GitUser = "some-user"
+		factory.GitKnownHostsFile = "testdata/known_hosts"
+
+		_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+		require.EqualError(t, err, "extraneous parameters: git-known-hosts")
+	})
+
+	when("scanning a local ssh server", func() {
+		it("returns the host key of the server", func() {
+			_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+			require.NoError(t, err)
+
+			signer, err := ssh.NewSignerFromKey(privateKey)
+			require.NoError(t, err)
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer listener.Close()
+
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+
+					config := &ssh.ServerConfig{NoClientAuth: true}
+					config.AddHostKey(signer)
+					go func() {
+						_, _, _, _ = ssh.NewServerConn(conn, config)
+						_ = conn.Close()
+					}()
+				}
+			}()
+
+			keys, err := secret.SshHostKeyScanner{Timeout: 5 * time.Second}.ScanHostKeys(listener.Addr().String())
+			require.NoError(t, err)
+			require.Len(t, keys, 1)
+			require.Equal(t, signer.PublicKey().Marshal(), keys[0].Marshal())
+		})
+	})
+}
+
+type fakeHostKeyScanner struct {
+	keys    []ssh.PublicKey
+	address string
+}
+
+func (f *fakeHostKeyScanner) ScanHostKeys(address string) ([]ssh.PublicKey, error) {
+	f.address = address
+	return f.keys, nil
+}
+
+type fakeConfirmationProvider struct {
+	confirm bool
+	message string
+}
+
+func (f *fakeConfirmationProvider) Confirm(message string, _ ...string) (bool, error) {
+	f.message = message
+	return f.confirm, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// gitTokenUsers are the usernames git hosts expect with access tokens over https.
+var gitTokenUsers = map[string]string{
+	"github.com": "x-access-token",
+	"gitlab.com": "oauth2",
+}
+
+func (f *Factory) validateGitToken() error {
+	if !isHttpUrl(f.GitUrl) {
+		return errors.Errorf("must provide a valid git url for token auth (ex. https://github.com)")
+	}
+
+	if _, err := gitTokenUser(f.GitUrl); err != nil {
+		return err
+	}
+	return nil
+}
+
+func gitTokenUser(gitUrl string) (string, error) {
+	u, err := url.Parse(gitUrl)
+	if err != nil {
+		return "", err
+	}
+
+	user, ok := gitTokenUsers[u.Hostname()]
+	if !ok {
+		return "", errors.Errorf("git token username for %s is unknown, use git-user with the token as the password instead", u.Hostname())
+	}
+	return user, nil
+}
+
+func (f *Factory) makeGitTokenSecret(name string, namespace string) (*corev1.Secret, string, error) {
+	user, err := gitTokenUser(f.GitUrl)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := f.CredentialFetcher.FetchPassword("GIT_TOKEN", "git token: ")
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				GitAnnotation: f.GitUrl,
+			},
+		},
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte(user),
+			corev1.BasicAuthPasswordKey: []byte(token),
+		},
+		Type: corev1.SecretTypeBasicAuth,
+	}, f.GitUrl, nil
+}
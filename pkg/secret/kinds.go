@@ -0,0 +1,188 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// secretKind describes a kind of secret the factory can make.
+// A kind is selected by its param, and kinds sharing a param are told apart by their identifying params.
+// The name is the Type of the secrets the kind makes, and target returns what they are for when it is known from the params alone.
+type secretKind struct {
+	secretType  corev1.SecretType
+	name        string
+	target      func(f *Factory) string
+	param       string
+	identifying []string
+	optional    []string
+	validate    func(f *Factory) error
+	make        func(f *Factory, name, namespace string) (*corev1.Secret, string, error)
+}
+
+// secretKinds are the kinds of secret the factory can make. New kinds are added here.
+var secretKinds = []secretKind{
+	{
+		secretType: corev1.SecretTypeDockerConfigJson,
+		name:       DockerhubType,
+		target:     func(*Factory) string { return DockerhubUrl },
+		param:      "dockerhub",
+		make:       (*Factory).makeDockerhubSecret,
+	},
+	{
+		secretType: corev1.SecretTypeDockerConfigJson,
+		name:       GcrType,
+		target:     func(*Factory) string { return GcrUrl },
+		param:      "gcr",
+		make:       (*Factory).makeGcrSecret,
+	},
+	{
+		secretType:  corev1.SecretTypeDockerConfigJson,
+		name:        RegistryType,
+		target:      func(f *Factory) string { return f.Registry },
+		param:       "registry",
+		identifying: []string{"registry-user"},
+		make:        (*Factory).makeRegistrySecret,
+	},
+	{
+		secretType:  corev1.SecretTypeBasicAuth,
+		name:        GitBasicType,
+		target:      gitTarget,
+		param:       "git",
+		identifying: []string{"git-user"},
+		validate:    (*Factory).validateGitBasicAuth,
+		make:        (*Factory).makeGitBasicAuthSecret,
+	},
+	{
+		secretType:  corev1.SecretTypeSSHAuth,
+		name:        GitSshType,
+		target:      gitTarget,
+		param:       "git",
+		identifying: []string{"git-ssh-key"},
+		optional:    []string{"git-known-hosts", "git-ssh-scan"},
+		validate:    (*Factory).validateGitSsh,
+		make:        (*Factory).makeGitSshSecret,
+	},
+	{
+		secretType:  corev1.SecretTypeBasicAuth,
+		name:        GitBasicType,
+		target:      gitTarget,
+		param:       "git",
+		identifying: []string{"git-token"},
+		validate:    (*Factory).validateGitToken,
+		make:        (*Factory).makeGitTokenSecret,
+	},
+	{
+		secretType: corev1.SecretTypeDockerConfigJson,
+		param:      "from-docker-config",
+		optional:   []string{"registry-filter"},
+		make:       (*Factory).makeDockerConfigSecret,
+	},
+	{
+		secretType:  corev1.SecretTypeDockerConfigJson,
+		name:        AcrType,
+		target:      func(f *Factory) string { return f.AcrRegistry },
+		param:       "acr",
+		identifying: []string{"acr-client-id"},
+		validate:    (*Factory).validateAcr,
+		make:        (*Factory).makeAcrSecret,
+	},
+	{
+		secretType: corev1.SecretTypeDockerConfigJson,
+		name:       EcrType,
+		target:     func(f *Factory) string { return f.EcrRegistry },
+		param:      "ecr",
+		validate:   (*Factory).validateEcr,
+		make:       (*Factory).makeEcrSecret,
+	},
+}
+
+// getSecretKind validates the factory parameters and returns the kind of secret they describe.
+func (f *Factory) getSecretKind() (secretKind, error) {
+	set := f.params()
+
+	var params, selected []string
+	for _, kind := range secretKinds {
+		if contains(params, kind.param) {
+			continue
+		}
+		params = append(params, kind.param)
+
+		if set.contains(kind.param) {
+			selected = append(selected, kind.param)
+		}
+	}
+
+	if len(selected) != 1 {
+		return secretKind{}, errors.Errorf("secret must be one of %s", joinOr(params))
+	}
+
+	var candidates, matching []secretKind
+	for _, kind := range secretKinds {
+		if kind.param != selected[0] {
+			continue
+		}
+		candidates = append(candidates, kind)
+
+		if set.containsAll(kind.identifying) {
+			matching = append(matching, kind)
+		}
+	}
+
+	if len(matching) == 0 {
+		return secretKind{}, errors.Errorf("missing parameter %s", joinOr(identifyingParams(candidates)))
+	} else if len(matching) > 1 {
+		return secretKind{}, errors.Errorf("must provide one of %s", joinOr(identifyingParams(matching)))
+	}
+
+	kind := matching[0]
+	allowed := append(append([]string{kind.param}, kind.identifying...), kind.optional...)
+	if !set.containsOnly(allowed) {
+		return secretKind{}, set.getExtraParamsError(allowed...)
+	}
+
+	if kind.validate != nil {
+		if err := kind.validate(f); err != nil {
+			return secretKind{}, err
+		}
+	}
+
+	return kind, nil
+}
+
+func gitTarget(f *Factory) string {
+	return f.GitUrl
+}
+
+func identifyingParams(kinds []secretKind) []string {
+	var params []string
+	for _, kind := range kinds {
+		params = append(params, kind.identifying...)
+	}
+	return params
+}
+
+// joinOr joins values as "a", "a or b", or "a, b, or c".
+func joinOr(values []string) string {
+	switch len(values) {
+	case 1:
+		return values[0]
+	case 2:
+		return values[0] + " or " + values[1]
+	default:
+		return strings.Join(values[:len(values)-1], ", ") + ", or " + values[len(values)-1]
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
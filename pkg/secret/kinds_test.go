@@ -0,0 +1,144 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func TestSecretKinds(t *testing.T) {
+	spec.Run(t, "TestSecretKinds", testSecretKinds)
+}
+
+func testSecretKinds(t *testing.T, when spec.G, it spec.S) {
+	var factory *secret.Factory
+
+	it.Before(func() {
+		factory = &secret.Factory{CredentialFetcher: &fakeCredentialFetcher{password: "some-password"}}
+	})
+
+	when("using git access tokens", func() {
+		it("uses the username expected by github", func() {
+			factory.GitUrl = "https://github.com"
+			factory.GitToken = true
+
+			s, target, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.NoError(t, err)
+			require.Equal(t, "https://github.com", target)
+			require.Equal(t, corev1.SecretTypeBasicAuth, s.Type)
+			require.Equal(t, "https://github.com", s.Annotations[secret.GitAnnotation])
+			require.Equal(t, "x-access-token", string(s.Data[corev1.BasicAuthUsernameKey]))
+			require.Equal(t, "some-password", string(s.Data[corev1.BasicAuthPasswordKey]))
+		})
+
+		it("uses the username expected by gitlab", func() {
+			factory.GitUrl = "https://gitlab.com"
+			factory.GitToken = true
+
+			s, _, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.NoError(t, err)
+			require.Equal(t, "oauth2", string(s.Data[corev1.BasicAuthUsernameKey]))
+		})
+
+		it("errors for git hosts with an unknown username convention", func() {
+			factory.GitUrl = "https://git.example.com"
+			factory.GitToken = true
+
+			_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.EqualError(t, err, "git token username for git.example.com is unknown, use git-user with the token as the password instead")
+		})
+
+		it("validates that the git url begins with http:// or https://", func() {
+			factory.GitUrl = "git@github.com"
+			factory.GitToken = true
+
+			_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.EqualError(t, err, "must provide a valid git url for token auth (ex. https://github.com)")
+		})
+
+		it("errors when a git user is also provided", func() {
+			factory.GitUrl = "https://github.com"
+			factory.GitToken = true
+			factory.GitUser = "some-user"
+
+			_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.EqualError(t, err, "must provide one of git-user or git-token")
+		})
+	})
+
+	when("using azure container registry service principals", func() {
+		const clientId = "01234567-89ab-cdef-0123-456789abcdef"
+
+		it("uses the client id and secret of the service principal", func() {
+			factory.AcrRegistry = "myregistry.azurecr.io"
+			factory.AcrClientId = clientId
+
+			s, target, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.NoError(t, err)
+			require.Equal(t, "myregistry.azurecr.io", target)
+			require.Equal(t, corev1.SecretTypeDockerConfigJson, s.Type)
+			require.JSONEq(t, `{"auths":{"myregistry.azurecr.io":{"username":"01234567-89ab-cdef-0123-456789abcdef","password":"some-password"}}}`, string(s.Data[corev1.DockerConfigJsonKey]))
+			require.Equal(t, secret.AcrType, secret.Type(s))
+		})
+
+		it("errors without a client id", func() {
+			factory.AcrRegistry = "myregistry.azurecr.io"
+
+			_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.EqualError(t, err, "missing parameter acr-client-id")
+		})
+
+		it("validates the registry", func() {
+			factory.AcrRegistry = "my-registry.io"
+			factory.AcrClientId = clientId
+
+			_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.EqualError(t, err, "must provide a valid azure container registry (ex. myregistry.azurecr.io)")
+		})
+
+		it("validates the client id", func() {
+			factory.AcrRegistry = "myregistry.azurecr.io"
+			factory.AcrClientId = "some-user"
+
+			_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.EqualError(t, err, "must provide the application id of the service principal as the acr client id")
+		})
+	})
+
+	when("using amazon elastic container registry tokens", func() {
+		const registry = "123456789012.dkr.ecr.us-east-1.amazonaws.com"
+
+		it("uses the token with the AWS username", func() {
+			factory.EcrRegistry = registry
+
+			s, target, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.NoError(t, err)
+			require.Equal(t, registry, target)
+			require.Equal(t, corev1.SecretTypeDockerConfigJson, s.Type)
+			require.JSONEq(t, `{"auths":{"123456789012.dkr.ecr.us-east-1.amazonaws.com":{"username":"AWS","password":"some-password"}}}`, string(s.Data[corev1.DockerConfigJsonKey]))
+			require.Equal(t, secret.EcrType, secret.Type(s))
+		})
+
+		it("validates the registry", func() {
+			factory.EcrRegistry = "some-registry.io"
+
+			_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.EqualError(t, err, "must provide a valid ecr registry (ex. 123456789012.dkr.ecr.us-east-1.amazonaws.com)")
+		})
+
+		it("errors with extraneous parameters", func() {
+			factory.EcrRegistry = registry
+			factory.RegistryUser = "some-user"
+
+			_, _, err := factory.MakeSecret("some-secret", "some-namespace")
+			require.EqualError(t, err, "extraneous parameters: registry-user")
+		})
+	})
+}
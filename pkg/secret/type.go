@@ -0,0 +1,82 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	DockerhubType = "dockerhub"
+	GcrType       = "gcr"
+	AcrType       = "acr"
+	EcrType       = "ecr"
+	RegistryType  = "registry"
+	GitSshType    = "git-ssh"
+	GitBasicType  = "git-basic"
+)
+
+// Type returns the kind of credentials a secret holds, as it would have been created by kp.
+// Secrets of other types are described by their kubernetes secret type.
+func Type(s *corev1.Secret) string {
+	switch s.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		return registryType(s)
+	case corev1.SecretTypeSSHAuth:
+		return GitSshType
+	case corev1.SecretTypeBasicAuth:
+		return GitBasicType
+	default:
+		return string(s.Type)
+	}
+}
+
+func registryType(s *corev1.Secret) string {
+	var config DockerConfigJson
+	if err := json.Unmarshal(s.Data[corev1.DockerConfigJsonKey], &config); err != nil || len(config.Auths) != 1 {
+		return RegistryType
+	}
+
+	if _, ok := config.Auths[DockerhubUrl]; ok {
+		return DockerhubType
+	}
+
+	if auth, ok := config.Auths[GcrUrl]; ok && auth.Username == GcrUser {
+		return GcrType
+	}
+
+	for registry, auth := range config.Auths {
+		if acrRegistryPattern.MatchString(registry) {
+			return AcrType
+		} else if ecrRegistryPattern.MatchString(registry) && auth.Username == EcrUser {
+			return EcrType
+		}
+	}
+
+	return RegistryType
+}
+
+// Target returns what the credentials of a secret are for: the git url of git secrets,
+// or the comma separated registries of registry secrets.
+func Target(s *corev1.Secret) string {
+	if gitUrl, ok := s.Annotations[GitAnnotation]; ok {
+		return gitUrl
+	}
+
+	var config DockerConfigJson
+	if err := json.Unmarshal(s.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+		return ""
+	}
+
+	var registries []string
+	for registry := range config.Auths {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	return strings.Join(registries, ",")
+}
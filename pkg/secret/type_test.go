@@ -0,0 +1,43 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func TestType(t *testing.T) {
+	spec.Run(t, "TestType", testType)
+}
+
+func testType(t *testing.T, when spec.G, it spec.S) {
+	registrySecret := func(dockerConfigJson string) *corev1.Secret {
+		return &corev1.Secret{
+			Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(dockerConfigJson)},
+			Type: corev1.SecretTypeDockerConfigJson,
+		}
+	}
+
+	it("describes registry secrets by the registry they authenticate with", func() {
+		require.Equal(t, "dockerhub", secret.Type(registrySecret(`{"auths":{"https://index.docker.io/v1/":{"username":"some-user"}}}`)))
+		require.Equal(t, "gcr", secret.Type(registrySecret(`{"auths":{"gcr.io":{"username":"_json_key"}}}`)))
+		require.Equal(t, "registry", secret.Type(registrySecret(`{"auths":{"gcr.io":{"username":"some-user"}}}`)))
+		require.Equal(t, "registry", secret.Type(registrySecret(`{"auths":{"https://index.docker.io/v1/":{},"some-registry.io":{}}}`)))
+	})
+
+	it("describes git secrets by their authentication method", func() {
+		require.Equal(t, "git-ssh", secret.Type(&corev1.Secret{Type: corev1.SecretTypeSSHAuth}))
+		require.Equal(t, "git-basic", secret.Type(&corev1.Secret{Type: corev1.SecretTypeBasicAuth}))
+	})
+
+	it("uses the kubernetes type of other secrets", func() {
+		require.Equal(t, "Opaque", secret.Type(&corev1.Secret{Type: corev1.SecretTypeOpaque}))
+	})
+}
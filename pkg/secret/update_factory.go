@@ -0,0 +1,71 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// MakeUpdate regenerates the credentials of an existing secret from the factory parameters.
+// The returned secret keeps the metadata of the existing secret, and neither its type, its kind of credentials
+// nor what the credentials are for can change.
+// These are checked before any password is fetched, except for the registries of docker config secrets.
+// The known hosts of git ssh secrets are kept unless new known hosts are provided.
+func (f *Factory) MakeUpdate(existing *corev1.Secret) (*corev1.Secret, string, error) {
+	kind, err := f.getSecretKind()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if kind.secretType != existing.Type {
+		return nil, "", errors.Errorf("cannot change the type of secret %q from %q to %q", existing.Name, existing.Type, kind.secretType)
+	}
+
+	if existingKind := Type(existing); kind.name != "" && kind.name != existingKind {
+		return nil, "", errors.Errorf("cannot change the kind of secret %q from %q to %q", existing.Name, existingKind, kind.name)
+	}
+
+	existingTarget := Target(existing)
+	if kind.target != nil {
+		if err := checkTarget(existing.Name, existingTarget, kind.target(f)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	generated, target, err := kind.make(f, existing.Name, existing.Namespace)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := checkTarget(existing.Name, existingTarget, target); err != nil {
+		return nil, "", err
+	}
+
+	updated := existing.DeepCopy()
+	updated.Data = generated.Data
+	updated.StringData = nil
+
+	if knownHosts, ok := existing.Data[KnownHostsKey]; ok && existing.Type == corev1.SecretTypeSSHAuth {
+		if _, generated := updated.Data[KnownHostsKey]; !generated {
+			updated.Data[KnownHostsKey] = knownHosts
+		}
+	}
+
+	for k, v := range generated.Annotations {
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[k] = v
+	}
+
+	return updated, target, nil
+}
+
+func checkTarget(name, existing, target string) error {
+	if existing != target {
+		return errors.Errorf("cannot change the target of secret %q from %q to %q", name, existing, target)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func TestUpdateFactory(t *testing.T) {
+	spec.Run(t, "TestUpdateFactory", testUpdateFactory)
+}
+
+func testUpdateFactory(t *testing.T, when spec.G, it spec.S) {
+	fetcher := &fakeCredentialFetcher{password: "new-password"}
+
+	existing := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "some-secret",
+			Namespace: "some-namespace",
+			Labels:    map[string]string{"some": "label"},
+			Annotations: map[string]string{
+				secret.GitAnnotation: "https://old-git.io",
+				"some":               "annotation",
+			},
+		},
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("old-user"),
+			corev1.BasicAuthPasswordKey: []byte("old-password"),
+		},
+		Type: corev1.SecretTypeBasicAuth,
+	}
+
+	it("replaces the credentials and keeps the metadata of the existing secret", func() {
+		factory := &secret.Factory{
+			CredentialFetcher: fetcher,
+			GitUrl:            "https://old-git.io",
+			GitUser:           "new-user",
+		}
+
+		updated, target, err := factory.MakeUpdate(existing)
+		require.NoError(t, err)
+		require.Equal(t, "https://old-git.io", target)
+		require.Equal(t, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-secret",
+				Namespace: "some-namespace",
+				Labels:    map[string]string{"some": "label"},
+				Annotations: map[string]string{
+					secret.GitAnnotation: "https://old-git.io",
+					"some":               "annotation",
+				},
+			},
+			Data: map[string][]byte{
+				corev1.BasicAuthUsernameKey: []byte("new-user"),
+				corev1.BasicAuthPasswordKey: []byte("new-password"),
+			},
+			Type: corev1.SecretTypeBasicAuth,
+		}, updated)
+		require.Equal(t, "old-password", string(existing.Data[corev1.BasicAuthPasswordKey]))
+	})
+
+	when("updating git ssh secrets", func() {
+		sshSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "some-ssh-secret",
+				Namespace:   "some-namespace",
+				Annotations: map[string]string{secret.GitAnnotation: "git@github.com"},
+			},
+			Data: map[string][]byte{
+				corev1.SSHAuthPrivateKey: []byte("old-key"),
+				secret.KnownHostsKey:     []byte("github.com ssh-ed25519 old-host-key\n"),
+			},
+			Type: corev1.SecretTypeSSHAuth,
+		}
+
+		it("keeps the existing known hosts when no known hosts are provided", func() {
+			factory := &secret.Factory{
+				GitUrl:        "git@github.com",
+				GitSshKeyFile: "testdata/git-ssh.pem",
+			}
+
+			updated, _, err := factory.MakeUpdate(sshSecret)
+			require.NoError(t, err)
+			require.NotEqual(t, "old-key", string(updated.Data[corev1.SSHAuthPrivateKey]))
+			require.Equal(t, "github.com ssh-ed25519 old-host-key\n", string(updated.Data[secret.KnownHostsKey]))
+		})
+
+		it("replaces the existing known hosts when known hosts are provided", func() {
+			factory := &secret.Factory{
+				GitUrl:            "git@github.com",
+				GitSshKeyFile:     "testdata/git-ssh.pem",
+				GitKnownHostsFile: "testdata/known_hosts",
+			}
+
+			updated, _, err := factory.MakeUpdate(sshSecret)
+			require.NoError(t, err)
+			require.NotEqual(t, "github.com ssh-ed25519 old-host-key\n", string(updated.Data[secret.KnownHostsKey]))
+			require.NotEmpty(t, updated.Data[secret.KnownHostsKey])
+		})
+	})
+
+	it("errors when the secret type would change", func() {
+		factory := &secret.Factory{
+			CredentialFetcher: fetcher,
+			DockerhubId:       "some-dockerhub-id",
+		}
+
+		_, _, err := factory.MakeUpdate(existing)
+		require.EqualError(t, err, `cannot change the type of secret "some-secret" from "kubernetes.io/basic-auth" to "kubernetes.io/dockerconfigjson"`)
+	})
+
+	it("errors when the kind of credentials would change", func() {
+		registrySecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "some-registry-secret",
+				Namespace: "some-namespace",
+			},
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"auths":{"some-registry.io":{"username":"some-user","password":"old-password"}}}`),
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+		}
+
+		factory := &secret.Factory{
+			CredentialFetcher: fetcher,
+			DockerhubId:       "some-dockerhub-id",
+		}
+
+		_, _, err := factory.MakeUpdate(registrySecret)
+		require.EqualError(t, err, `cannot change the kind of secret "some-registry-secret" from "registry" to "dockerhub"`)
+	})
+
+	it("errors when the target of the credentials would change before fetching the password", func() {
+		factory := &secret.Factory{
+			CredentialFetcher: failingCredentialFetcher{},
+			GitUrl:            "https://new-git.io",
+			GitUser:           "new-user",
+		}
+
+		_, _, err := factory.MakeUpdate(existing)
+		require.EqualError(t, err, `cannot change the target of secret "some-secret" from "https://old-git.io" to "https://new-git.io"`)
+	})
+}
+
+type fakeCredentialFetcher struct {
+	password string
+}
+
+func (f *fakeCredentialFetcher) FetchPassword(string, string) (string, error) {
+	return f.password, nil
+}
+
+type failingCredentialFetcher struct{}
+
+func (failingCredentialFetcher) FetchPassword(string, string) (string, error) {
+	return "", errors.New("password should not be fetched")
+}
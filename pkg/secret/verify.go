@@ -0,0 +1,210 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type VerifyResult struct {
+	Target string
+	Err    error
+}
+
+// Verifier checks that the credentials of a secret are accepted by their targets.
+type Verifier struct {
+	Transport  http.RoundTripper
+	SshTimeout time.Duration
+	// GitRepo is the repository probed for git basic auth credentials. It is required for git basic auth secrets
+	// because their git url is usually a host, which has no refs to fetch.
+	GitRepo string
+}
+
+func (v Verifier) Verify(s *corev1.Secret) ([]VerifyResult, error) {
+	switch s.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		return v.verifyRegistries(s)
+	case corev1.SecretTypeBasicAuth, corev1.SecretTypeSSHAuth:
+		gitUrl, ok := s.Annotations[GitAnnotation]
+		if !ok {
+			return nil, errors.Errorf("secret %q is missing the %s annotation", s.Name, GitAnnotation)
+		}
+
+		if s.Type == corev1.SecretTypeBasicAuth {
+			if v.GitRepo == "" {
+				return nil, errors.Errorf("a git repository is required to verify the git basic auth credentials of secret %q", s.Name)
+			}
+			if err := checkGitRepoHost(v.GitRepo, gitUrl); err != nil {
+				return nil, errors.Wrapf(err, "cannot verify the git basic auth credentials of secret %q", s.Name)
+			}
+			return []VerifyResult{{Target: gitUrl, Err: v.verifyGitBasicAuth(s)}}, nil
+		}
+		return []VerifyResult{{Target: gitUrl, Err: v.verifyGitSsh(gitUrl, s)}}, nil
+	default:
+		return nil, errors.Errorf("cannot verify secret %q of type %q", s.Name, s.Type)
+	}
+}
+
+func (v Verifier) verifyRegistries(s *corev1.Secret) ([]VerifyResult, error) {
+	var config DockerConfigJson
+	if err := json.Unmarshal(s.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+		return nil, errors.Wrapf(err, "invalid docker config in secret %q", s.Name)
+	}
+
+	var registries []string
+	for registry := range config.Auths {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+
+	var results []VerifyResult
+	for _, registry := range registries {
+		results = append(results, VerifyResult{Target: registry, Err: v.verifyRegistry(registry, config.Auths[registry])})
+	}
+	return results, nil
+}
+
+// verifyRegistry authenticates against the /v2/ endpoint of a registry, exchanging the credentials for a token
+// when the registry requires bearer authentication.
+func (v Verifier) verifyRegistry(registry string, cfg authn.AuthConfig) error {
+	reg, err := name.NewRegistry(RegistryHost(registry), name.WeakValidation)
+	if err != nil {
+		return err
+	}
+
+	rt, err := transport.New(reg, authn.FromConfig(cfg), v.Transport, []string{})
+	if err != nil {
+		return err
+	}
+
+	resp, err := (&http.Client{Transport: rt}).Get(fmt.Sprintf("%s://%s/v2/", reg.Scheme(), reg.RegistryStr()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkStatus(resp)
+}
+
+// checkGitRepoHost checks that a git repository has the scheme and host of the git url of a secret,
+// so that its credentials are only sent to the server they are for.
+func checkGitRepoHost(gitRepo, gitUrl string) error {
+	repo, err := url.Parse(gitRepo)
+	if err != nil {
+		return errors.Wrapf(err, "invalid git repository %q", gitRepo)
+	}
+
+	target, err := url.Parse(gitUrl)
+	if err != nil {
+		return errors.Wrapf(err, "invalid git url %q", gitUrl)
+	}
+
+	if !strings.EqualFold(repo.Scheme, target.Scheme) || !strings.EqualFold(repo.Host, target.Host) {
+		return errors.Errorf("git repository %q is not on %q", gitRepo, gitUrl)
+	}
+	return nil
+}
+
+// verifyGitBasicAuth requests the refs of the git repository of the verifier with the smart http protocol.
+func (v Verifier) verifyGitBasicAuth(s *corev1.Secret) error {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(v.GitRepo, "/")+"/info/refs?service=git-upload-pack", nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(string(s.Data[corev1.BasicAuthUsernameKey]), string(s.Data[corev1.BasicAuthPasswordKey]))
+
+	resp, err := (&http.Client{Transport: v.Transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkStatus(resp)
+}
+
+// verifyGitSsh authenticates with the private key in an ssh handshake. The host key is checked when
+// the secret contains known hosts.
+func (v Verifier) verifyGitSsh(gitUrl string, s *corev1.Secret) error {
+	signer, err := ssh.ParsePrivateKey(s.Data[corev1.SSHAuthPrivateKey])
+	if err != nil {
+		return errors.Wrap(err, "invalid ssh private key")
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if knownHosts, ok := s.Data[KnownHostsKey]; ok {
+		hostKeyCallback, err = knownHostsCallback(knownHosts)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := ssh.Dial("tcp", sshAddress(gitUrl), &ssh.ClientConfig{
+		User:            sshUser(gitUrl),
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         v.SshTimeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = client.Close()
+	return nil
+}
+
+func sshUser(gitUrl string) string {
+	if u, ok := parseSshUrl(gitUrl); ok {
+		if u.User != nil && u.User.Username() != "" {
+			return u.User.Username()
+		}
+		return "git"
+	}
+
+	if i := strings.Index(gitUrl, "@"); i > 0 {
+		return gitUrl[:i]
+	}
+	return "git"
+}
+
+func knownHostsCallback(knownHosts []byte) (ssh.HostKeyCallback, error) {
+	file, err := ioutil.TempFile("", "known_hosts")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if _, err := file.Write(knownHosts); err != nil {
+		return nil, err
+	}
+
+	return knownhosts.New(file.Name())
+}
+
+func checkStatus(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errors.Errorf("invalid credentials (%s)", resp.Status)
+	default:
+		return errors.Errorf("unexpected status %s from %s", resp.Status, resp.Request.URL)
+	}
+}
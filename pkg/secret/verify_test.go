@@ -0,0 +1,293 @@
+// Copyright 2020-Present VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package secret_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/build-service-cli/pkg/secret"
+)
+
+func TestVerifier(t *testing.T) {
+	spec.Run(t, "TestVerifier", testVerifier)
+}
+
+func testVerifier(t *testing.T, when spec.G, it spec.S) {
+	verifier := secret.Verifier{Transport: http.DefaultTransport, SshTimeout: 5 * time.Second}
+
+	when("verifying registry credentials", func() {
+		var server *httptest.Server
+
+		it.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/token":
+					if user, password, ok := r.BasicAuth(); !ok || user != "some-user" || password != "some-password" {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					_, _ = fmt.Fprint(w, `{"token":"some-token"}`)
+				case "/v2/":
+					if r.Header.Get("Authorization") != "Bearer some-token" {
+						w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="some-registry"`, r.Host))
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		registrySecret := func(password string) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "some-secret"},
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(fmt.Sprintf(`{"auths":{"%s":{"username":"some-user","password":"%s"}}}`, server.Listener.Addr().String(), password)),
+				},
+				Type: corev1.SecretTypeDockerConfigJson,
+			}
+		}
+
+		it("exchanges the credentials for a token", func() {
+			results, err := verifier.Verify(registrySecret("some-password"))
+			require.NoError(t, err)
+			require.Equal(t, []secret.VerifyResult{{Target: server.Listener.Addr().String()}}, results)
+		})
+
+		it("reports credentials that are rejected", func() {
+			results, err := verifier.Verify(registrySecret("wrong-password"))
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.Error(t, results[0].Err)
+		})
+	})
+
+	when("verifying git basic auth credentials", func() {
+		var (
+			server *httptest.Server
+			path   string
+		)
+
+		it.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.String()
+				if user, password, ok := r.BasicAuth(); !ok || user != "some-user" || password != "some-password" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		gitSecret := func(password string) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "some-secret",
+					Annotations: map[string]string{secret.GitAnnotation: server.URL},
+				},
+				Data: map[string][]byte{
+					corev1.BasicAuthUsernameKey: []byte("some-user"),
+					corev1.BasicAuthPasswordKey: []byte(password),
+				},
+				Type: corev1.SecretTypeBasicAuth,
+			}
+		}
+
+		it("fetches the refs of the git repository", func() {
+			verifier.GitRepo = server.URL + "/some-org/some-repo"
+
+			results, err := verifier.Verify(gitSecret("some-password"))
+			require.NoError(t, err)
+			require.Equal(t, []secret.VerifyResult{{Target: server.URL}}, results)
+			require.Equal(t, "/some-org/some-repo/info/refs?service=git-upload-pack", path)
+		})
+
+		it("reports credentials that are rejected", func() {
+			verifier.GitRepo = server.URL + "/some-org/some-repo"
+
+			results, err := verifier.Verify(gitSecret("wrong-password"))
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.EqualError(t, results[0].Err, "invalid credentials (401 Unauthorized)")
+		})
+
+		it("errors without sending the credentials when the git repository is not on the git url of the secret", func() {
+			other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.String()
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer other.Close()
+
+			for _, repo := range []string{
+				other.URL + "/some-org/some-repo",
+				strings.Replace(server.URL, "http://", "https://", 1) + "/some-org/some-repo",
+			} {
+				verifier.GitRepo = repo
+
+				_, err := verifier.Verify(gitSecret("some-password"))
+				require.EqualError(t, err, fmt.Sprintf(`cannot verify the git basic auth credentials of secret "some-secret": git repository %q is not on %q`, repo, server.URL))
+				require.Empty(t, path)
+			}
+		})
+
+		it("errors without a git repository instead of probing the git url of the secret", func() {
+			_, err := verifier.Verify(gitSecret("some-password"))
+			require.EqualError(t, err, `a git repository is required to verify the git basic auth credentials of secret "some-secret"`)
+			require.Empty(t, path)
+		})
+	})
+
+	when("verifying git ssh credentials", func() {
+		var (
+			listener   net.Listener
+			hostSigner ssh.Signer
+			clientKey  []byte
+		)
+
+		it.Before(func() {
+			var err error
+			clientKey, err = ioutil.ReadFile("testdata/git-ssh.pem")
+			require.NoError(t, err)
+
+			clientSigner, err := ssh.ParsePrivateKey(clientKey)
+			require.NoError(t, err)
+
+			_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+			require.NoError(t, err)
+			hostSigner, err = ssh.NewSignerFromKey(hostKey)
+			require.NoError(t, err)
+
+			config := &ssh.ServerConfig{
+				PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+					if conn.User() == "git" && string(key.Marshal()) == string(clientSigner.PublicKey().Marshal()) {
+						return nil, nil
+					}
+					return nil, fmt.Errorf("unknown key")
+				},
+			}
+			config.AddHostKey(hostSigner)
+
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					go func() {
+						_, _, _, _ = ssh.NewServerConn(conn, config)
+						_ = conn.Close()
+					}()
+				}
+			}()
+		})
+
+		it.After(func() {
+			_ = listener.Close()
+		})
+
+		sshSecret := func(key []byte, knownHosts string) *corev1.Secret {
+			s := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "some-secret",
+					Annotations: map[string]string{secret.GitAnnotation: "git@" + strings.Replace(listener.Addr().String(), "127.0.0.1", "localhost", 1)},
+				},
+				Data: map[string][]byte{
+					corev1.SSHAuthPrivateKey: key,
+				},
+				Type: corev1.SecretTypeSSHAuth,
+			}
+			if knownHosts != "" {
+				s.Data[secret.KnownHostsKey] = []byte(knownHosts)
+			}
+			return s
+		}
+
+		it("authenticates with the private key", func() {
+			results, err := verifier.Verify(sshSecret(clientKey, ""))
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.NoError(t, results[0].Err)
+		})
+
+		it("connects to the host and port of ssh:// urls", func() {
+			s := sshSecret(clientKey, "")
+			s.Annotations[secret.GitAnnotation] = "ssh://git@" + strings.Replace(listener.Addr().String(), "127.0.0.1", "localhost", 1) + "/some-org/some-repo.git"
+
+			results, err := verifier.Verify(s)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.NoError(t, results[0].Err)
+		})
+
+		it("checks the host key with the known hosts of the secret", func() {
+			port := listener.Addr().(*net.TCPAddr).Port
+			knownHosts := fmt.Sprintf("[localhost]:%d %s", port, ssh.MarshalAuthorizedKey(hostSigner.PublicKey()))
+
+			results, err := verifier.Verify(sshSecret(clientKey, knownHosts))
+			require.NoError(t, err)
+			require.NoError(t, results[0].Err)
+
+			otherKnownHosts, err := ioutil.ReadFile("testdata/known_hosts")
+			require.NoError(t, err)
+			otherKnownHosts = []byte(strings.Replace(string(otherKnownHosts), "github.com", fmt.Sprintf("[localhost]:%d", port), 1))
+
+			results, err = verifier.Verify(sshSecret(clientKey, string(otherKnownHosts)))
+			require.NoError(t, err)
+			require.Error(t, results[0].Err)
+			require.Contains(t, results[0].Err.Error(), "key mismatch")
+		})
+
+		it("reports keys that are rejected", func() {
+			otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			require.NoError(t, err)
+			der, err := x509.MarshalECPrivateKey(otherKey)
+			require.NoError(t, err)
+
+			results, err := verifier.Verify(sshSecret(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), ""))
+			require.NoError(t, err)
+			require.Error(t, results[0].Err)
+			require.Contains(t, results[0].Err.Error(), "unable to authenticate")
+		})
+	})
+
+	it("errors on unsupported secret types", func() {
+		_, err := verifier.Verify(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-secret"},
+			Type:       corev1.SecretTypeOpaque,
+		})
+		require.EqualError(t, err, `cannot verify secret "some-secret" of type "Opaque"`)
+	})
+}
@@ -55,3 +55,13 @@ func GetFakeClusterProvider(k8sClient *k8sfakes.Clientset, kpackClient *kpackfak
 		},
 	}
 }
+
+func GetFakeK8sAndKpackProvider(k8sClient *k8sfakes.Clientset, kpackClient *kpackfakes.Clientset, namespace string) FakeClientSetProvider {
+	return FakeClientSetProvider{
+		clientSet: k8s.ClientSet{
+			K8sClient:   k8sClient,
+			KpackClient: kpackClient,
+			Namespace:   namespace,
+		},
+	}
+}